@@ -0,0 +1,654 @@
+// Package agent tails local log files and ships their lines to the gRPC ingestion API. Progress
+// is saved per file after every durable batch, and every line carries an idempotency key derived
+// from its file and offset, so a restart neither loses nor duplicates lines.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"tlng/config"
+	"tlng/internal/tlsconfig"
+	pb "tlng/proto/logingestion"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// Retry delays of lines that are not durable yet
+const (
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// goneGracePeriod is how long a file that was renamed away or deleted is still read, for lines
+// the writer appends before it reopens its log
+const goneGracePeriod = 5 * time.Second
+
+// statusDurable is the submission status once a log is in the state DB and Kafka
+const statusDurable = "DURABLE"
+
+// Line errors; the line is skipped and recorded in the manifest
+var (
+	errLineTooLong = errors.New("line exceeds max_line_size")
+	errInvalidUTF8 = errors.New("line is not valid UTF-8")
+	errInvalidJSON = errors.New("line is not a JSON document")
+)
+
+// Stats are the agent counters
+type Stats struct {
+	Files     int    // Files open
+	Lines     uint64 // Lines read
+	Submitted uint64 // Lines durable in the pipeline
+	Rejected  uint64 // Lines skipped locally or rejected by the server
+	Retried   uint64 // Line submissions repeated after a failed call or a non-durable result
+}
+
+// record is a line waiting in the batch, and its outcome
+type record struct {
+	fileID     string
+	generation string
+	path       string
+	manifest   bool
+	line       rawLine
+	request    *pb.SubmitLogRequest // nil for lines that are not submitted
+
+	response *pb.SubmitLogResponse
+	err      string
+}
+
+// Agent tails the configured files. A single goroutine discovers files, reads their new lines
+// and submits them in batches; reading waits while a batch is retried.
+type Agent struct {
+	cfg       *config.AgentConfig
+	logger    *log.Logger
+	conn      *grpc.ClientConn
+	client    pb.LogIngestionClient
+	statePath string
+
+	state        map[string]*fileState  // By file ID
+	files        map[string]*tailedFile // By file ID
+	manifests    map[string]*manifest   // By generation
+	batch        []*record
+	batchStarted time.Time
+	scanned      bool // The first scan is done; start_at: end only applies to it
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	lines     atomic.Uint64
+	submitted atomic.Uint64
+	rejected  atomic.Uint64
+	retried   atomic.Uint64
+	open      atomic.Int64
+}
+
+// New creates an agent from its configuration and saved state; call Start to begin tailing
+func New(cfg *config.AgentConfig, logger *log.Logger) (*Agent, error) {
+	// 1. State and manifest directories
+	if err := os.MkdirAll(cfg.StateDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state_dir: %w", err)
+	}
+	for _, files := range cfg.Files {
+		if files.Manifest {
+			if err := os.MkdirAll(cfg.ManifestDir, 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create manifest_dir: %w", err)
+			}
+			break
+		}
+	}
+
+	// 2. Saved progress
+	statePath := filepath.Join(cfg.StateDir, stateFileName)
+	state, err := loadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Ingestion API client; the connection is established on first use
+	creds := insecure.NewCredentials()
+	if cfg.Server.TLS.Enabled {
+		tlsConfig, err := tlsconfig.NewClient(cfg.Server.TLS.CAFile, cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile, cfg.Server.TLS.ServerName)
+		if err != nil {
+			return nil, fmt.Errorf("server.tls: %w", err)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(cfg.Server.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client for '%s': %w", cfg.Server.Address, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Agent{
+		cfg:       cfg,
+		logger:    logger,
+		conn:      conn,
+		client:    pb.NewLogIngestionClient(conn),
+		statePath: statePath,
+		state:     state,
+		files:     make(map[string]*tailedFile),
+		manifests: make(map[string]*manifest),
+		ctx:       ctx,
+		cancel:    cancel,
+	}, nil
+}
+
+// Start tails the files in the background
+func (a *Agent) Start() {
+	a.wg.Add(1)
+	go a.run()
+	a.logger.Printf("Agent '%s': Tailing %d file groups into %s (state in %s)", a.cfg.Name, len(a.cfg.Files), a.cfg.Server.Address, a.cfg.StateDir)
+}
+
+// Close stops tailing and closes the files. Lines of a batch that was not durable yet are read
+// and submitted again after a restart; their idempotency keys return the original submissions.
+func (a *Agent) Close() {
+	a.cancel()
+	a.wg.Wait()
+	if err := saveState(a.statePath, a.cfg.Name, a.state); err != nil {
+		a.logger.Printf("Agent '%s': Failed to save state: %v", a.cfg.Name, err)
+	}
+	for _, t := range a.files {
+		t.close()
+	}
+	for _, m := range a.manifests {
+		m.close()
+	}
+	if err := a.conn.Close(); err != nil {
+		a.logger.Printf("Agent '%s': Failed to close gRPC connection: %v", a.cfg.Name, err)
+	}
+
+	stats := a.Stats()
+	a.logger.Printf("Agent '%s': Stopped (lines %d, submitted %d, rejected %d, retried %d)",
+		a.cfg.Name, stats.Lines, stats.Submitted, stats.Rejected, stats.Retried)
+}
+
+// Stats returns the agent counters
+func (a *Agent) Stats() Stats {
+	return Stats{
+		Files:     int(a.open.Load()),
+		Lines:     a.lines.Load(),
+		Submitted: a.submitted.Load(),
+		Rejected:  a.rejected.Load(),
+		Retried:   a.retried.Load(),
+	}
+}
+
+func (a *Agent) run() {
+	defer a.wg.Done()
+	ticker := time.NewTicker(a.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if !a.poll() {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+// poll runs one round: discover files, read their new lines and submit the batch once it is due.
+// It returns false if the agent was closed.
+func (a *Agent) poll() bool {
+	// 1. Discover files; detect rotation and truncation
+	a.scan()
+
+	// 2. Read new lines, submitting every full batch
+	for _, id := range slices.Sorted(maps.Keys(a.files)) {
+		if !a.read(a.files[id]) {
+			return false
+		}
+	}
+
+	// 3. Submit a partial batch once its first line waited batch_timeout
+	if len(a.batch) > 0 && time.Since(a.batchStarted) >= a.cfg.BatchTimeout {
+		if !a.flush() {
+			return false
+		}
+	}
+
+	// 4. Finish files that are gone: read their last line, submit it, and forget them
+	var finished []string
+	for id, t := range a.files {
+		if !t.goneSince.IsZero() && time.Since(t.goneSince) >= goneGracePeriod {
+			t.closing = true
+			if !a.read(t) {
+				return false
+			}
+			finished = append(finished, id)
+		}
+	}
+	if len(finished) > 0 {
+		if len(a.batch) > 0 && !a.flush() {
+			return false
+		}
+		for _, id := range finished {
+			t := a.files[id]
+			a.logger.Printf("Agent '%s': Finished %s after line %d", a.cfg.Name, t, t.line)
+			t.close()
+			delete(a.files, id)
+			delete(a.state, id)
+			a.open.Add(-1)
+		}
+		a.saveState()
+		a.closeManifests()
+	}
+	return true
+}
+
+// scan matches the configured patterns, opens new files and marks files whose path now leads
+// elsewhere, or nowhere, as gone. A file matched by several groups belongs to the first.
+func (a *Agent) scan() {
+	seen := make(map[string]bool)
+	for group, files := range a.cfg.Files {
+		for _, pattern := range files.Paths {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				a.logger.Printf("Agent '%s': Invalid pattern '%s': %v", a.cfg.Name, pattern, err)
+				continue
+			}
+			for _, path := range matches {
+				info, err := os.Stat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					continue
+				}
+				id := fileID(path, info)
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+
+				if t, ok := a.files[id]; ok {
+					if t.state.Path != path {
+						a.logger.Printf("Agent '%s': %s was renamed to '%s'", a.cfg.Name, t, path)
+						t.state.Path = path
+					}
+					t.goneSince = time.Time{}
+					a.checkTruncated(id, t)
+					continue
+				}
+				if err := a.openFile(id, path, info, group); err != nil {
+					a.logger.Printf("Agent '%s': Failed to open '%s': %v", a.cfg.Name, path, err)
+				}
+			}
+		}
+	}
+
+	for id, t := range a.files {
+		if !seen[id] && t.goneSince.IsZero() {
+			t.goneSince = time.Now()
+		}
+	}
+
+	// Progress of files that disappeared while the agent was stopped is dropped once
+	if !a.scanned {
+		a.scanned = true
+		for id := range a.state {
+			if !seen[id] {
+				delete(a.state, id)
+			}
+		}
+		a.saveState()
+	}
+}
+
+// openFile starts tailing a file, resuming from its saved progress unless the file was
+// truncated or replaced meanwhile. A new generation is saved before any of its lines is sent,
+// so its idempotency keys survive a crash.
+func (a *Agent) openFile(id, path string, info os.FileInfo, group int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	state := a.state[id]
+	if state != nil && !resumable(file, info, state) {
+		a.logger.Printf("Agent '%s': '%s' was truncated or replaced since line %d, reading it from the start", a.cfg.Name, path, state.Line)
+		state = nil
+	}
+	if state == nil {
+		state = &fileState{Generation: uuid.New().String(), FileID: id, Path: path}
+		if !a.scanned && a.cfg.Files[group].StartAt == config.AgentStartEnd {
+			if state.Offset, err = lastLineEnd(file, info.Size()); err != nil {
+				file.Close()
+				return err
+			}
+		}
+		a.updateFingerprint(file, info.Size(), state)
+		a.state[id] = state
+		if err := saveState(a.statePath, a.cfg.Name, a.state); err != nil {
+			delete(a.state, id)
+			file.Close()
+			return err
+		}
+	}
+	state.Path = path
+
+	t, err := newTailed(file, state, group)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	a.files[id] = t
+	a.open.Add(1)
+	a.logger.Printf("Agent '%s': Tailing %s from offset %d", a.cfg.Name, t, state.Offset)
+	return nil
+}
+
+// resumable reports whether saved progress still applies to a file: it is not shorter than
+// the saved offset, and starts with the same bytes
+func resumable(file *os.File, info os.FileInfo, state *fileState) bool {
+	if info.Size() < state.Offset {
+		return false
+	}
+	sum, ok, err := fingerprint(file, state.FingerprintSize)
+	return err == nil && ok && sum == state.Fingerprint
+}
+
+// checkTruncated starts a new generation of a file that became shorter than what was read,
+// or whose first bytes changed (copytruncate followed by new writes)
+func (a *Agent) checkTruncated(id string, t *tailedFile) {
+	info, err := t.file.Stat()
+	if err != nil {
+		return
+	}
+	if resumable(t.file, info, &fileState{Offset: t.position, Fingerprint: t.state.Fingerprint, FingerprintSize: t.state.FingerprintSize}) {
+		a.updateFingerprint(t.file, info.Size(), t.state)
+		return
+	}
+
+	a.logger.Printf("Agent '%s': %s was truncated after line %d, reading it from the start", a.cfg.Name, t, t.line)
+	state := &fileState{Generation: uuid.New().String(), FileID: id, Path: t.state.Path}
+	a.updateFingerprint(t.file, info.Size(), state)
+	a.state[id] = state
+	a.saveState()
+	if err := t.restart(state); err != nil {
+		a.logger.Printf("Agent '%s': Failed to rewind %s: %v", a.cfg.Name, t, err)
+	}
+}
+
+// updateFingerprint extends a fingerprint that is shorter than fingerprintSize as the file grows
+func (a *Agent) updateFingerprint(file *os.File, size int64, state *fileState) {
+	target := min(size, fingerprintSize)
+	if state.FingerprintSize >= target && state.Fingerprint != "" {
+		return
+	}
+	if sum, ok, err := fingerprint(file, target); err == nil && ok {
+		state.Fingerprint, state.FingerprintSize = sum, target
+	}
+}
+
+// lastLineEnd returns the offset after the last line terminator of a file, so tailing from
+// the end starts with the line that is being written
+func lastLineEnd(file *os.File, size int64) (int64, error) {
+	buf := make([]byte, 64*1024)
+	for end := size; end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] == '\n' {
+				return start + int64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// read adds the new complete lines of a file to the batch, submitting it whenever it is full.
+// It returns false if the agent was closed.
+func (a *Agent) read(t *tailedFile) bool {
+	for {
+		l, err := t.next(a.cfg.MaxLineSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				a.logger.Printf("Agent '%s': Failed to read %s: %v", a.cfg.Name, t, err)
+			}
+			return true
+		}
+		a.lines.Add(1)
+		a.add(t, l)
+		if len(a.batch) >= a.cfg.BatchSize && !a.flush() {
+			return false
+		}
+	}
+}
+
+// add maps a line to its submission and queues it. Blank lines only move the offset.
+func (a *Agent) add(t *tailedFile, l rawLine) {
+	files := a.cfg.Files[t.group]
+	rec := &record{
+		fileID:     t.state.FileID,
+		generation: t.state.Generation,
+		path:       t.state.Path,
+		manifest:   files.Manifest,
+		line:       l,
+	}
+	if len(a.batch) == 0 {
+		a.batchStarted = time.Now()
+	}
+	a.batch = append(a.batch, rec)
+
+	var err error
+	switch {
+	case l.tooLong:
+		err = errLineTooLong
+	case len(l.text) == 0:
+		return
+	case files.Format == config.AgentFormatJSON && !json.Valid(l.text):
+		err = errInvalidJSON
+	case !utf8.Valid(l.text): // Proto strings must be UTF-8
+		err = errInvalidUTF8
+	}
+	if err != nil {
+		a.reject(rec, err.Error())
+		return
+	}
+
+	rec.request = &pb.SubmitLogRequest{
+		ClientSourceOrgId: files.OrgID,
+		Category:          files.Category,
+		AckLevel:          "durable",
+		IdempotencyKey:    idempotencyKey(rec.generation, l.offset),
+	}
+	if files.Format == config.AgentFormatJSON {
+		rec.request.LogJson = string(l.text)
+	} else {
+		rec.request.LogContent = string(l.text)
+	}
+}
+
+// idempotencyKey names a line by the generation of its file and its offset, so a line that is
+// read again after a restart is deduplicated by the server
+func idempotencyKey(generation string, offset int64) string {
+	return fmt.Sprintf("agent:%s:%d", generation, offset)
+}
+
+// flush submits the batch until every line is durable or rejected, then records the outcome in
+// the manifests and the state. It returns false if the agent was closed first.
+func (a *Agent) flush() bool {
+	var pending []*record
+	for _, rec := range a.batch {
+		if rec.request != nil {
+			pending = append(pending, rec)
+		}
+	}
+
+	delay := minRetryDelay
+	for len(pending) > 0 {
+		retry, err := a.submit(pending)
+		if a.ctx.Err() != nil {
+			return false
+		}
+		if len(retry) == 0 {
+			break
+		}
+
+		a.retried.Add(uint64(len(retry)))
+		if err != nil {
+			a.logger.Printf("Agent '%s': Retrying %d lines in %v: %v", a.cfg.Name, len(retry), delay, err)
+		}
+		select {
+		case <-time.After(delay):
+		case <-a.ctx.Done():
+			return false
+		}
+		delay = min(delay*2, maxRetryDelay)
+		pending = retry
+	}
+
+	a.commit(a.batch)
+	a.batch = nil
+	return true
+}
+
+// submit sends one SubmitLogsBatch call and returns the records to send again, with the last
+// error seen
+func (a *Agent) submit(pending []*record) ([]*record, error) {
+	request := &pb.SubmitLogsBatchRequest{Entries: make([]*pb.SubmitLogRequest, len(pending))}
+	for i, rec := range pending {
+		request.Entries[i] = rec.request
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.cfg.Server.Timeout)
+	defer cancel()
+	if a.cfg.Server.APIKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", a.cfg.Server.APIKey)
+	}
+	response, err := a.client.SubmitLogsBatch(ctx, request)
+	if err != nil {
+		return pending, err
+	}
+	if len(response.GetResults()) != len(pending) {
+		return pending, fmt.Errorf("%d results for %d entries", len(response.GetResults()), len(pending))
+	}
+
+	var retry []*record
+	var lastErr error
+	for i, result := range response.GetResults() {
+		rec := pending[i]
+		switch code := codes.Code(result.GetCode()); {
+		case result.GetResponse().GetStatus() == statusDurable:
+			rec.response = result.GetResponse()
+			a.submitted.Add(1)
+		case result.GetError() != "" && (code == codes.InvalidArgument || code == codes.AlreadyExists):
+			a.reject(rec, result.GetError())
+		default:
+			// Not durable yet: a failed write, or a replay of a submission still in flight
+			if result.GetError() != "" {
+				lastErr = errors.New(result.GetError())
+			}
+			retry = append(retry, rec)
+		}
+	}
+	return retry, lastErr
+}
+
+func (a *Agent) reject(rec *record, reason string) {
+	rec.err = reason
+	a.rejected.Add(1)
+	a.logger.Printf("Agent '%s': Rejected line %d of '%s': %s", a.cfg.Name, rec.line.number, rec.path, reason)
+}
+
+// commit appends the outcome of a batch to the manifests, then saves the offsets. A crash in
+// between replays the batch; the manifest skips entries it already has.
+func (a *Agent) commit(batch []*record) {
+	// 1. Manifests, per file generation
+	entries := make(map[string][]*ManifestEntry)
+	paths := make(map[string]string)
+	now := time.Now().UTC()
+	for _, rec := range batch {
+		if !rec.manifest || (rec.response == nil && rec.err == "") {
+			continue
+		}
+		entry := &ManifestEntry{
+			Path:       rec.path,
+			Generation: rec.generation,
+			Line:       rec.line.number,
+			Offset:     rec.line.offset,
+			Error:      rec.err,
+			RecordedAt: now,
+		}
+		if rec.response != nil {
+			entry.RequestID = rec.response.GetRequestId()
+			entry.ServerLogHash = rec.response.GetServerLogHash()
+			entry.Status = rec.response.GetStatus()
+		}
+		entries[rec.generation] = append(entries[rec.generation], entry)
+		paths[rec.generation] = rec.path
+	}
+	for generation, list := range entries {
+		m, ok := a.manifests[generation]
+		if !ok {
+			var err error
+			if m, err = openManifest(a.cfg.ManifestDir, paths[generation], generation); err != nil {
+				a.logger.Printf("Agent '%s': %v", a.cfg.Name, err)
+				continue
+			}
+			a.manifests[generation] = m
+		}
+		if err := m.write(list); err != nil {
+			a.logger.Printf("Agent '%s': Failed to write manifest of '%s': %v", a.cfg.Name, paths[generation], err)
+		}
+	}
+
+	// 2. Offsets of the files, unless they were truncated meanwhile
+	for _, rec := range batch {
+		state := a.state[rec.fileID]
+		if state == nil || state.Generation != rec.generation {
+			continue
+		}
+		state.Offset, state.Line = rec.line.end, rec.line.number
+		if rec.response != nil {
+			state.LastRequestID = rec.response.GetRequestId()
+		}
+		state.UpdatedAt = now
+	}
+	a.saveState()
+	a.closeManifests()
+}
+
+// closeManifests closes the manifests of generations that are no longer tailed
+func (a *Agent) closeManifests() {
+	current := make(map[string]bool, len(a.state))
+	for _, state := range a.state {
+		current[state.Generation] = true
+	}
+	for generation, m := range a.manifests {
+		if !current[generation] {
+			m.close()
+			delete(a.manifests, generation)
+		}
+	}
+}
+
+// saveState saves the progress of all files. A failure is logged: the lines since the last save
+// are submitted again after a restart, and deduplicated by their idempotency keys.
+func (a *Agent) saveState() {
+	if err := saveState(a.statePath, a.cfg.Name, a.state); err != nil {
+		a.logger.Printf("Agent '%s': Failed to save state: %v", a.cfg.Name, err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package agent
+
+import "os"
+
+// fileID identifies a file by its path. Without inodes, a renamed file is a new file and
+// rotation is only detected through truncation and the fingerprint.
+func fileID(path string, _ os.FileInfo) string {
+	return "path:" + path
+}
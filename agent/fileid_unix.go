@@ -0,0 +1,17 @@
+//go:build unix
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileID identifies a file by device and inode, which stay the same when it is renamed on rotation
+func fileID(path string, info os.FileInfo) string {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fmt.Sprintf("%d:%d", uint64(st.Dev), uint64(st.Ino))
+	}
+	return "path:" + path
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestEntry is one line of a sidecar manifest. It ties a line of a tailed file to the
+// submission that attested it: the line's SHA-256 (or configured) hash equals ServerLogHash.
+type ManifestEntry struct {
+	Path          string    `json:"path"`
+	Generation    string    `json:"generation"`
+	Line          int64     `json:"line"`   // 1-based line number
+	Offset        int64     `json:"offset"` // Byte offset of the line in the file
+	RequestID     string    `json:"request_id,omitempty"`
+	ServerLogHash string    `json:"server_log_hash,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	Error         string    `json:"error,omitempty"` // Set for lines that were skipped or rejected
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+// manifest appends the entries of one file generation to
+// <manifest_dir>/<file name>.<generation>.manifest.ndjson
+type manifest struct {
+	file       *os.File
+	lastOffset int64 // Offset of the last entry written; replayed lines up to it are not written again
+}
+
+// openManifest opens or creates the manifest of a file generation. A last entry cut off by a
+// crash is removed, and the offset of the last complete entry is remembered.
+func openManifest(dir, path, generation string) (*manifest, error) {
+	name := filepath.Join(dir, fmt.Sprintf("%s.%s.manifest.ndjson", filepath.Base(path), generation))
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest '%s': %w", name, err)
+	}
+	m := &manifest{file: file, lastOffset: -1}
+	if err := m.recover(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to recover manifest '%s': %w", name, err)
+	}
+	return m, nil
+}
+
+// recover truncates the manifest after its last complete entry and reads that entry's offset
+func (m *manifest) recover() error {
+	info, err := m.file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil
+	}
+
+	// Read the tail; entries are far shorter than 64 KiB
+	tailSize := min(size, 64*1024)
+	tail := make([]byte, tailSize)
+	if _, err := m.file.ReadAt(tail, size-tailSize); err != nil && err != io.EOF {
+		return err
+	}
+	end := bytes.LastIndexByte(tail, '\n') + 1
+	if end == 0 && tailSize < size {
+		return fmt.Errorf("last entry is longer than %d bytes", tailSize)
+	}
+	if complete := size - tailSize + int64(end); complete < size {
+		if err := m.file.Truncate(complete); err != nil {
+			return err
+		}
+	}
+	if _, err := m.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(tail[:end], []byte("\n")), []byte("\n"))
+	if last := lines[len(lines)-1]; len(last) > 0 {
+		var entry ManifestEntry
+		if err := json.Unmarshal(last, &entry); err != nil {
+			return err
+		}
+		m.lastOffset = entry.Offset
+	}
+	return nil
+}
+
+// write appends entries and syncs them, skipping those already in the manifest
+func (m *manifest) write(entries []*ManifestEntry) error {
+	w := bufio.NewWriter(m.file)
+	encoder := json.NewEncoder(w)
+	written := false
+	for _, entry := range entries {
+		if entry.Offset <= m.lastOffset {
+			continue
+		}
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+		m.lastOffset = entry.Offset
+		written = true
+	}
+	if !written {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return m.file.Sync()
+}
+
+func (m *manifest) close() error {
+	return m.file.Close()
+}
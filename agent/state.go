@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateFileName is the file in state_dir holding the progress of every tailed file
+const stateFileName = "state.json"
+
+// fingerprintSize is the number of leading bytes that tell a file apart from another one that
+// got the same inode, or from its own truncated and rewritten content
+const fingerprintSize = 1024
+
+// fileState is the progress of one tailed file, saved after every durable batch
+type fileState struct {
+	Generation      string    `json:"generation"`       // Random ID, part of the idempotency key of every line; renewed on truncation
+	FileID          string    `json:"file_id"`          // device:inode
+	Path            string    `json:"path"`             // Last path the file was seen at
+	Fingerprint     string    `json:"fingerprint"`      // SHA-256 of the first fingerprint_size bytes
+	FingerprintSize int64     `json:"fingerprint_size"` // Grows with the file up to 1 KiB
+	Offset          int64     `json:"offset"`           // Byte offset after the last line that is durable or rejected
+	Line            int64     `json:"line"`             // Number of that line, 1-based
+	LastRequestID   string    `json:"last_request_id,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// stateFile is the content of state.json
+type stateFile struct {
+	Agent string                `json:"agent"`
+	Files map[string]*fileState `json:"files"` // By file ID
+}
+
+// loadState reads the saved progress; a missing file is an empty state
+func loadState(path string) (map[string]*fileState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]*fileState), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file '%s': %w", path, err)
+	}
+	var saved stateFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse state file '%s': %w", path, err)
+	}
+	if saved.Files == nil {
+		saved.Files = make(map[string]*fileState)
+	}
+	return saved.Files, nil
+}
+
+// saveState replaces the state file atomically: the new content is synced to a temporary file,
+// which is then renamed over the old one
+func saveState(path, agent string, files map[string]*fileState) error {
+	data, err := json.MarshalIndent(stateFile{Agent: agent, Files: files}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), stateFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create state file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir makes a rename in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !errors.Is(err, os.ErrInvalid) { // Not supported on every platform
+		return fmt.Errorf("failed to sync directory '%s': %w", dir, err)
+	}
+	return nil
+}
+
+// fingerprint hashes the first size bytes of a file. ok is false if the file is shorter.
+func fingerprint(file io.ReaderAt, size int64) (sum string, ok bool, err error) {
+	buf := make([]byte, size)
+	n, err := file.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", false, err
+	}
+	if int64(n) < size {
+		return "", false, nil
+	}
+	digest := sha256.Sum256(buf)
+	return hex.EncodeToString(digest[:]), true, nil
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// tailedFile is an open file being read. Reading runs ahead of the saved state by the lines
+// waiting in the current batch.
+type tailedFile struct {
+	state  *fileState
+	group  int // Index of the files entry that matched it
+	file   *os.File
+	reader *bufio.Reader
+
+	position  int64     // Bytes read from the file
+	lineStart int64     // Byte offset after the last complete line read
+	line      int64     // Number of the last complete line read
+	partial   []byte    // Start of a line whose end has not been written yet
+	tooLong   bool      // The partial line exceeds max_line_size; its bytes are dropped
+	goneSince time.Time // When the path stopped leading to this file; zero while it does
+	closing   bool      // Last read before closing; an unterminated last line is complete
+}
+
+// rawLine is a complete line read from a file
+type rawLine struct {
+	number  int64 // 1-based
+	offset  int64 // Byte offset of the line
+	end     int64 // Byte offset after the line terminator
+	text    []byte
+	tooLong bool // text was dropped because the line exceeds max_line_size
+}
+
+// newTailed positions an open file after the last line of its state
+func newTailed(file *os.File, state *fileState, group int) (*tailedFile, error) {
+	if _, err := file.Seek(state.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &tailedFile{
+		state:     state,
+		group:     group,
+		file:      file,
+		reader:    bufio.NewReaderSize(file, 64*1024),
+		position:  state.Offset,
+		lineStart: state.Offset,
+		line:      state.Line,
+	}, nil
+}
+
+// restart reads the file again from its start, after it was truncated
+func (t *tailedFile) restart(state *fileState) error {
+	if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	t.state = state
+	t.reader.Reset(t.file)
+	t.position, t.lineStart, t.line = 0, 0, 0
+	t.partial, t.tooLong = nil, false
+	return nil
+}
+
+// next returns the next complete line, or io.EOF if the rest of the file is not terminated yet.
+// While closing, the last line needs no terminator, since nothing will be appended to it.
+func (t *tailedFile) next(maxSize int) (rawLine, error) {
+	for {
+		chunk, err := t.reader.ReadSlice('\n')
+		t.position += int64(len(chunk))
+		if !t.tooLong {
+			t.partial = append(t.partial, chunk...)
+			if len(t.partial) > maxSize+2 { // +2 for CRLF
+				t.partial, t.tooLong = nil, true
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && !(errors.Is(err, io.EOF) && t.closing && t.position > t.lineStart) {
+			return rawLine{}, err
+		}
+
+		result := rawLine{
+			number:  t.line + 1,
+			offset:  t.lineStart,
+			end:     t.position,
+			text:    bytes.TrimSuffix(bytes.TrimSuffix(t.partial, []byte("\n")), []byte("\r")),
+			tooLong: t.tooLong,
+		}
+		if len(result.text) > maxSize {
+			result.text, result.tooLong = nil, true
+		}
+		t.lineStart = t.position
+		t.partial, t.tooLong = nil, false
+		t.line++
+		return result, nil
+	}
+}
+
+func (t *tailedFile) close() error {
+	return t.file.Close()
+}
+
+// String names the file in logs
+func (t *tailedFile) String() string {
+	return fmt.Sprintf("'%s'", t.state.Path)
+}
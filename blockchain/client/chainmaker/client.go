@@ -190,6 +190,13 @@ func (c *Client) SubmitLogsBatch(ctx context.Context, entries []types.LogEntry)
 		BlockHeight:   resp.TxBlockHeight,
 	}
 
+	// The block time is not part of the invoke response; a failed lookup only leaves it unset
+	if txInfo, err := c.sdkClient.GetTxByTxId(resp.TxId); err != nil {
+		c.logger.Printf("Failed to read block time for batch tx %s: %v", resp.TxId, err)
+	} else if txInfo != nil {
+		batchProof.BlockTimestamp = txInfo.BlockTimestamp
+	}
+
 	// c.logger.Printf("Successfully processed batch submission. TxID: %s, Block: %d, Results count: %d",
 	// 	batchProof.TransactionID, batchProof.BlockHeight, len(results))
 
@@ -237,6 +244,51 @@ func (c *Client) FindLogByHash(ctx context.Context, logHash string) (string, err
 	return string(resp.ContractResult.Result), nil
 }
 
+// SubmitTombstone records the erasure of a log's content on chain
+func (c *Client) SubmitTombstone(ctx context.Context, tombstone types.Tombstone) (*types.Proof, error) {
+	chainmakerCfg := c.cfg.ChainSpecific.(*ChainMakerConfig)
+	if chainmakerCfg.SubmitTombstoneMethodName == "" {
+		return nil, fmt.Errorf("tombstone configuration fields not set in config")
+	}
+
+	kvs := []*common.KeyValuePair{
+		{Key: chainmakerCfg.ParamKeyLogHash, Value: []byte(tombstone.LogHash)},
+		{Key: chainmakerCfg.ParamKeySenderOrgID, Value: []byte(tombstone.SenderOrgID)},
+		{Key: chainmakerCfg.ParamKeyErasedAt, Value: []byte(tombstone.ErasedAt)},
+		{Key: chainmakerCfg.ParamKeyReason, Value: []byte(tombstone.Reason)},
+	}
+	_, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+	resp, err := c.sdkClient.InvokeContract(chainmakerCfg.ContractName, chainmakerCfg.SubmitTombstoneMethodName, "", kvs, -1, true)
+	if err != nil {
+		return nil, fmt.Errorf("SDK invoke failed: %w", err)
+	}
+	if resp.Code != common.TxStatusCode_SUCCESS {
+		return nil, fmt.Errorf("contract execution failed: %s (code: %d)", resp.Message, resp.Code)
+	}
+	return &types.Proof{TransactionID: resp.TxId, BlockHeight: resp.TxBlockHeight, LogHash: tombstone.LogHash}, nil
+}
+
+// FindTombstoneByHash queries the contract for the erasure tombstone of a log
+func (c *Client) FindTombstoneByHash(ctx context.Context, logHash string) (string, error) {
+	chainmakerCfg := c.cfg.ChainSpecific.(*ChainMakerConfig)
+	if chainmakerCfg.FindTombstoneByHashMethodName == "" {
+		return "", fmt.Errorf("tombstone configuration fields not set in config")
+	}
+
+	_, cancel := context.WithTimeout(ctx, time.Duration(c.cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+	kvs := []*common.KeyValuePair{{Key: chainmakerCfg.ParamKeyLogHash, Value: []byte(logHash)}}
+	resp, err := c.sdkClient.QueryContract(chainmakerCfg.ContractName, chainmakerCfg.FindTombstoneByHashMethodName, kvs, -1)
+	if err != nil {
+		return "", fmt.Errorf("SDK query failed: %w", err)
+	}
+	if resp.Code != common.TxStatusCode_SUCCESS {
+		return "", fmt.Errorf("contract query failed: %s (code: %d)", resp.Message, resp.Code)
+	}
+	return string(resp.ContractResult.Result), nil
+}
+
 // GetLogByTxHash performs the "on-chain public audit" by querying transaction details
 func (c *Client) GetLogByTxHash(ctx context.Context, txHash string) (*types.AuditData, error) {
 	if txHash == "" {
@@ -259,7 +311,7 @@ func (c *Client) GetLogByTxHash(ctx context.Context, txHash string) (*types.Audi
 			if len(eventData) != 3 {
 				return nil, fmt.Errorf("malformed event data: expected 3 fields, got %d", len(eventData))
 			}
-			auditData := &types.AuditData{LogHash: eventData[0], SubmitterOrgID: eventData[1], Timestamp: eventData[2]}
+			auditData := &types.AuditData{LogHash: eventData[0], SubmitterOrgID: eventData[1], Timestamp: eventData[2], BlockTimestamp: txInfo.BlockTimestamp}
 			return auditData, nil
 		}
 	}
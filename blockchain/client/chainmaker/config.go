@@ -44,6 +44,12 @@ type ChainMakerConfig struct {
 	SubmitEventTopic          string `yaml:"submit_event_topic"`
 	SubmitLogsBatchMethodName string `yaml:"submit_logs_batch_method_name"`
 	ParamKeyLogsJson          string `yaml:"param_key_logs_json"`
+
+	// Erasure tombstones
+	SubmitTombstoneMethodName     string `yaml:"submit_tombstone_method_name"`
+	FindTombstoneByHashMethodName string `yaml:"find_tombstone_by_hash_method_name"`
+	ParamKeyErasedAt              string `yaml:"param_key_erased_at"`
+	ParamKeyReason                string `yaml:"param_key_reason"`
 }
 
 // LoadChainMakerConfig loads ChainMaker configuration from the specified YAML file path
@@ -17,6 +17,12 @@ type BlockchainClient interface {
 	// FindLogByHash queries the blockchain for a log record by its hash
 	FindLogByHash(ctx context.Context, logHash string) (string, error)
 
+	// SubmitTombstone records the erasure of a log's content on chain; resubmitting a tombstone succeeds
+	SubmitTombstone(ctx context.Context, tombstone types.Tombstone) (*types.Proof, error)
+
+	// FindTombstoneByHash queries the blockchain for the erasure tombstone of a log, empty if there is none
+	FindTombstoneByHash(ctx context.Context, logHash string) (string, error)
+
 	// GetLogByTxHash performs the "on-chain public audit" by querying transaction details
 	GetLogByTxHash(ctx context.Context, txHash string) (*types.AuditData, error)
 
@@ -6,7 +6,44 @@ type LogEntry struct {
 	LogHash     string `json:"log_hash"`
 	LogContent  string `json:"log_content"`
 	SenderOrgID string `json:"sender_org_id"`
-	Timestamp   string `json:"timestamp"`
+	Timestamp   string `json:"timestamp"` // Ingestion receive time
+
+	// Original event time reported by the client, omitted if none was sent
+	ClientTimestamp string `json:"client_timestamp,omitempty"`
+
+	// "hash_only" stores hash and metadata without LogContent; omitted means full
+	AttestationMode string `json:"attestation_mode,omitempty"`
+
+	// "json" marks LogContent as a JSON value in RFC 8785 canonical form; omitted means text
+	ContentFormat string `json:"content_format,omitempty"`
+
+	// Schema category of a structured log and the version it was validated against; omitted if none
+	Category      string `json:"category,omitempty"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+
+	// Blob store locator of content kept off chain because of its size; LogContent is empty when set
+	ContentLocator string `json:"content_locator,omitempty"`
+
+	// Client signature over LogHash (unpadded base64url), the signer's key ID, algorithm and key fingerprint; omitted if unsigned
+	Signature               string `json:"signature,omitempty"`
+	SignatureKeyID          string `json:"signature_key_id,omitempty"`
+	SignatureAlgorithm      string `json:"signature_algorithm,omitempty"`
+	SignatureKeyFingerprint string `json:"signature_key_fingerprint,omitempty"`
+}
+
+// AttestationModeHashOnly is the LogEntry.AttestationMode that keeps content off chain
+const AttestationModeHashOnly = "hash_only"
+
+// ContentFormatJSON is the LogEntry.ContentFormat of structured logs
+const ContentFormatJSON = "json"
+
+// Tombstone records on chain that a log's content was erased (crypto-shredded).
+// The log record itself stays on chain, so its hash, timestamps and transaction remain verifiable.
+type Tombstone struct {
+	LogHash     string `json:"log_hash"`
+	SenderOrgID string `json:"sender_org_id"`
+	ErasedAt    string `json:"erased_at"`
+	Reason      string `json:"reason"`
 }
 
 // LogProcessingStatus corresponds to the Rust enum for batch results
@@ -29,8 +66,9 @@ type LogStatusInfo struct {
 
 // BatchProof holds the results common to the entire batch transaction
 type BatchProof struct {
-	TransactionID string // The TxID for the single batch transaction
-	BlockHeight   uint64 // The block height where the batch was included
+	TransactionID  string // The TxID for the single batch transaction
+	BlockHeight    uint64 // The block height where the batch was included
+	BlockTimestamp int64  // Unix time of that block in seconds, 0 if it could not be read
 }
 
 // Proof is the on-chain credential returned after successful single SubmitLog
@@ -45,4 +83,5 @@ type AuditData struct {
 	LogHash        string
 	SubmitterOrgID string
 	Timestamp      string
-}
\ No newline at end of file
+	BlockTimestamp int64 // Unix time of the block in seconds, 0 if unknown
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tlng/agent"
+	"tlng/config"
+)
+
+const agentConfigPath = "./config/agent.defaults.yml"
+
+func main() {
+	configPath := flag.String("config", agentConfigPath, "path of the agent configuration")
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "[AGENT] ", log.LstdFlags|log.Lshortfile)
+	logger.Println("Starting logchain-agent...")
+
+	// 1. Load Agent Config
+	cfg, err := config.LoadAgentConfig(*configPath)
+	if err != nil {
+		logger.Fatalf("FATAL: Failed to load agent configuration: %v", err)
+	}
+
+	// 2. Restore saved progress and start tailing
+	tailer, err := agent.New(cfg, logger)
+	if err != nil {
+		logger.Fatalf("FATAL: Failed to initialize agent: %v", err)
+	}
+	tailer.Start()
+
+	// 3. Graceful shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	logger.Printf("Received shutdown signal: %s, stopping agent...", sig)
+	tailer.Close()
+	logger.Println("logchain-agent stopped.")
+}
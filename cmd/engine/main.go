@@ -10,6 +10,8 @@ import (
 
 	blockchain "tlng/blockchain/client"
 	"tlng/config"
+	"tlng/internal/blobstore"
+	"tlng/internal/encryption"
 	"tlng/internal/messaging/consumer"
 	worker "tlng/processing"
 	"tlng/storage/store"
@@ -46,6 +48,16 @@ func main() {
 	}
 	defer bcClientImpl.Close()
 
+	var encryptor *encryption.Encryptor
+	if engineCfg.Encryption.Enabled {
+		logger.Printf("Initializing %s key provider for on-chain content encryption...", engineCfg.Encryption.KeyProvider.Type)
+		keyProvider, err := encryption.NewKeyProvider(engineCfg.Encryption.KeyProvider, logger)
+		if err != nil {
+			logger.Fatalf("FATAL: Failed to initialize key provider: %v", err)
+		}
+		encryptor = encryption.NewEncryptor(keyProvider, engineCfg.Encryption.Orgs)
+	}
+
 	// 3. Initialize Multiple Consumers
 	var mqConsumers []consumer.Consumer
 	if len(engineCfg.KafkaConsumer.Brokers) > 0 && engineCfg.KafkaConsumer.Brokers[0] != "mock://local" {
@@ -74,7 +86,7 @@ func main() {
 	var wg sync.WaitGroup
 
 	for i, consumer := range mqConsumers {
-		workerInstance := worker.New(engineCfg.Worker, engineCfg.MaxTaskRetries, logger, dbStore, consumer, bcClientImpl)
+		workerInstance := worker.New(engineCfg.Worker, engineCfg.MaxTaskRetries, logger, dbStore, consumer, bcClientImpl, encryptor)
 		workers = append(workers, workerInstance)
 
 		wg.Add(1)
@@ -86,6 +98,24 @@ func main() {
 		}(i+1, workerInstance)
 	}
 
+	// 5. Start the Eraser (retention expiry and erasure tombstones)
+	if engineCfg.Erasure.Enabled {
+		var blobs blobstore.Store
+		if engineCfg.BlobStore.Enabled {
+			logger.Printf("Initializing %s blob store for erasing off-chain log content...", engineCfg.BlobStore.Type)
+			blobs, err = blobstore.New(engineCfg.BlobStore, logger)
+			if err != nil {
+				logger.Fatalf("FATAL: Failed to initialize blob store: %v", err)
+			}
+		}
+		eraser := worker.NewEraser(engineCfg.Erasure, logger, dbStore, bcClientImpl, blobs)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eraser.Run(ctx)
+		}()
+	}
+
 	logger.Printf("Attestation Engine started with %d workers. Press Ctrl+C to stop.", len(workers))
 
 	// 6. Graceful Shutdown
@@ -18,10 +18,17 @@ import (
 	apiconfig "tlng/config"                     // Unified configuration package
 	grpchandler "tlng/ingestion/service/grpc"          // gRPC Handler (only includes SubmitLog)
 	httphandler "tlng/ingestion/service/http"          // HTTP Handler (only includes SubmitLog)
+	"tlng/ingestion/service/kafkasource"            // Native Kafka source adapters
+	"tlng/ingestion/service/otlp"                   // OTLP logs receiver
+	"tlng/ingestion/service/s3source"               // S3 bucket poller
+	"tlng/ingestion/service/syslog"                 // Native syslog receiver
+	"tlng/internal/auth"                       // Auth context and gateway signature checks
 	"tlng/internal/messaging/producer"         // Kafka producer
 	core "tlng/ingestion/service/core"                   // Core Service (only includes SubmitLog logic)
 	"tlng/storage/store"                       // Database Store (only needs InsertLogStatus)
 	pb "tlng/proto/logingestion"               // Protobuf definitions
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1" // OTLP logs service
 )
 
 // API Gateway configuration file path
@@ -56,17 +63,52 @@ func main() {
 	defer kafkaProducer.Close()
 
 	// 3. Create core Service (using configuration parameters) and Handlers
-	coreService := core.NewService(
+	coreService, err := core.NewService(
 		dbStore,
 		kafkaProducer,
 		logger,
-		cfg.BatchProcessor.BatchSize,
-		cfg.BatchProcessor.BatchTimeout,
-		cfg.BatchProcessor.FlushChannelBuffer,
+		cfg,
 	)
+	if err != nil {
+		logger.Fatalf("Failed to initialize core service: %v", err)
+	}
 	defer coreService.Close() // Ensure service is closed on exit
+
+	// Outbox relay publishes tbl_log_outbox records with its own synchronous producer keyed by org
+	if cfg.Outbox.Enabled {
+		relayCfg := cfg.KafkaProducer
+		relayCfg.Async = false
+		relayCfg.PartitionKey = producer.PartitionKeySourceOrgID
+		if relayCfg.RequiredAcks == "" || relayCfg.RequiredAcks == "none" {
+			relayCfg.RequiredAcks = "one" // Records are marked sent only after a broker ack
+		}
+		relayProducer, err := producer.NewKafkaProducer(relayCfg, logger)
+		if err != nil {
+			logger.Fatalf("Failed to initialize outbox relay Kafka producer: %v", err)
+		}
+		defer relayProducer.Close()
+
+		outboxRelay := core.NewOutboxRelay(dbStore, relayProducer, cfg.Outbox, logger)
+		outboxRelay.Start()
+		defer outboxRelay.Close()
+	}
+
 	logHttpHandler := httphandler.NewLogHandler(coreService, logger)
-	logGrpcService := grpchandler.NewServer(coreService, logger) // gRPC service implementation
+	logGrpcService := grpchandler.NewServer(coreService, logger, cfg.StreamMaxInFlight) // gRPC service implementation
+
+	// OTLP receiver, served on both listeners behind the same authentication
+	var otlpReceiver *otlp.Receiver
+	if cfg.OTLP.Enabled {
+		otlpReceiver = otlp.NewReceiver(coreService, cfg.OTLP, logger)
+	}
+
+	// Identity headers are only trusted with the ingress signature
+	var gateway *auth.GatewayVerifier
+	if cfg.Gateway.Enabled {
+		gateway = auth.NewGatewayVerifier([]byte(cfg.Gateway.HMACSecret), cfg.Gateway.MaxSkew)
+	} else {
+		logger.Println("Warning: gateway_auth disabled, identity headers are trusted without a signature")
+	}
 
 	var wg sync.WaitGroup
 
@@ -74,7 +116,24 @@ func main() {
 	var httpServer *http.Server
 	if cfg.HttpListenAddr != "" {
 		mux := http.NewServeMux()
-		mux.HandleFunc("/v1/logs", logHttpHandler.SubmitLog) // Only register write Handler
+		// Only register write Handlers; submissions need the org the ingress authenticated
+		requireSubmitter := func(h http.HandlerFunc) http.Handler {
+			return auth.RequireGateway(gateway)(auth.RequireAPIKey(h))
+		}
+		mux.Handle("/v1/logs", requireSubmitter(logHttpHandler.SubmitLog))
+		mux.Handle("/v1/logs/batch", requireSubmitter(logHttpHandler.SubmitLogsBatch))
+		if otlpReceiver != nil {
+			mux.Handle(cfg.OTLP.HTTPPath, requireSubmitter(otlpReceiver.ServeHTTP))
+			logger.Printf("OTLP/HTTP logs receiver on %s", cfg.OTLP.HTTPPath)
+		}
+
+		// Internal health and metrics endpoints (not routed through Nginx)
+		if cfg.Monitoring.HealthCheckPath != "" {
+			mux.HandleFunc(cfg.Monitoring.HealthCheckPath, logHttpHandler.HealthCheck)
+		}
+		if cfg.Monitoring.EnableMetrics && cfg.Monitoring.MetricsPath != "" {
+			mux.HandleFunc(cfg.Monitoring.MetricsPath, logHttpHandler.Metrics)
+		}
 
 		// Use HTTP server configuration with defaults
 		readTimeout := cfg.HttpServer.ReadTimeout
@@ -127,8 +186,32 @@ func main() {
 		if err != nil {
 			logger.Fatalf("Unable to listen on gRPC port %s: %v", cfg.GrpcListenAddr, err)
 		}
-		grpcServer = grpc.NewServer()
+		// Native TLS/mTLS and caller authentication, independent of the nginx gRPC ingress
+		var grpcOpts []grpc.ServerOption
+		if cfg.GrpcTLS.Enabled {
+			creds, err := grpchandler.NewServerCredentials(cfg.GrpcTLS)
+			if err != nil {
+				logger.Fatalf("Failed to initialize gRPC TLS: %v", err)
+			}
+			grpcOpts = append(grpcOpts, grpc.Creds(creds))
+		}
+		if cfg.GrpcAuth.Enabled {
+			authenticator, err := grpchandler.NewAuthenticator(cfg.GrpcAuth, gateway, logger)
+			if err != nil {
+				logger.Fatalf("Failed to initialize gRPC authentication: %v", err)
+			}
+			grpcOpts = append(grpcOpts,
+				grpc.ChainUnaryInterceptor(authenticator.UnaryInterceptor),
+				grpc.ChainStreamInterceptor(authenticator.StreamInterceptor))
+		} else {
+			logger.Println("Warning: grpc_auth disabled, gRPC callers can submit logs for any org")
+		}
+		grpcServer = grpc.NewServer(grpcOpts...)
 		pb.RegisterLogIngestionServer(grpcServer, logGrpcService) // Only register LogIngestion service
+		if otlpReceiver != nil {
+			collogspb.RegisterLogsServiceServer(grpcServer, otlpReceiver)
+			logger.Println("OTLP/gRPC logs receiver registered")
+		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -142,7 +225,41 @@ func main() {
 		logger.Println("grpc_listen_addr not configured, skipping gRPC server startup.")
 	}
 
-	// 6. Graceful shutdown
+	// 6. [Conditional startup] Syslog receiver, submitting directly to the core service
+	var syslogServer *syslog.Server
+	if cfg.Syslog.Enabled {
+		syslogServer, err = syslog.NewServer(coreService, cfg.Syslog, logger)
+		if err != nil {
+			logger.Fatalf("Failed to initialize syslog receiver: %v", err)
+		}
+		if err := syslogServer.Start(); err != nil {
+			logger.Fatalf("Syslog receiver startup failed: %v", err)
+		}
+	}
+
+	// 7. [Conditional startup] Kafka source adapters, committing offsets once logs are durable
+	var kafkaSources []*kafkasource.Source
+	for _, sourceCfg := range cfg.KafkaSources {
+		source, err := kafkasource.NewSource(coreService, sourceCfg, logger)
+		if err != nil {
+			logger.Fatalf("Failed to initialize Kafka source: %v", err)
+		}
+		source.Start()
+		kafkaSources = append(kafkaSources, source)
+	}
+
+	// 8. [Conditional startup] S3 source adapters, checkpointing every object in the state DB
+	var s3Pollers []*s3source.Poller
+	for _, sourceCfg := range cfg.S3Sources {
+		poller, err := s3source.NewPoller(coreService, dbStore, sourceCfg, logger)
+		if err != nil {
+			logger.Fatalf("Failed to initialize S3 source: %v", err)
+		}
+		poller.Start()
+		s3Pollers = append(s3Pollers, poller)
+	}
+
+	// 9. Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-quit
@@ -165,6 +282,16 @@ func main() {
 		grpcServer.GracefulStop()
 		logger.Println("gRPC server shutdown.")
 	}
+	if syslogServer != nil {
+		logger.Println("Shutting down syslog receiver...")
+		syslogServer.Close()
+	}
+	for _, source := range kafkaSources {
+		source.Close()
+	}
+	for _, poller := range s3Pollers {
+		poller.Close()
+	}
 
 	// Wait for HTTP server and gRPC server to finish
 	wg.Wait()
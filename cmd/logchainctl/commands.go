@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tlng/pkg/client"
+)
+
+// maxLineSize bounds a log read with -lines
+const maxLineSize = 4 << 20
+
+// input is a log read from a file or stdin; line is its line number with -lines, 0 otherwise
+type input struct {
+	line    int
+	content client.Content
+}
+
+// readInputs reads the logs of a file, or of stdin if path is empty or "-". The whole input is
+// one log without its final newline, or with lines each non-empty line is a log.
+func readInputs(path string, lines, isJSON bool) ([]input, error) {
+	// 1. Source
+	var r io.Reader = os.Stdin
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open input: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	content := func(text string) client.Content {
+		if isJSON {
+			return client.JSON([]byte(text))
+		}
+		return client.Text(text)
+	}
+
+	// 2. Whole input
+	if !lines {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		text := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		if text == "" {
+			return nil, errors.New("input is empty")
+		}
+		return []input{{content: content(text)}}, nil
+	}
+
+	// 3. One log per line
+	var inputs []input
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		inputs = append(inputs, input{line: n, content: content(text)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if len(inputs) == 0 {
+		return nil, errors.New("input has no logs")
+	}
+	return inputs, nil
+}
+
+// inputPath is the optional file argument of a command
+func inputPath(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// lineCell is the LINE column of a log; empty for a whole input
+func lineCell(line int) string {
+	if line == 0 {
+		return ""
+	}
+	return strconv.Itoa(line)
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func timeCell(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// submitRecord is the outcome of a submitted log
+type submitRecord struct {
+	Line int `json:"line,omitempty"`
+	*client.Receipt
+	Error string `json:"error,omitempty"`
+}
+
+func runSubmit(ctx context.Context, fs *flag.FlagSet, o *options, args []string) (bool, error) {
+	lines := fs.Bool("lines", false, "submit each non-empty line as a log")
+	isJSON := fs.Bool("json", false, "the logs are JSON documents")
+	ack := fs.String("ack", client.AckDurable, "ack level: accepted, durable or attested")
+	category := fs.String("category", "", "schema category of JSON logs")
+	mode := fs.String("attestation-mode", "", "full or hash_only (default the org's mode)")
+	key := fs.String("idempotency-key", "", "idempotency key; with -lines, each log's key is <key>:<line>")
+	batchSize := fs.Int("batch", 100, "logs per call with -lines")
+	args, err := parseArgs(fs, args, 0, 1)
+	if err != nil {
+		return false, err
+	}
+	if *batchSize < 1 {
+		return false, usageError(fs, "-batch must be positive")
+	}
+
+	// 1. Entries
+	inputs, err := readInputs(inputPath(args), *lines, *isJSON)
+	if err != nil {
+		return false, err
+	}
+	entries := make([]client.Entry, len(inputs))
+	for i, in := range inputs {
+		entries[i] = client.Entry{Content: in.content, AckLevel: *ack, AttestationMode: *mode, Category: *category}
+		if *key != "" && in.line > 0 {
+			entries[i].IdempotencyKey = fmt.Sprintf("%s:%d", *key, in.line)
+		} else {
+			entries[i].IdempotencyKey = *key
+		}
+	}
+
+	c, err := newClient(o)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	// 2. Submission, in batches with -lines. Once a whole call fails, the rest is not sent.
+	results := make([]client.BatchResult, 0, len(entries))
+	if !*lines {
+		receipt, err := c.Submit(ctx, entries[0])
+		results = append(results, client.BatchResult{Receipt: receipt, Err: err})
+	}
+	for start := 0; *lines && start < len(entries); start += *batchSize {
+		batch, err := c.SubmitBatch(ctx, entries[start:min(start+*batchSize, len(entries))])
+		if err != nil {
+			for range entries[start:] {
+				results = append(results, client.BatchResult{Err: err})
+			}
+			break
+		}
+		results = append(results, batch...)
+	}
+
+	// 3. Output
+	ok := true
+	r := &result{columns: []string{"LINE", "REQUEST_ID", "STATUS", "SERVER_LOG_HASH", "ERROR"}}
+	for i, res := range results {
+		record := &submitRecord{Line: inputs[i].line, Receipt: res.Receipt, Error: errorText(res.Err)}
+		row := []string{lineCell(record.Line), "", "", "", record.Error}
+		if res.Receipt != nil {
+			row[1], row[2], row[3] = res.Receipt.RequestID, res.Receipt.Status, res.Receipt.LogHash
+		}
+		r.rows = append(r.rows, row)
+		r.records = append(r.records, record)
+		ok = ok && res.Err == nil
+	}
+	if !*lines {
+		r.document = r.records[0]
+	}
+	return ok, r.print(os.Stdout, o.output)
+}
+
+func runStatus(ctx context.Context, fs *flag.FlagSet, o *options, args []string) (bool, error) {
+	args, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return false, err
+	}
+	c, err := newClient(o)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	status, err := c.Status(ctx, args[0])
+	if err != nil {
+		return false, err
+	}
+	r := &result{
+		columns:  []string{"REQUEST_ID", "STATUS", "LOG_HASH", "TX_HASH", "BLOCK", "RECEIVED", "ERROR"},
+		rows:     [][]string{statusRow(status)},
+		records:  []any{status},
+		document: status,
+	}
+	return true, r.print(os.Stdout, o.output)
+}
+
+func statusRow(status *client.LogStatus) []string {
+	block := ""
+	if status.BlockHeight > 0 {
+		block = strconv.FormatInt(status.BlockHeight, 10)
+	}
+	return []string{status.RequestID, status.Status, status.LogHash, status.TxHash, block,
+		timeCell(&status.ReceivedTimestamp), status.ErrorMessage}
+}
+
+// queryRecord is the state DB record of a queried log
+type queryRecord struct {
+	Line int `json:"line,omitempty"`
+	*client.LogStatus
+	Error string `json:"error,omitempty"`
+}
+
+func runQuery(ctx context.Context, fs *flag.FlagSet, o *options, args []string) (bool, error) {
+	contentFile := fs.String("content-file", "", "file with the content to look up, or - for stdin (required)")
+	lines := fs.Bool("lines", false, "look up each non-empty line as a log")
+	isJSON := fs.Bool("json", false, "the logs are JSON documents")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return false, err
+	}
+	if *contentFile == "" {
+		return false, usageError(fs, "-content-file is required")
+	}
+
+	inputs, err := readInputs(*contentFile, *lines, *isJSON)
+	if err != nil {
+		return false, err
+	}
+	c, err := newClient(o)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	ok := true
+	r := &result{columns: []string{"LINE", "REQUEST_ID", "STATUS", "LOG_HASH", "TX_HASH", "ERROR"}}
+	for _, in := range inputs {
+		status, err := c.QueryByContent(ctx, in.content)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, ctxErr
+		}
+		record := &queryRecord{Line: in.line, LogStatus: status, Error: errorText(err)}
+		row := []string{lineCell(in.line), "", "", "", "", record.Error}
+		if status != nil {
+			row[1], row[2], row[3], row[4] = status.RequestID, status.Status, status.LogHash, status.TxHash
+		}
+		r.rows = append(r.rows, row)
+		r.records = append(r.records, record)
+		ok = ok && err == nil
+	}
+	if !*lines {
+		r.document = r.records[0]
+	}
+	return ok, r.print(os.Stdout, o.output)
+}
+
+func runAudit(ctx context.Context, fs *flag.FlagSet, o *options, args []string) (bool, error) {
+	args, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return false, err
+	}
+	kind, value := args[0], args[1]
+	if kind != "hash" && kind != "tx" {
+		return false, usageError(fs, "unknown audit '%s' (hash or tx)", kind)
+	}
+	c, err := newClient(o)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	r := &result{columns: []string{"LOG_HASH", "SENDER_ORG", "TIMESTAMP", "MODE", "CATEGORY", "SIGNED", "CONTENT"}}
+	if kind == "hash" {
+		record, err := c.AuditLog(ctx, value)
+		if err != nil {
+			return false, err
+		}
+		r.rows = [][]string{auditRow(record)}
+		r.records = []any{record}
+		r.document = record
+		return true, r.print(os.Stdout, o.output)
+	}
+
+	audit, err := c.AuditTx(ctx, value)
+	if err != nil {
+		return false, err
+	}
+	for _, record := range audit.Logs {
+		r.rows = append(r.rows, auditRow(record))
+		r.records = append(r.records, record)
+	}
+	r.document = audit
+	return true, r.print(os.Stdout, o.output)
+}
+
+func auditRow(record *client.OnChainLog) []string {
+	content := record.LogContent
+	switch {
+	case record.Erased:
+		content = "(erased)"
+	case content == "" && record.ContentEncrypted:
+		content = "(encrypted)"
+	case content == "":
+		content = "(hash only)"
+	}
+	signed := "no"
+	if record.Signature != nil {
+		signed = record.Signature.KeyID
+	}
+	return []string{record.LogHash, record.SenderOrgID, record.Timestamp, record.AttestationMode,
+		record.Category, signed, content}
+}
+
+// verifyRecord is the verification of a log
+type verifyRecord struct {
+	Line int `json:"line,omitempty"`
+	*client.Verification
+	Error string `json:"error,omitempty"`
+}
+
+func runVerify(ctx context.Context, fs *flag.FlagSet, o *options, args []string) (bool, error) {
+	lines := fs.Bool("lines", false, "verify each non-empty line as a log")
+	isJSON := fs.Bool("json", false, "the logs are JSON documents")
+	args, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return false, err
+	}
+
+	inputs, err := readInputs(args[0], *lines, *isJSON)
+	if err != nil {
+		return false, err
+	}
+	c, err := newClient(o)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	ok := true
+	r := &result{columns: []string{"LINE", "VERIFIED", "LOG_HASH", "STATUS", "TX_HASH", "ERROR"}}
+	for _, in := range inputs {
+		verification, err := c.Verify(ctx, in.content)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, ctxErr
+		}
+		record := &verifyRecord{Line: in.line, Verification: verification, Error: errorText(err)}
+		row := []string{lineCell(in.line), "false", "", "", "", record.Error}
+		if verification != nil {
+			row[1], row[2] = strconv.FormatBool(verification.Verified), verification.LogHash
+			if verification.Status != nil {
+				row[3], row[4] = verification.Status.Status, verification.Status.TxHash
+			}
+		}
+		r.rows = append(r.rows, row)
+		r.records = append(r.records, record)
+		ok = ok && verification != nil && verification.Verified
+	}
+	if !*lines {
+		r.document = r.records[0]
+	}
+	return ok, r.print(os.Stdout, o.output)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitFailed  = 1 // A call failed, an entry was rejected or a log did not verify
+	exitUsage   = 2
+	programName = "logchainctl"
+)
+
+// errUsage is returned for errors in the command line, once they are printed
+var errUsage = errors.New("usage")
+
+// options are the flags every command accepts
+type options struct {
+	configPath  string
+	profile     string
+	output      string
+	baseURL     string
+	grpcAddress string
+	apiKey      string
+	caFile      string
+	certFile    string
+	keyFile     string
+	timeout     time.Duration
+}
+
+// command is a subcommand. run adds its flags to fs, which has the common flags already, and
+// returns whether everything succeeded.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, fs *flag.FlagSet, o *options, args []string) (bool, error)
+}
+
+var commands = []command{
+	{"submit", "[file|-]", "Submit the input as one log, or each line with -lines", runSubmit},
+	{"status", "<request_id>", "Show the status of a submission", runStatus},
+	{"query", "-content-file <file|->", "Find the status of logs by their content", runQuery},
+	{"audit", "hash <log_hash> | tx <tx_hash>", "Show on-chain records by log hash or transaction (mTLS)", runAudit},
+	{"verify", "<file|->", "Check logs against the state DB and the chain (API key and mTLS)", runVerify},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	o := &options{}
+	fs := flag.NewFlagSet(programName, flag.ContinueOnError)
+	registerOptions(fs, o)
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	} else if err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		usage(fs)
+		return exitUsage
+	}
+
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		ok, err := cmd.run(ctx, newFlagSet(cmd, o), o, fs.Args()[1:])
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return exitOK
+		case errors.Is(err, errUsage):
+			return exitUsage
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", programName, err)
+			return exitFailed
+		case !ok:
+			return exitFailed
+		}
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: unknown command '%s'\n", programName, name)
+	usage(fs)
+	return exitUsage
+}
+
+// registerOptions adds the common flags to the flag set of the program or of a command. They
+// may be given before or after the command name.
+func registerOptions(fs *flag.FlagSet, o *options) {
+	fs.StringVar(&o.configPath, "config", o.configPath, "profiles file (default $LOGCHAINCTL_CONFIG or "+defaultConfigPath()+")")
+	fs.StringVar(&o.profile, "profile", o.profile, "profile to use (default $LOGCHAINCTL_PROFILE or the file's default_profile)")
+	fs.StringVar(&o.output, "o", o.output, "output format: table, json or ndjson (default table)")
+	fs.StringVar(&o.baseURL, "url", o.baseURL, "HTTP API URL, overriding the profile's base_url")
+	fs.StringVar(&o.grpcAddress, "grpc", o.grpcAddress, "gRPC ingestion address for submit, overriding the profile's grpc_address")
+	fs.StringVar(&o.apiKey, "api-key", o.apiKey, "API key, overriding $LOGCHAIN_API_KEY and the profile's api_key")
+	fs.StringVar(&o.caFile, "ca", o.caFile, "CA bundle of the server certificate")
+	fs.StringVar(&o.certFile, "cert", o.certFile, "client certificate for mTLS")
+	fs.StringVar(&o.keyFile, "key", o.keyFile, "key of the client certificate")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "deadline of each call attempt (default 30s)")
+}
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <command> [command flags] [args]\n\nCommands:\n", programName)
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-7s %-32s %s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+// newFlagSet creates the flag set of a command, with the common flags
+func newFlagSet(cmd command, o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(programName+" "+cmd.name, flag.ContinueOnError)
+	registerOptions(fs, o)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags] %s\n\n%s.\n\nFlags:\n", programName, cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses the flags of a command, before or after its arguments, and checks the number
+// of arguments and the output format
+func parseArgs(fs *flag.FlagSet, args []string, minArgs, maxArgs int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage // Printed by the flag package
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional, args = append(positional, fs.Arg(0)), fs.Args()[1:]
+	}
+	if len(positional) < minArgs || len(positional) > maxArgs {
+		return nil, usageError(fs, "wrong number of arguments")
+	}
+	if err := checkFormat(fs.Lookup("o").Value.String()); err != nil {
+		return nil, usageError(fs, "%v", err)
+	}
+	return positional, nil
+}
+
+// usageError prints an error in the command line
+func usageError(fs *flag.FlagSet, format string, args ...any) error {
+	fmt.Fprintf(fs.Output(), "%s: %s\nRun '%s -h' for usage.\n", fs.Name(), fmt.Sprintf(format, args...), fs.Name())
+	return errUsage
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// result is the output of a command in every format: rows for the table, records for NDJSON,
+// and document, or records as an array if it is nil, for JSON
+type result struct {
+	columns  []string
+	rows     [][]string
+	records  []any
+	document any
+}
+
+// checkFormat validates the -o flag
+func checkFormat(format string) error {
+	switch format {
+	case "", formatTable, formatJSON, formatNDJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format '%s' (table, json or ndjson)", format)
+	}
+}
+
+// print writes the result in the format
+func (r *result) print(w io.Writer, format string) error {
+	switch format {
+	case formatJSON:
+		document := r.document
+		if document == nil {
+			document = r.records
+			if r.records == nil {
+				document = []any{}
+			}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+
+	case formatNDJSON:
+		encoder := json.NewEncoder(w)
+		for _, record := range r.records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(r.columns, "\t"))
+		for _, row := range r.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = cellText(cell)
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// cellText keeps a table cell on one line and within a readable width
+func cellText(cell string) string {
+	const maxWidth = 80
+
+	cell = strings.Join(strings.Fields(cell), " ")
+	switch {
+	case cell == "":
+		return "-"
+	case len([]rune(cell)) > maxWidth:
+		return string([]rune(cell)[:maxWidth-3]) + "..."
+	}
+	return cell
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"tlng/pkg/client"
+
+	"gopkg.in/yaml.v2"
+)
+
+// profilesFile is the YAML file of connection profiles. A profile is a client configuration:
+// operators typically have one with an API key, auditors one with a client certificate.
+type profilesFile struct {
+	DefaultProfile string                   `yaml:"default_profile"`
+	Profiles       map[string]client.Config `yaml:"profiles"`
+}
+
+// defaultConfigPath is the profiles file used without -config and $LOGCHAINCTL_CONFIG
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".", programName+".yml")
+	}
+	return filepath.Join(dir, programName, "config.yml")
+}
+
+// loadProfile returns the client configuration of the selected profile, with the flags and
+// $LOGCHAIN_API_KEY applied over it. Without a profiles file, the flags alone are used.
+func loadProfile(o *options) (client.Config, error) {
+	// 1. Profiles file
+	path, explicit := o.configPath, o.configPath != ""
+	if !explicit {
+		path, explicit = os.Getenv("LOGCHAINCTL_CONFIG"), os.Getenv("LOGCHAINCTL_CONFIG") != ""
+	}
+	if !explicit {
+		path = defaultConfigPath()
+	}
+
+	var file profilesFile
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return client.Config{}, fmt.Errorf("failed to parse profiles file '%s': %w", path, err)
+		}
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+	default:
+		return client.Config{}, fmt.Errorf("failed to read profiles file '%s': %w", path, err)
+	}
+
+	// 2. Selected profile
+	name := o.profile
+	if name == "" {
+		name = os.Getenv("LOGCHAINCTL_PROFILE")
+	}
+	if name == "" {
+		name = file.DefaultProfile
+	}
+	var cfg client.Config
+	if name != "" {
+		profile, ok := file.Profiles[name]
+		if !ok {
+			return client.Config{}, fmt.Errorf("profile '%s' not found in '%s'", name, path)
+		}
+		cfg = profile
+	} else if len(file.Profiles) == 1 {
+		for _, profile := range file.Profiles {
+			cfg = profile
+		}
+	}
+
+	// 3. Overrides
+	if key := os.Getenv("LOGCHAIN_API_KEY"); key != "" {
+		cfg.APIKey = key
+	}
+	if o.apiKey != "" {
+		cfg.APIKey = o.apiKey
+	}
+	if o.baseURL != "" {
+		cfg.BaseURL = o.baseURL
+	}
+	if o.grpcAddress != "" {
+		cfg.GRPCAddress = o.grpcAddress
+	}
+	if o.caFile != "" {
+		cfg.TLS.Enabled, cfg.TLS.CAFile = true, o.caFile
+	}
+	if o.certFile != "" || o.keyFile != "" {
+		cfg.TLS.Enabled, cfg.TLS.CertFile, cfg.TLS.KeyFile = true, o.certFile, o.keyFile
+	}
+	if o.timeout > 0 {
+		cfg.Timeout = o.timeout
+	}
+	if cfg.BaseURL == "" && cfg.GRPCAddress == "" {
+		return client.Config{}, fmt.Errorf("no server configured: create %s or pass -url", path)
+	}
+	return cfg, nil
+}
+
+// newClient creates the client of the selected profile
+func newClient(o *options) (*client.Client, error) {
+	cfg, err := loadProfile(o)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg)
+}
@@ -12,6 +12,9 @@ import (
 
 	blockchain "tlng/blockchain/client"
 	"tlng/config"
+	"tlng/internal/auth"
+	"tlng/internal/blobstore"
+	"tlng/internal/encryption"
 	"tlng/query/service/core"
 	queryhttp "tlng/query/service/http"
 	"tlng/storage/store"
@@ -62,16 +65,42 @@ func main() {
 		logger.Println("Blockchain client is disabled in configuration; skipping initialization.")
 	}
 
-	// 4. Create Query Service
+	// 4. Initialize Key Provider (conditionally)
+	var keyProvider encryption.KeyProvider
+	if queryCfg.Encryption.Enabled {
+		logger.Printf("Initializing %s key provider for encrypted on-chain content...", queryCfg.Encryption.KeyProvider.Type)
+		keyProvider, err = encryption.NewKeyProvider(queryCfg.Encryption.KeyProvider, logger)
+		if err != nil {
+			logger.Fatalf("FATAL: Failed to initialize key provider: %v", err)
+		}
+	}
+
+	// 5. Initialize Blob Store (conditionally)
+	var blobs blobstore.Store
+	if queryCfg.BlobStore.Enabled {
+		logger.Printf("Initializing %s blob store for off-chain log content...", queryCfg.BlobStore.Type)
+		blobs, err = blobstore.New(queryCfg.BlobStore, logger)
+		if err != nil {
+			logger.Fatalf("FATAL: Failed to initialize blob store: %v", err)
+		}
+	}
+
+	// 6. Create Query Service
 	logger.Println("Initializing query service...")
-	queryService := core.NewService(dbStore, bcClient, logger)
+	queryService := core.NewService(dbStore, bcClient, queryCfg.Hashing, queryCfg.Encryption, keyProvider, blobs, logger)
 
-	// 5. Setup HTTP Server
+	// 7. Setup HTTP Server
 	logger.Println("Setting up HTTP server...")
 	mux := http.NewServeMux()
 
 	// Register query API routes
-	handler := queryhttp.NewHandler(queryService, logger)
+	var gateway *auth.GatewayVerifier
+	if queryCfg.Gateway.Enabled {
+		gateway = auth.NewGatewayVerifier([]byte(queryCfg.Gateway.HMACSecret), queryCfg.Gateway.MaxSkew)
+	} else {
+		logger.Println("WARNING: gateway_auth disabled, identity headers are trusted without a signature")
+	}
+	handler := queryhttp.NewHandler(queryService, queryCfg.Admin.MemberIDs, gateway, logger)
 	handler.RegisterRoutes(mux)
 
 	// Add health check endpoint
@@ -102,7 +131,7 @@ func main() {
 		IdleTimeout:  idleTimeout,
 	}
 
-	// 6. Start HTTP Server in goroutine
+	// 8. Start HTTP Server in goroutine
 	go func() {
 		logger.Printf("Query Service listening on port %d", queryCfg.Server.HTTPPort)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -112,7 +141,7 @@ func main() {
 
 	logger.Println("Query Service started successfully. Press Ctrl+C to stop.")
 
-	// 7. Graceful Shutdown
+	// 9. Graceful Shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
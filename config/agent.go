@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Line formats of a tailed file
+const (
+	AgentFormatText = "text" // Each line is submitted as log_content
+	AgentFormatJSON = "json" // Each line is a JSON document, submitted as log_json
+)
+
+// Where tailing starts in a file the agent has no state for
+const (
+	AgentStartBeginning = "beginning"
+	AgentStartEnd       = "end" // Only for files that exist when the agent starts; files appearing later are read in full
+)
+
+// AgentConfig defines the configuration of logchain-agent, which tails local log files into the
+// gRPC ingestion API
+type AgentConfig struct {
+	Name         string            `yaml:"name"`          // Agent identity in logs; defaults to the hostname
+	Server       AgentServerConfig `yaml:"server"`        // Ingestion gRPC API
+	StateDir     string            `yaml:"state_dir"`     // Offsets and request_ids; must survive restarts
+	ManifestDir  string            `yaml:"manifest_dir"`  // Sidecar manifests of files with manifest enabled
+	Files        []AgentFileConfig `yaml:"files"`         // Files to tail
+	PollInterval time.Duration     `yaml:"poll_interval"` // How often files are checked for new lines, rotation and truncation
+	BatchSize    int               `yaml:"batch_size"`    // Lines per SubmitLogsBatch call, at most the server's max_batch_entries
+	BatchTimeout time.Duration     `yaml:"batch_timeout"` // Longest a line waits for a batch to fill
+	MaxLineSize  int               `yaml:"max_line_size"` // Longer lines are skipped
+}
+
+// AgentServerConfig defines how the agent reaches the ingestion service
+type AgentServerConfig struct {
+	Address string          `yaml:"address"` // host:port of the gRPC listener or the nginx gRPC ingress
+	APIKey  string          `yaml:"api_key"` // Sent as x-api-key; LOGCHAIN_API_KEY overrides it
+	TLS     ClientTLSConfig `yaml:"tls"`     // Client certificate for mTLS, CA of the server
+	Timeout time.Duration   `yaml:"timeout"` // Deadline of a call, including the wait for durability
+}
+
+// ClientTLSConfig defines TLS towards a server, and the certificate presented for mTLS
+type ClientTLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"`     // CA bundle of the server certificate; empty trusts the system roots
+	CertFile   string `yaml:"cert_file"`   // Client certificate; its Organization (O) is the org with mtls auth
+	KeyFile    string `yaml:"key_file"`    // Key of cert_file
+	ServerName string `yaml:"server_name"` // Overrides the host name checked against the server certificate
+}
+
+// AgentFileConfig defines a group of tailed files
+type AgentFileConfig struct {
+	Paths    []string `yaml:"paths"`    // Glob patterns, re-evaluated every poll
+	Format   string   `yaml:"format"`   // text or json
+	OrgID    string   `yaml:"org_id"`   // client_source_org_id; empty uses the authenticated org
+	Category string   `yaml:"category"` // Optional schema category of the lines
+	StartAt  string   `yaml:"start_at"` // beginning or end, for files without state
+	Manifest bool     `yaml:"manifest"` // Record every line's request_id and server hash in manifest_dir
+}
+
+// LoadAgentConfig loads agent configuration from the specified YAML file path
+func LoadAgentConfig(path string) (*AgentConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file '%s': %w", path, err)
+	}
+
+	var cfg AgentConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML config file: %w", err)
+	}
+	if key := os.Getenv("LOGCHAIN_API_KEY"); key != "" {
+		cfg.Server.APIKey = key
+	}
+
+	// Set defaults
+	cfg.SetDefaults()
+
+	// Validate
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// SetDefaults sets reasonable default values for the agent configuration
+func (c *AgentConfig) SetDefaults() {
+	if c.Name == "" {
+		c.Name, _ = os.Hostname()
+	}
+	if c.Server.Timeout <= 0 {
+		c.Server.Timeout = 30 * time.Second
+		fmt.Printf("Warning: server.timeout not set or invalid, defaulting to %v\n", c.Server.Timeout)
+	}
+	if c.StateDir == "" {
+		c.StateDir = "/var/lib/logchain-agent"
+		fmt.Printf("Warning: state_dir not set, defaulting to %s\n", c.StateDir)
+	}
+	if c.ManifestDir == "" {
+		c.ManifestDir = filepath.Join(c.StateDir, "manifests")
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 250 * time.Millisecond
+		fmt.Printf("Warning: poll_interval not set or invalid, defaulting to %v\n", c.PollInterval)
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+		fmt.Printf("Warning: batch_size not set or invalid, defaulting to %d\n", c.BatchSize)
+	}
+	if c.BatchTimeout <= 0 {
+		c.BatchTimeout = time.Second
+		fmt.Printf("Warning: batch_timeout not set or invalid, defaulting to %v\n", c.BatchTimeout)
+	}
+	if c.MaxLineSize <= 0 {
+		c.MaxLineSize = 1024 * 1024
+		fmt.Printf("Warning: max_line_size not set or invalid, defaulting to %d\n", c.MaxLineSize)
+	}
+	for i := range c.Files {
+		if c.Files[i].Format == "" {
+			c.Files[i].Format = AgentFormatText
+		}
+		if c.Files[i].StartAt == "" {
+			c.Files[i].StartAt = AgentStartBeginning
+		}
+	}
+}
+
+// Validate validates the agent configuration
+func (c *AgentConfig) Validate() error {
+	if c.Server.Address == "" {
+		return fmt.Errorf("server.address is required")
+	}
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		return fmt.Errorf("server.tls: cert_file and key_file must be set together")
+	}
+	if len(c.Files) == 0 {
+		return fmt.Errorf("files: at least one entry is required")
+	}
+	for i, file := range c.Files {
+		if len(file.Paths) == 0 {
+			return fmt.Errorf("files[%d]: paths is required", i)
+		}
+		if file.Format != AgentFormatText && file.Format != AgentFormatJSON {
+			return fmt.Errorf("files[%d]: format must be '%s' or '%s', got '%s'", i, AgentFormatText, AgentFormatJSON, file.Format)
+		}
+		if file.StartAt != AgentStartBeginning && file.StartAt != AgentStartEnd {
+			return fmt.Errorf("files[%d]: start_at must be '%s' or '%s', got '%s'", i, AgentStartBeginning, AgentStartEnd, file.StartAt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Blob store backends
+const (
+	BlobStoreTypeFilesystem = "filesystem" // Local or mounted directory
+	BlobStoreTypeS3         = "s3"         // S3-compatible object storage (AWS S3, MinIO, ...)
+)
+
+// blobKeyPrefixPattern keeps prefixes out of the on-chain key=value record and the filesystem root
+var blobKeyPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// BlobStoreConfig selects where log content above the size threshold is kept off chain.
+// The ingestion service writes to it; the query service reads from it and, like the engine, deletes
+// the blobs of erased logs. All three must reach the same storage.
+type BlobStoreConfig struct {
+	Enabled        bool                      `yaml:"enabled"`
+	ThresholdBytes int                       `yaml:"threshold_bytes"` // Ingestion only: content larger than this is stored off chain
+	Type           string                    `yaml:"type"`            // filesystem or s3
+	Filesystem     FilesystemBlobStoreConfig `yaml:"filesystem"`
+	S3             S3BlobStoreConfig         `yaml:"s3"`
+}
+
+// FilesystemBlobStoreConfig defines the filesystem blob store
+type FilesystemBlobStoreConfig struct {
+	Dir string `yaml:"dir"` // Root directory; blobs are stored as <dir>/<org>/<algorithm>/<xx>/<digest>
+}
+
+// S3BlobStoreConfig defines the S3-compatible blob store
+type S3BlobStoreConfig struct {
+	Endpoint        string `yaml:"endpoint"`          // host[:port], e.g. s3.amazonaws.com or minio:9000
+	Region          string `yaml:"region"`            // Optional for MinIO
+	Bucket          string `yaml:"bucket"`            // Must exist
+	Prefix          string `yaml:"prefix"`            // Optional key prefix, e.g. "logs"
+	AccessKeyID     string `yaml:"access_key_id"`     // Empty reads AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY
+	SecretAccessKey string `yaml:"secret_access_key"` // Prefer the environment over the config file
+	UseSSL          bool   `yaml:"use_ssl"`
+}
+
+// SetDefaults sets reasonable default values for blob store configuration
+func (c *BlobStoreConfig) SetDefaults() {
+	if !c.Enabled {
+		return
+	}
+	if c.ThresholdBytes <= 0 {
+		c.ThresholdBytes = 64 * 1024
+		fmt.Printf("Warning: blob_store.threshold_bytes not set or invalid, defaulting to %d\n", c.ThresholdBytes)
+	}
+	if c.Type == "" {
+		c.Type = BlobStoreTypeFilesystem
+		fmt.Printf("Warning: blob_store.type not set, defaulting to %s\n", c.Type)
+	}
+}
+
+// Validate checks the blob store configuration
+func (c *BlobStoreConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Type {
+	case BlobStoreTypeFilesystem:
+		if c.Filesystem.Dir == "" {
+			return fmt.Errorf("filesystem.dir is required")
+		}
+	case BlobStoreTypeS3:
+		if c.S3.Endpoint == "" {
+			return fmt.Errorf("s3.endpoint is required")
+		}
+		if c.S3.Bucket == "" {
+			return fmt.Errorf("s3.bucket is required")
+		}
+		if c.S3.Prefix != "" && !blobKeyPrefixPattern.MatchString(c.S3.Prefix) {
+			return fmt.Errorf("s3.prefix '%s' must be slash-separated segments of letters, digits, '.', '_' and '-'", c.S3.Prefix)
+		}
+		if (c.S3.AccessKeyID == "") != (c.S3.SecretAccessKey == "") {
+			return fmt.Errorf("s3.access_key_id and s3.secret_access_key must be set together")
+		}
+	default:
+		return fmt.Errorf("unsupported blob store type '%s'", c.Type)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import "fmt"
+
+// KeyProviderTypeFile reads org data keys from a local keystore file wrapped by a master key
+const KeyProviderTypeFile = "file"
+
+// KeyProviderConfig selects where per-org data keys come from; shared by the engine and query services
+type KeyProviderConfig struct {
+	Type string             `yaml:"type"` // file
+	File FileKeystoreConfig `yaml:"file"`
+}
+
+// FileKeystoreConfig defines the file-based keystore
+type FileKeystoreConfig struct {
+	Path          string `yaml:"path"`            // JSON keystore holding the wrapped org data keys
+	MasterKeyFile string `yaml:"master_key_file"` // Base64-encoded 32-byte key that wraps the data keys
+	AutoGenerate  bool   `yaml:"auto_generate"`   // Create a data key for orgs that have none (engine and ingestion)
+}
+
+// SetDefaults sets reasonable default values for key provider configuration
+func (c *KeyProviderConfig) SetDefaults() {
+	if c.Type == "" {
+		c.Type = KeyProviderTypeFile
+		fmt.Printf("Warning: encryption.key_provider.type not set, defaulting to %s\n", c.Type)
+	}
+}
+
+// Validate checks the key provider configuration
+func (c *KeyProviderConfig) Validate() error {
+	switch c.Type {
+	case KeyProviderTypeFile:
+		if c.File.Path == "" {
+			return fmt.Errorf("file.path is required")
+		}
+		if c.File.MasterKeyFile == "" {
+			return fmt.Errorf("file.master_key_file is required")
+		}
+	default:
+		return fmt.Errorf("unsupported key provider type '%s'", c.Type)
+	}
+	return nil
+}
+
+// EncryptionConfig controls encryption of on-chain log content by the engine, and of content
+// offloaded to the blob store by the ingestion service
+type EncryptionConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	Orgs        []string          `yaml:"orgs"` // Orgs whose content is encrypted; empty means all orgs
+	KeyProvider KeyProviderConfig `yaml:"key_provider"`
+}
+
+// SetDefaults sets reasonable default values for encryption configuration
+func (c *EncryptionConfig) SetDefaults() {
+	if c.Enabled {
+		c.KeyProvider.SetDefaults()
+	}
+}
+
+// Validate checks the encryption configuration
+func (c *EncryptionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if err := c.KeyProvider.Validate(); err != nil {
+		return fmt.Errorf("key_provider: %w", err)
+	}
+	return nil
+}
+
+// QueryEncryptionConfig controls decryption of on-chain log content by the query service
+type QueryEncryptionConfig struct {
+	Enabled       bool                `yaml:"enabled"`
+	KeyProvider   KeyProviderConfig   `yaml:"key_provider"`   // Must reach the same keys as the engine
+	DecryptGrants map[string][]string `yaml:"decrypt_grants"` // owning org -> audit member IDs allowed to read its content
+}
+
+// SetDefaults sets reasonable default values for query encryption configuration
+func (c *QueryEncryptionConfig) SetDefaults() {
+	if c.Enabled {
+		c.KeyProvider.SetDefaults()
+	}
+}
+
+// Validate checks the query encryption configuration
+func (c *QueryEncryptionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if err := c.KeyProvider.Validate(); err != nil {
+		return fmt.Errorf("key_provider: %w", err)
+	}
+	return nil
+}
+
+// CanDecrypt reports whether the owning org has granted an audit member access to its content
+func (c *QueryEncryptionConfig) CanDecrypt(orgID, memberID string) bool {
+	if !c.Enabled || memberID == "" {
+		return false
+	}
+	for _, granted := range c.DecryptGrants[orgID] {
+		if granted == memberID {
+			return true
+		}
+	}
+	return false
+}
@@ -73,6 +73,25 @@ func (c *WorkerConfig) SetDefaults() {
 	}
 }
 
+// ErasureConfig defines how the engine applies retention policies and anchors erasure tombstones
+type ErasureConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	CheckInterval string `yaml:"check_interval"` // How often expired content is erased and pending tombstones are anchored
+	BatchSize     int    `yaml:"batch_size"`     // Maximum erasures and tombstones handled per round
+}
+
+// SetDefaults sets reasonable default values for erasure configuration
+func (c *ErasureConfig) SetDefaults() {
+	if c.CheckInterval == "" {
+		c.CheckInterval = "1m"
+		fmt.Printf("Warning: erasure.check_interval not set, defaulting to %s\n", c.CheckInterval)
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+		fmt.Printf("Warning: erasure.batch_size not set or invalid, defaulting to %d\n", c.BatchSize)
+	}
+}
+
 // EngineMonitoringConfig defines monitoring configuration for engine
 type EngineMonitoringConfig struct {
 	EnableMetrics   bool   `yaml:"enable_metrics"`    // Enable metrics collection
@@ -116,6 +135,15 @@ type EngineConfig struct {
 
 	// Blockchain Client Configuration
 	BlockchainClientConfigPath string `yaml:"blockchain_client_config_path"`
+
+	// On-chain Content Encryption Configuration
+	Encryption EncryptionConfig `yaml:"encryption"`
+
+	// Erasure (Crypto-shredding) Configuration
+	Erasure ErasureConfig `yaml:"erasure"`
+
+	// Off-chain Blob Store Configuration; the eraser deletes the blobs of expired logs
+	BlobStore BlobStoreConfig `yaml:"blob_store"`
 }
 
 // LoadEngineConfig loads configuration from the specified YAML file path
@@ -136,6 +164,11 @@ func LoadEngineConfig(path string) (*EngineConfig, error) {
 	cfg.KafkaConsumer.SetDefaults()
 	cfg.Worker.SetDefaults()
 	cfg.Monitoring.SetDefaults()
+	cfg.Encryption.SetDefaults()
+	cfg.BlobStore.SetDefaults()
+	if cfg.Erasure.Enabled {
+		cfg.Erasure.SetDefaults()
+	}
 
 	// Set default for business rules
 	if cfg.MaxTaskRetries <= 0 {
@@ -148,5 +181,15 @@ func LoadEngineConfig(path string) (*EngineConfig, error) {
 		return nil, fmt.Errorf("database configuration error: %w", err)
 	}
 
+	// Validate encryption configuration
+	if err := cfg.Encryption.Validate(); err != nil {
+		return nil, fmt.Errorf("encryption configuration error: %w", err)
+	}
+
+	// Validate blob store configuration
+	if err := cfg.BlobStore.Validate(); err != nil {
+		return nil, fmt.Errorf("blob_store configuration error: %w", err)
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// GatewayHMACSecretEnv holds the shared gateway secret when hmac_secret is not set; the ingress reads the same variable
+const GatewayHMACSecretEnv = "GATEWAY_HMAC_SECRET"
+
+// GatewayAuthConfig defines how a service verifies that identity headers were set by the nginx ingress
+type GatewayAuthConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	HMACSecret string        `yaml:"hmac_secret"` // Empty reads GATEWAY_HMAC_SECRET; prefer the environment over the config file
+	MaxSkew    time.Duration `yaml:"max_skew"`    // Accepted age of a signature, covering clock differences and queueing
+}
+
+// SetDefaults sets reasonable default values for gateway authentication configuration
+func (c *GatewayAuthConfig) SetDefaults() {
+	if !c.Enabled {
+		return
+	}
+	if c.HMACSecret == "" {
+		c.HMACSecret = os.Getenv(GatewayHMACSecretEnv)
+	}
+	if c.MaxSkew == 0 {
+		c.MaxSkew = 30 * time.Second
+		fmt.Printf("Warning: gateway_auth.max_skew not set, defaulting to %v\n", c.MaxSkew)
+	}
+}
+
+// Validate checks the gateway authentication configuration
+func (c *GatewayAuthConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.HMACSecret) < 32 {
+		return fmt.Errorf("hmac_secret (or %s) must be at least 32 characters", GatewayHMACSecretEnv)
+	}
+	if c.MaxSkew < 0 {
+		return fmt.Errorf("max_skew cannot be negative")
+	}
+	return nil
+}
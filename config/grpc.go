@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// gRPC authentication methods, tried in the configured order
+const (
+	GrpcAuthMethodAPIKey  = "api_key" // x-api-key metadata checked against api_keys_file
+	GrpcAuthMethodMTLS    = "mtls"    // Verified client certificate; the org is its subject Organization (O)
+	GrpcAuthMethodGateway = "gateway" // x-client-org-id metadata set and signed by the nginx ingress; requires gateway_auth
+)
+
+// gRPC client certificate policies
+const (
+	GrpcClientAuthRequest = "request" // Verify a client certificate if one is sent
+	GrpcClientAuthRequire = "require" // Reject connections without a valid client certificate
+)
+
+// GrpcAuthConfig defines how callers of the gRPC ingestion API are authenticated
+type GrpcAuthConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Methods        []string      `yaml:"methods"`         // api_key, mtls and/or gateway
+	APIKeysFile    string        `yaml:"api_keys_file"`   // API keys in the ingress api-keys.json format
+	ReloadInterval time.Duration `yaml:"reload_interval"` // How often api_keys_file is re-read; new and revoked keys apply after this
+}
+
+// SetDefaults sets reasonable default values for gRPC authentication configuration
+func (c *GrpcAuthConfig) SetDefaults() {
+	if !c.Enabled {
+		return
+	}
+	if len(c.Methods) == 0 {
+		c.Methods = []string{GrpcAuthMethodAPIKey}
+		fmt.Printf("Warning: grpc_auth.methods not set, defaulting to %v\n", c.Methods)
+	}
+	if c.ReloadInterval == 0 {
+		c.ReloadInterval = 30 * time.Second
+		fmt.Printf("Warning: grpc_auth.reload_interval not set, defaulting to %v\n", c.ReloadInterval)
+	}
+}
+
+// Validate checks the gRPC authentication configuration against the listener's TLS and gateway configuration
+func (c *GrpcAuthConfig) Validate(tls GrpcTLSConfig, gateway GatewayAuthConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	for _, method := range c.Methods {
+		switch method {
+		case GrpcAuthMethodAPIKey:
+			if c.APIKeysFile == "" {
+				return fmt.Errorf("api_keys_file is required for method '%s'", method)
+			}
+		case GrpcAuthMethodMTLS:
+			if !tls.Enabled || tls.ClientCAFile == "" {
+				return fmt.Errorf("method '%s' requires grpc_tls.enabled and grpc_tls.client_ca_file", method)
+			}
+		case GrpcAuthMethodGateway:
+			if !gateway.Enabled {
+				return fmt.Errorf("method '%s' requires gateway_auth.enabled", method)
+			}
+		default:
+			return fmt.Errorf("unknown method '%s' (expected %s, %s or %s)",
+				method, GrpcAuthMethodAPIKey, GrpcAuthMethodMTLS, GrpcAuthMethodGateway)
+		}
+	}
+	return nil
+}
+
+// HasMethod reports whether a method is enabled
+func (c *GrpcAuthConfig) HasMethod(method string) bool {
+	for _, m := range c.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// GrpcTLSConfig defines native TLS on the gRPC listener; with a client CA it also verifies client certificates (mTLS)
+type GrpcTLSConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"` // CA bundle client certificates must chain to; empty disables mTLS
+	ClientAuth   string `yaml:"client_auth"`    // request or require
+}
+
+// SetDefaults sets reasonable default values for gRPC TLS configuration
+func (c *GrpcTLSConfig) SetDefaults() {
+	if !c.Enabled || c.ClientCAFile == "" {
+		return
+	}
+	if c.ClientAuth == "" {
+		c.ClientAuth = GrpcClientAuthRequire
+		fmt.Printf("Warning: grpc_tls.client_auth not set, defaulting to %s\n", c.ClientAuth)
+	}
+}
+
+// Validate checks the gRPC TLS configuration
+func (c *GrpcTLSConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("cert_file and key_file are required")
+	}
+	if c.ClientCAFile != "" && c.ClientAuth != GrpcClientAuthRequest && c.ClientAuth != GrpcClientAuthRequire {
+		return fmt.Errorf("client_auth must be '%s' or '%s', got '%s'", GrpcClientAuthRequest, GrpcClientAuthRequire, c.ClientAuth)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+
+	"tlng/internal/hashing"
+)
+
+// HashingConfig selects the log digest algorithm; shared by the ingestion and query services
+type HashingConfig struct {
+	DefaultAlgorithm string            `yaml:"default_algorithm"` // sha256, sha3-256 or sm3
+	OrgAlgorithms    map[string]string `yaml:"org_algorithms"`    // source_org_id -> algorithm, overrides the default
+}
+
+// SetDefaults sets reasonable default values for hashing configuration
+func (c *HashingConfig) SetDefaults() {
+	if c.DefaultAlgorithm == "" {
+		c.DefaultAlgorithm = string(hashing.Default)
+		fmt.Printf("Warning: hashing.default_algorithm not set, defaulting to %s\n", c.DefaultAlgorithm)
+	}
+}
+
+// Validate checks that all configured algorithms are supported
+func (c *HashingConfig) Validate() error {
+	if _, err := hashing.ParseAlgorithm(c.DefaultAlgorithm); err != nil {
+		return fmt.Errorf("default_algorithm: %w", err)
+	}
+	for orgID, algorithm := range c.OrgAlgorithms {
+		if _, err := hashing.ParseAlgorithm(algorithm); err != nil {
+			return fmt.Errorf("org_algorithms[%s]: %w", orgID, err)
+		}
+	}
+	return nil
+}
+
+// AlgorithmFor returns the algorithm configured for an org, or the default
+func (c *HashingConfig) AlgorithmFor(orgID string) hashing.Algorithm {
+	if algorithm, ok := c.OrgAlgorithms[orgID]; ok {
+		if parsed, err := hashing.ParseAlgorithm(algorithm); err == nil {
+			return parsed
+		}
+	}
+	algorithm, err := hashing.ParseAlgorithm(c.DefaultAlgorithm)
+	if err != nil {
+		return hashing.Default
+	}
+	return algorithm
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"time"
 
+	"tlng/internal/models"
+
 	"gopkg.in/yaml.v2"
 )
 
@@ -22,6 +24,9 @@ type KafkaProducerConfig struct {
 	RequiredAcks string `yaml:"required_acks"`
 	Async        bool   `yaml:"async"`
 
+	// Partitioning: "request_id" (default, spread by load) or "source_org_id" (hash, per-org ordering)
+	PartitionKey string `yaml:"partition_key"`
+
 	// Performance settings
 	WriteTimeout time.Duration `yaml:"write_timeout"`
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
@@ -33,6 +38,7 @@ type BatchProcessorConfig struct {
 	BatchTimeout        time.Duration `yaml:"batch_timeout"`
 	MaxBufferSize       int           `yaml:"max_buffer_size"`
 	FlushChannelBuffer  int           `yaml:"flush_channel_buffer"`  // Buffer size for flush channel
+	RetryAfter          time.Duration `yaml:"retry_after"`           // Retry-After sent when max_buffer_size is reached
 }
 
 // SetDefaults sets reasonable default values for batch processor configuration
@@ -53,8 +59,205 @@ func (c *BatchProcessorConfig) SetDefaults() {
 		c.FlushChannelBuffer = 100
 		fmt.Printf("Warning: batch_processor.flush_channel_buffer not set, defaulting to %d\n", c.FlushChannelBuffer)
 	}
+	if c.RetryAfter == 0 {
+		c.RetryAfter = time.Second
+		fmt.Printf("Warning: batch_processor.retry_after not set, defaulting to %v\n", c.RetryAfter)
+	}
+}
+
+// SpoolConfig defines the local disk spool for batches that could not be written to the DB or Kafka
+type SpoolConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	Dir              string        `yaml:"dir"`                // Directory holding spooled batch segments
+	MaxBytes         int64         `yaml:"max_bytes"`          // Batches are dropped once the spool reaches this size
+	ReplayInterval   time.Duration `yaml:"replay_interval"`    // How often the spool is checked for batches to replay
+	MaxReplayBackoff time.Duration `yaml:"max_replay_backoff"` // Upper bound for the backoff after failed replays
+}
+
+// SetDefaults sets reasonable default values for spool configuration
+func (c *SpoolConfig) SetDefaults() {
+	if c.Dir == "" {
+		c.Dir = "./data/spool"
+		fmt.Printf("Warning: spool.dir not set, defaulting to %s\n", c.Dir)
+	}
+	if c.MaxBytes == 0 {
+		c.MaxBytes = 1 << 30 // 1 GiB
+		fmt.Printf("Warning: spool.max_bytes not set, defaulting to %d\n", c.MaxBytes)
+	}
+	if c.ReplayInterval == 0 {
+		c.ReplayInterval = 5 * time.Second
+		fmt.Printf("Warning: spool.replay_interval not set, defaulting to %v\n", c.ReplayInterval)
+	}
+	if c.MaxReplayBackoff == 0 {
+		c.MaxReplayBackoff = time.Minute
+		fmt.Printf("Warning: spool.max_replay_backoff not set, defaulting to %v\n", c.MaxReplayBackoff)
+	}
+}
+
+// OutboxConfig defines the transactional outbox between tbl_log_status and Kafka
+type OutboxConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	PollInterval  time.Duration `yaml:"poll_interval"`  // Relay wait when no unsent records are left
+	BatchSize     int           `yaml:"batch_size"`     // Records published per relay round
+	SentRetention time.Duration `yaml:"sent_retention"` // Sent records are purged after this
+}
+
+// SetDefaults sets reasonable default values for outbox configuration
+func (c *OutboxConfig) SetDefaults() {
+	if c.PollInterval == 0 {
+		c.PollInterval = 100 * time.Millisecond
+		fmt.Printf("Warning: outbox.poll_interval not set, defaulting to %v\n", c.PollInterval)
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 500
+		fmt.Printf("Warning: outbox.batch_size not set, defaulting to %d\n", c.BatchSize)
+	}
+	if c.SentRetention == 0 {
+		c.SentRetention = 24 * time.Hour
+		fmt.Printf("Warning: outbox.sent_retention not set, defaulting to %v\n", c.SentRetention)
+	}
+}
+
+// IdempotencyConfig defines how long idempotency keys are remembered
+type IdempotencyConfig struct {
+	TTL             time.Duration `yaml:"ttl"`              // A retry after this is treated as a new submission
+	CleanupInterval time.Duration `yaml:"cleanup_interval"` // How often expired keys are purged
+}
+
+// SetDefaults sets reasonable default values for idempotency configuration
+func (c *IdempotencyConfig) SetDefaults() {
+	if c.TTL == 0 {
+		c.TTL = 24 * time.Hour
+		fmt.Printf("Warning: idempotency.ttl not set, defaulting to %v\n", c.TTL)
+	}
+	if c.CleanupInterval == 0 {
+		c.CleanupInterval = 10 * time.Minute
+		fmt.Printf("Warning: idempotency.cleanup_interval not set, defaulting to %v\n", c.CleanupInterval)
+	}
+}
+
+// Clock skew actions for client timestamps outside ClientTimestampConfig.MaxSkew
+const (
+	ClockSkewActionFlag   = "flag"   // Accept the log and mark the client timestamp as flagged
+	ClockSkewActionReject = "reject" // Reject the submission
+)
+
+// ClientTimestampConfig defines how far a client-reported event time may be from the receive time
+type ClientTimestampConfig struct {
+	MaxSkew time.Duration `yaml:"max_skew"` // Allowed difference in either direction
+	Action  string        `yaml:"action"`   // "flag" or "reject"
+}
+
+// SetDefaults sets reasonable default values for client timestamp configuration
+func (c *ClientTimestampConfig) SetDefaults() {
+	if c.MaxSkew == 0 {
+		c.MaxSkew = 15 * time.Minute
+		fmt.Printf("Warning: client_timestamp.max_skew not set, defaulting to %v\n", c.MaxSkew)
+	}
+	if c.Action == "" {
+		c.Action = ClockSkewActionFlag
+		fmt.Printf("Warning: client_timestamp.action not set, defaulting to %s\n", c.Action)
+	}
+}
+
+// AttestationConfig selects what the engine puts on chain for each org
+type AttestationConfig struct {
+	DefaultMode string            `yaml:"default_mode"` // "full" or "hash_only"
+	OrgModes    map[string]string `yaml:"org_modes"`    // source_org_id -> mode, overrides the default
+}
+
+// SetDefaults sets reasonable default values for attestation configuration
+func (c *AttestationConfig) SetDefaults() {
+	if c.DefaultMode == "" {
+		c.DefaultMode = models.AttestationModeFull
+		fmt.Printf("Warning: attestation.default_mode not set, defaulting to %s\n", c.DefaultMode)
+	}
+}
+
+// Validate checks that all configured modes are known
+func (c *AttestationConfig) Validate() error {
+	if !validAttestationMode(c.DefaultMode) {
+		return fmt.Errorf("default_mode must be '%s' or '%s', got '%s'", models.AttestationModeFull, models.AttestationModeHashOnly, c.DefaultMode)
+	}
+	for orgID, mode := range c.OrgModes {
+		if !validAttestationMode(mode) {
+			return fmt.Errorf("org_modes[%s] must be '%s' or '%s', got '%s'", orgID, models.AttestationModeFull, models.AttestationModeHashOnly, mode)
+		}
+	}
+	return nil
+}
+
+// ModeFor returns the attestation mode configured for an org, or the default
+func (c *AttestationConfig) ModeFor(orgID string) string {
+	if mode, ok := c.OrgModes[orgID]; ok {
+		return mode
+	}
+	return c.DefaultMode
 }
 
+func validAttestationMode(mode string) bool {
+	return mode == models.AttestationModeFull || mode == models.AttestationModeHashOnly
+}
+
+// SchemaRegistryConfig defines how the ingestion service reads the per-org log schema registry
+type SchemaRegistryConfig struct {
+	CacheTTL time.Duration `yaml:"cache_ttl"` // How long a looked-up schema, or its absence, is reused before the state DB is asked again
+}
+
+// SetDefaults sets reasonable default values for schema registry configuration
+func (c *SchemaRegistryConfig) SetDefaults() {
+	if c.CacheTTL == 0 {
+		c.CacheTTL = 30 * time.Second
+		fmt.Printf("Warning: schema_registry.cache_ttl not set, defaulting to %v\n", c.CacheTTL)
+	}
+}
+
+// SignaturesConfig defines how client signatures on log hashes are checked
+type SignaturesConfig struct {
+	CacheTTL     time.Duration `yaml:"cache_ttl"`     // How long a looked-up public key, or its absence, is reused; revocations apply after this
+	RequiredOrgs []string      `yaml:"required_orgs"` // Orgs whose submissions must be signed; others may sign optionally
+}
+
+// SetDefaults sets reasonable default values for client signature configuration
+func (c *SignaturesConfig) SetDefaults() {
+	if c.CacheTTL == 0 {
+		c.CacheTTL = 30 * time.Second
+		fmt.Printf("Warning: signatures.cache_ttl not set, defaulting to %v\n", c.CacheTTL)
+	}
+}
+
+// Required reports whether submissions of an org must carry a signature
+func (c *SignaturesConfig) Required(orgID string) bool {
+	for _, required := range c.RequiredOrgs {
+		if required == orgID {
+			return true
+		}
+	}
+	return false
+}
+
+// AckConfig defines how long a submission may block for the durable and attested acknowledgement levels
+type AckConfig struct {
+	DefaultAttestTimeout time.Duration `yaml:"default_attest_timeout"` // Wait used when the caller does not choose a deadline
+	MaxAttestTimeout     time.Duration `yaml:"max_attest_timeout"`     // Upper bound for caller-chosen deadlines
+	AttestPollInterval   time.Duration `yaml:"attest_poll_interval"`   // How often the state DB is polled for COMPLETED
+}
+
+// SetDefaults sets reasonable default values for acknowledgement configuration
+func (c *AckConfig) SetDefaults() {
+	if c.DefaultAttestTimeout == 0 {
+		c.DefaultAttestTimeout = 5 * time.Second
+		fmt.Printf("Warning: ack.default_attest_timeout not set, defaulting to %v\n", c.DefaultAttestTimeout)
+	}
+	if c.MaxAttestTimeout == 0 {
+		c.MaxAttestTimeout = 8 * time.Second
+		fmt.Printf("Warning: ack.max_attest_timeout not set, defaulting to %v\n", c.MaxAttestTimeout)
+	}
+	if c.AttestPollInterval == 0 {
+		c.AttestPollInterval = 200 * time.Millisecond
+		fmt.Printf("Warning: ack.attest_poll_interval not set, defaulting to %v\n", c.AttestPollInterval)
+	}
+}
 
 // HttpServerConfig defines HTTP server configuration
 type HttpServerConfig struct {
@@ -76,9 +279,32 @@ type ApiGatewayConfig struct {
 	HttpListenAddr string `yaml:"http_listen_addr"`
 	GrpcListenAddr string `yaml:"grpc_listen_addr"`
 
+	MaxBatchEntries   int `yaml:"max_batch_entries"`    // Entry limit for POST /v1/logs/batch and SubmitLogsBatch
+	StreamMaxInFlight int `yaml:"stream_max_in_flight"` // Unacknowledged entries per SubmitLogStream before reads pause
+
+	Gateway  GatewayAuthConfig `yaml:"gateway_auth"` // Verification of the identity headers set by the ingress
+	GrpcAuth GrpcAuthConfig    `yaml:"grpc_auth"`    // Authentication of gRPC callers
+	GrpcTLS  GrpcTLSConfig     `yaml:"grpc_tls"`     // Native TLS/mTLS on grpc_listen_addr
+	Syslog   SyslogConfig      `yaml:"syslog"`       // Native syslog receiver
+	OTLP     OTLPConfig        `yaml:"otlp"`         // OpenTelemetry logs receiver
+
+	KafkaSources []KafkaSourceConfig `yaml:"kafka_sources"` // Native adapters consuming external Kafka topics
+	S3Sources    []S3SourceConfig    `yaml:"s3_sources"`    // Adapters polling S3-compatible buckets for log files
+
 	Database       DatabaseConfig       `yaml:"database"`       // Use unified DatabaseConfig
 	KafkaProducer  KafkaProducerConfig  `yaml:"kafka_producer"` // Local Kafka producer config
 	BatchProcessor BatchProcessorConfig `yaml:"batch_processor"`
+	Spool          SpoolConfig          `yaml:"spool"`
+	Outbox         OutboxConfig         `yaml:"outbox"`
+	Ack            AckConfig            `yaml:"ack"`
+	Idempotency    IdempotencyConfig    `yaml:"idempotency"`
+	ClientTimestamp ClientTimestampConfig `yaml:"client_timestamp"`
+	Hashing        HashingConfig        `yaml:"hashing"`
+	Attestation    AttestationConfig    `yaml:"attestation"`
+	SchemaRegistry SchemaRegistryConfig `yaml:"schema_registry"`
+	Signatures     SignaturesConfig     `yaml:"signatures"`
+	BlobStore      BlobStoreConfig      `yaml:"blob_store"`
+	Encryption     EncryptionConfig     `yaml:"encryption"` // Seals offloaded content; must match the engine's
 	HttpServer     HttpServerConfig     `yaml:"http_server"`
 	Monitoring     GatewayMonitoringConfig     `yaml:"monitoring"`
 }
@@ -101,9 +327,78 @@ func LoadApiGatewayConfig(path string) (*ApiGatewayConfig, error) {
 	// Set defaults for batch processor configuration
 	cfg.BatchProcessor.SetDefaults()
 
+	// Set defaults for spool configuration
+	if cfg.Spool.Enabled {
+		cfg.Spool.SetDefaults()
+	}
+
+	// Set defaults for outbox configuration
+	if cfg.Outbox.Enabled {
+		cfg.Outbox.SetDefaults()
+	}
+
+	// Set defaults for acknowledgement configuration
+	cfg.Ack.SetDefaults()
+
+	// Set defaults for idempotency configuration
+	cfg.Idempotency.SetDefaults()
+
+	// Set defaults for client timestamp configuration
+	cfg.ClientTimestamp.SetDefaults()
+
+	// Set defaults for hashing configuration
+	cfg.Hashing.SetDefaults()
+
+	// Set defaults for attestation configuration
+	cfg.Attestation.SetDefaults()
+
+	// Set defaults for schema registry configuration
+	cfg.SchemaRegistry.SetDefaults()
+
+	// Set defaults for client signature configuration
+	cfg.Signatures.SetDefaults()
+
+	// Set defaults for blob store configuration
+	cfg.BlobStore.SetDefaults()
+
+	// Set defaults for offloaded content encryption
+	cfg.Encryption.SetDefaults()
+
+	// Set defaults for gateway authentication configuration
+	cfg.Gateway.SetDefaults()
+
+	// Set defaults for gRPC authentication and TLS configuration
+	cfg.GrpcAuth.SetDefaults()
+	cfg.GrpcTLS.SetDefaults()
+
+	// Set defaults for syslog receiver configuration
+	cfg.Syslog.SetDefaults()
+
+	// Set defaults for OTLP receiver configuration
+	cfg.OTLP.SetDefaults()
+
+	// Set defaults for Kafka source adapters
+	for i := range cfg.KafkaSources {
+		cfg.KafkaSources[i].SetDefaults(fmt.Sprintf("kafka_sources[%d]", i))
+	}
+
+	// Set defaults for S3 source adapters
+	for i := range cfg.S3Sources {
+		cfg.S3Sources[i].SetDefaults(fmt.Sprintf("s3_sources[%d]", i))
+	}
+
+	if cfg.MaxBatchEntries <= 0 {
+		cfg.MaxBatchEntries = 1000
+		fmt.Printf("Warning: max_batch_entries not set or invalid, defaulting to %d\n", cfg.MaxBatchEntries)
+	}
+	if cfg.StreamMaxInFlight <= 0 {
+		cfg.StreamMaxInFlight = 1000
+		fmt.Printf("Warning: stream_max_in_flight not set or invalid, defaulting to %d\n", cfg.StreamMaxInFlight)
+	}
+
 	// Validation
-	if cfg.HttpListenAddr == "" && cfg.GrpcListenAddr == "" {
-		return nil, fmt.Errorf("configuration error: at least one of http_listen_addr or grpc_listen_addr must be configured")
+	if cfg.HttpListenAddr == "" && cfg.GrpcListenAddr == "" && !cfg.Syslog.Enabled && len(cfg.KafkaSources) == 0 && len(cfg.S3Sources) == 0 {
+		return nil, fmt.Errorf("configuration error: at least one of http_listen_addr, grpc_listen_addr, syslog, kafka_sources or s3_sources must be configured")
 	}
 
 	// Validate database configuration
@@ -111,5 +406,81 @@ func LoadApiGatewayConfig(path string) (*ApiGatewayConfig, error) {
 		return nil, fmt.Errorf("database configuration error: %w", err)
 	}
 
+	if err := cfg.Hashing.Validate(); err != nil {
+		return nil, fmt.Errorf("hashing configuration error: %w", err)
+	}
+
+	if err := cfg.Attestation.Validate(); err != nil {
+		return nil, fmt.Errorf("attestation configuration error: %w", err)
+	}
+
+	if err := cfg.BlobStore.Validate(); err != nil {
+		return nil, fmt.Errorf("blob_store configuration error: %w", err)
+	}
+
+	if err := cfg.Encryption.Validate(); err != nil {
+		return nil, fmt.Errorf("encryption configuration error: %w", err)
+	}
+
+	if err := cfg.Gateway.Validate(); err != nil {
+		return nil, fmt.Errorf("gateway_auth configuration error: %w", err)
+	}
+
+	if err := cfg.GrpcTLS.Validate(); err != nil {
+		return nil, fmt.Errorf("grpc_tls configuration error: %w", err)
+	}
+
+	if err := cfg.GrpcAuth.Validate(cfg.GrpcTLS, cfg.Gateway); err != nil {
+		return nil, fmt.Errorf("grpc_auth configuration error: %w", err)
+	}
+
+	if err := cfg.Syslog.Validate(); err != nil {
+		return nil, fmt.Errorf("syslog configuration error: %w", err)
+	}
+
+	if err := cfg.OTLP.Validate(); err != nil {
+		return nil, fmt.Errorf("otlp configuration error: %w", err)
+	}
+
+	kafkaSourceNames := make(map[string]bool)
+	for i := range cfg.KafkaSources {
+		if err := cfg.KafkaSources[i].Validate(); err != nil {
+			return nil, fmt.Errorf("kafka_sources[%d] configuration error: %w", i, err)
+		}
+		if cfg.KafkaSources[i].BatchSize > cfg.MaxBatchEntries {
+			return nil, fmt.Errorf("kafka_sources[%d] configuration error: batch_size (%d) cannot be greater than max_batch_entries (%d)",
+				i, cfg.KafkaSources[i].BatchSize, cfg.MaxBatchEntries)
+		}
+		if kafkaSourceNames[cfg.KafkaSources[i].Name] {
+			return nil, fmt.Errorf("kafka_sources[%d] configuration error: duplicate name '%s'", i, cfg.KafkaSources[i].Name)
+		}
+		kafkaSourceNames[cfg.KafkaSources[i].Name] = true
+	}
+
+	s3SourceNames := make(map[string]bool)
+	for i := range cfg.S3Sources {
+		if err := cfg.S3Sources[i].Validate(); err != nil {
+			return nil, fmt.Errorf("s3_sources[%d] configuration error: %w", i, err)
+		}
+		if cfg.S3Sources[i].BatchSize > cfg.MaxBatchEntries {
+			return nil, fmt.Errorf("s3_sources[%d] configuration error: batch_size (%d) cannot be greater than max_batch_entries (%d)",
+				i, cfg.S3Sources[i].BatchSize, cfg.MaxBatchEntries)
+		}
+		if s3SourceNames[cfg.S3Sources[i].Name] {
+			return nil, fmt.Errorf("s3_sources[%d] configuration error: duplicate name '%s'", i, cfg.S3Sources[i].Name)
+		}
+		s3SourceNames[cfg.S3Sources[i].Name] = true
+	}
+
+	if cfg.ClientTimestamp.Action != ClockSkewActionFlag && cfg.ClientTimestamp.Action != ClockSkewActionReject {
+		return nil, fmt.Errorf("client_timestamp configuration error: action must be '%s' or '%s', got '%s'",
+			ClockSkewActionFlag, ClockSkewActionReject, cfg.ClientTimestamp.Action)
+	}
+
+	if cfg.Ack.DefaultAttestTimeout > cfg.Ack.MaxAttestTimeout {
+		return nil, fmt.Errorf("ack configuration error: default_attest_timeout (%v) cannot be greater than max_attest_timeout (%v)",
+			cfg.Ack.DefaultAttestTimeout, cfg.Ack.MaxAttestTimeout)
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kafka source selectors name the part of a message a field is read from
+const (
+	KafkaSelectKey    = "key"    // The message key
+	KafkaSelectValue  = "value"  // The message value, or with value:<path> a field of the JSON value
+	KafkaSelectHeader = "header" // header:<name>, the first header of that name
+	KafkaSelectRecord = "kafka"  // The record timestamp; timestamp only
+	KafkaSelectNone   = "none"   // Nothing; timestamp only
+)
+
+// Content formats of a Kafka source
+const (
+	KafkaFormatText = "text" // Submitted as log_content
+	KafkaFormatJSON = "json" // Submitted as log_json
+)
+
+// Timestamp formats of a Kafka source
+const (
+	KafkaTimestampRFC3339 = "rfc3339"
+	KafkaTimestampUnix    = "unix"
+	KafkaTimestampUnixMs  = "unix_ms"
+	KafkaTimestampUnixNs  = "unix_ns"
+)
+
+// KafkaSourceConfig defines a native Kafka source adapter consuming an external topic.
+// Offsets are committed only once the submitted entries are durable in the pipeline.
+type KafkaSourceConfig struct {
+	Name            string              `yaml:"name"`             // Used in logs and idempotency keys; defaults to the topic
+	Consumer        KafkaConsumerConfig `yaml:"consumer"`         // Brokers, topic, group_id and offset settings of the external cluster
+	Content         string              `yaml:"content"`          // Selector of the log content
+	Format          string              `yaml:"format"`           // text or json
+	Org             string              `yaml:"org"`              // Selector of the source org; empty uses default_org
+	OrgMap          map[string]string   `yaml:"org_map"`          // Optional selected value -> org; when set, unlisted values are rejected
+	DefaultOrg      string              `yaml:"default_org"`      // Org of messages without a selected org; empty rejects them
+	Timestamp       string              `yaml:"timestamp"`        // Selector of the client timestamp, kafka for the record timestamp or none
+	TimestampFormat string              `yaml:"timestamp_format"` // rfc3339, unix, unix_ms or unix_ns; the record timestamp needs none
+	Category        string              `yaml:"category"`         // Optional schema category of the submitted logs
+	BatchSize       int                 `yaml:"batch_size"`       // Messages per submission, at most max_batch_entries
+	BatchTimeout    time.Duration       `yaml:"batch_timeout"`    // Longest wait to fill a batch once it has a message
+}
+
+// SetDefaults sets reasonable default values for a Kafka source; prefix names it in warnings
+func (c *KafkaSourceConfig) SetDefaults(prefix string) {
+	if c.Name == "" {
+		c.Name = c.Consumer.Topic
+	}
+	if c.Consumer.SessionTimeout == "" {
+		c.Consumer.SessionTimeout = "30s"
+		fmt.Printf("Warning: %s.consumer.session_timeout not set, defaulting to %s\n", prefix, c.Consumer.SessionTimeout)
+	}
+	if c.Consumer.HeartbeatInterval == "" {
+		c.Consumer.HeartbeatInterval = "3s"
+		fmt.Printf("Warning: %s.consumer.heartbeat_interval not set, defaulting to %s\n", prefix, c.Consumer.HeartbeatInterval)
+	}
+	if c.Consumer.AutoOffsetReset == "" {
+		c.Consumer.AutoOffsetReset = "earliest"
+		fmt.Printf("Warning: %s.consumer.auto_offset_reset not set, defaulting to %s\n", prefix, c.Consumer.AutoOffsetReset)
+	}
+	if c.Content == "" {
+		c.Content = KafkaSelectValue
+		fmt.Printf("Warning: %s.content not set, defaulting to %s\n", prefix, c.Content)
+	}
+	if c.Format == "" {
+		c.Format = KafkaFormatText
+		fmt.Printf("Warning: %s.format not set, defaulting to %s\n", prefix, c.Format)
+	}
+	if c.Timestamp == "" {
+		c.Timestamp = KafkaSelectRecord
+		fmt.Printf("Warning: %s.timestamp not set, defaulting to %s\n", prefix, c.Timestamp)
+	}
+	if c.TimestampFormat == "" {
+		c.TimestampFormat = KafkaTimestampRFC3339
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+		fmt.Printf("Warning: %s.batch_size not set or invalid, defaulting to %d\n", prefix, c.BatchSize)
+	}
+	if c.BatchTimeout <= 0 {
+		c.BatchTimeout = 500 * time.Millisecond
+		fmt.Printf("Warning: %s.batch_timeout not set or invalid, defaulting to %v\n", prefix, c.BatchTimeout)
+	}
+}
+
+// Validate checks a Kafka source configuration
+func (c *KafkaSourceConfig) Validate() error {
+	if len(c.Consumer.Brokers) == 0 || c.Consumer.Topic == "" || c.Consumer.GroupID == "" {
+		return fmt.Errorf("consumer.brokers, consumer.topic and consumer.group_id are all required")
+	}
+	if c.Consumer.AutoOffsetReset != "earliest" && c.Consumer.AutoOffsetReset != "latest" {
+		return fmt.Errorf("consumer.auto_offset_reset must be 'earliest' or 'latest', got '%s'", c.Consumer.AutoOffsetReset)
+	}
+	if err := validateKafkaSelector(c.Content, false); err != nil {
+		return fmt.Errorf("content: %w", err)
+	}
+	if c.Format != KafkaFormatText && c.Format != KafkaFormatJSON {
+		return fmt.Errorf("format must be '%s' or '%s', got '%s'", KafkaFormatText, KafkaFormatJSON, c.Format)
+	}
+	if c.Org == "" {
+		if c.DefaultOrg == "" {
+			return fmt.Errorf("no org mapping: set org or default_org")
+		}
+	} else if err := validateKafkaSelector(c.Org, false); err != nil {
+		return fmt.Errorf("org: %w", err)
+	}
+	if len(c.OrgMap) > 0 && c.Org == "" {
+		return fmt.Errorf("org_map requires org")
+	}
+	for value, org := range c.OrgMap {
+		if org == "" {
+			return fmt.Errorf("org_map: empty org for '%s'", value)
+		}
+	}
+	if err := validateKafkaSelector(c.Timestamp, true); err != nil {
+		return fmt.Errorf("timestamp: %w", err)
+	}
+	switch c.TimestampFormat {
+	case KafkaTimestampRFC3339, KafkaTimestampUnix, KafkaTimestampUnixMs, KafkaTimestampUnixNs:
+	default:
+		return fmt.Errorf("timestamp_format must be one of %s, %s, %s or %s, got '%s'",
+			KafkaTimestampRFC3339, KafkaTimestampUnix, KafkaTimestampUnixMs, KafkaTimestampUnixNs, c.TimestampFormat)
+	}
+	return nil
+}
+
+// ParseKafkaSelector splits a selector such as header:x-org-id or value:meta.org into its
+// source and argument
+func ParseKafkaSelector(selector string) (source, arg string) {
+	source, arg, _ = strings.Cut(selector, ":")
+	return source, arg
+}
+
+// validateKafkaSelector checks a selector; the record timestamp and none are only valid for timestamps
+func validateKafkaSelector(selector string, timestamp bool) error {
+	source, arg := ParseKafkaSelector(selector)
+	switch source {
+	case KafkaSelectKey:
+		if arg != "" {
+			return fmt.Errorf("'%s' takes no argument", KafkaSelectKey)
+		}
+	case KafkaSelectValue:
+		if strings.HasPrefix(selector, KafkaSelectValue+":") && (arg == "" || strings.HasPrefix(arg, ".") || strings.HasSuffix(arg, ".") || strings.Contains(arg, "..")) {
+			return fmt.Errorf("invalid JSON path in '%s'", selector)
+		}
+	case KafkaSelectHeader:
+		if arg == "" {
+			return fmt.Errorf("'%s' requires a header name, as in header:<name>", KafkaSelectHeader)
+		}
+	case KafkaSelectRecord, KafkaSelectNone:
+		if !timestamp || arg != "" {
+			return fmt.Errorf("invalid selector '%s'", selector)
+		}
+	default:
+		return fmt.Errorf("invalid selector '%s': must be key, value, value:<path> or header:<name>", selector)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OTLPConfig defines the OpenTelemetry logs receiver. OTLP/gRPC is served on grpc_listen_addr and
+// OTLP/HTTP on http_listen_addr, behind the same authentication as the other submission APIs.
+type OTLPConfig struct {
+	Enabled       bool              `yaml:"enabled"`
+	HTTPPath      string            `yaml:"http_path"`      // OTLP/HTTP logs path; exporters append /v1/logs to their base endpoint
+	OrgAttribute  string            `yaml:"org_attribute"`  // Resource attribute naming the source org
+	AttributeOrgs map[string]string `yaml:"attribute_orgs"` // Optional org_attribute value -> org; when set, unlisted values are rejected
+	DefaultOrg    string            `yaml:"default_org"`    // Org of unauthenticated resources without org_attribute; empty rejects them
+	Category      string            `yaml:"category"`       // Optional schema category of the submitted records
+}
+
+// SetDefaults sets reasonable default values for OTLP receiver configuration
+func (c *OTLPConfig) SetDefaults() {
+	if !c.Enabled {
+		return
+	}
+	if c.HTTPPath == "" {
+		c.HTTPPath = "/otlp/v1/logs"
+		fmt.Printf("Warning: otlp.http_path not set, defaulting to %s\n", c.HTTPPath)
+	}
+	if c.OrgAttribute == "" {
+		c.OrgAttribute = "tlng.org_id"
+		fmt.Printf("Warning: otlp.org_attribute not set, defaulting to %s\n", c.OrgAttribute)
+	}
+}
+
+// Validate checks the OTLP receiver configuration
+func (c *OTLPConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if !strings.HasPrefix(c.HTTPPath, "/") {
+		return fmt.Errorf("http_path must start with '/', got '%s'", c.HTTPPath)
+	}
+	if c.HTTPPath == "/v1/logs" || c.HTTPPath == "/v1/logs/batch" {
+		return fmt.Errorf("http_path '%s' is taken by the submission API", c.HTTPPath)
+	}
+	for value, org := range c.AttributeOrgs {
+		if org == "" {
+			return fmt.Errorf("attribute_orgs: empty org for '%s'", value)
+		}
+	}
+	return nil
+}
@@ -13,6 +13,11 @@ type QueryConfig struct {
 	Server     QueryServerConfig     `yaml:"server"`
 	Database   DatabaseConfig        `yaml:"database"`
 	Blockchain QueryBlockchainConfig `yaml:"blockchain"`
+	Hashing    HashingConfig         `yaml:"hashing"` // Must match the ingestion service
+	Encryption QueryEncryptionConfig `yaml:"encryption"`
+	BlobStore  BlobStoreConfig       `yaml:"blob_store"` // Off-chain content written by the ingestion service
+	Admin      QueryAdminConfig      `yaml:"admin"`
+	Gateway    GatewayAuthConfig     `yaml:"gateway_auth"` // Verification of the identity headers set by the ingress
 	Logging    QueryLoggingConfig    `yaml:"logging"`
 }
 
@@ -30,6 +35,11 @@ type QueryBlockchainConfig struct {
 	ChainMakerConfig string `yaml:"chainmaker_config"`
 }
 
+// QueryAdminConfig defines who may use the admin API (erasures and retention policies)
+type QueryAdminConfig struct {
+	MemberIDs []string `yaml:"member_ids"` // mTLS member IDs allowed to call /v1/admin/; empty disables the admin API
+}
+
 // QueryLoggingConfig defines logging configuration for Query service
 type QueryLoggingConfig struct {
 	Level        string `yaml:"level"`
@@ -81,6 +91,18 @@ func (c *QueryConfig) SetDefaults() {
 	// Database defaults
 	c.Database.SetDefaults()
 
+	// Hashing defaults
+	c.Hashing.SetDefaults()
+
+	// Encryption defaults
+	c.Encryption.SetDefaults()
+
+	// Blob store defaults
+	c.BlobStore.SetDefaults()
+
+	// Gateway authentication defaults
+	c.Gateway.SetDefaults()
+
 	// Logging defaults
 	if c.Logging.Level == "" {
 		c.Logging.Level = "info"
@@ -116,6 +138,26 @@ func (c *QueryConfig) Validate() error {
 		return fmt.Errorf("database config error: %w", err)
 	}
 
+	// Validate hashing config
+	if err := c.Hashing.Validate(); err != nil {
+		return fmt.Errorf("hashing config error: %w", err)
+	}
+
+	// Validate encryption config
+	if err := c.Encryption.Validate(); err != nil {
+		return fmt.Errorf("encryption config error: %w", err)
+	}
+
+	// Validate blob store config
+	if err := c.BlobStore.Validate(); err != nil {
+		return fmt.Errorf("blob_store config error: %w", err)
+	}
+
+	// Validate gateway authentication config
+	if err := c.Gateway.Validate(); err != nil {
+		return fmt.Errorf("gateway_auth config error: %w", err)
+	}
+
 	// Validate blockchain config
 	if c.Blockchain.Enabled && c.Blockchain.ChainMakerConfig == "" {
 		return fmt.Errorf("blockchain is enabled but chainmaker_config is not set")
@@ -132,6 +174,11 @@ func (c *QueryConfig) LogConfiguration() {
 	fmt.Printf("  Write Timeout: %s\n", c.Server.WriteTimeout)
 	fmt.Printf("  Idle Timeout: %s\n", c.Server.IdleTimeout)
 	fmt.Printf("  Blockchain Enabled: %v\n", c.Blockchain.Enabled)
+	fmt.Printf("  Default Hash Algorithm: %s (%d org overrides)\n", c.Hashing.DefaultAlgorithm, len(c.Hashing.OrgAlgorithms))
+	fmt.Printf("  Encryption Enabled: %v (%d orgs with decrypt grants)\n", c.Encryption.Enabled, len(c.Encryption.DecryptGrants))
+	fmt.Printf("  Blob Store Enabled: %v\n", c.BlobStore.Enabled)
+	fmt.Printf("  Admin Members: %d\n", len(c.Admin.MemberIDs))
+	fmt.Printf("  Gateway Signatures Required: %v\n", c.Gateway.Enabled)
 	fmt.Printf("  Logging Level: %s\n", c.Logging.Level)
 	fmt.Printf("  Audit Enabled: %v\n", c.Logging.AuditEnabled)
 	c.Database.LogConfiguration()
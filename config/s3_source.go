@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Line formats of an S3 source
+const (
+	S3FormatText = "text" // Each line is submitted as the event's message
+	S3FormatJSON = "json" // Each line is a JSON document, submitted as the event's record
+)
+
+// S3SourceConfig defines an adapter polling a bucket of an S3-compatible object store for
+// newline-delimited log files, plain or gzip. Every object is tracked in tbl_s3_checkpoint,
+// so it is attested once even across restarts and ingestion replicas.
+type S3SourceConfig struct {
+	Name            string            `yaml:"name"`              // Scope of the checkpoints; renaming a source ingests its bucket again
+	Endpoint        string            `yaml:"endpoint"`          // host[:port], e.g. s3.amazonaws.com or minio:9000
+	Region          string            `yaml:"region"`            // Optional for MinIO
+	Bucket          string            `yaml:"bucket"`            // Must exist
+	Prefix          string            `yaml:"prefix"`            // Only objects under this key prefix are polled
+	AccessKeyID     string            `yaml:"access_key_id"`     // Empty reads AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY
+	SecretAccessKey string            `yaml:"secret_access_key"` // Prefer the environment over the config file
+	UseSSL          bool              `yaml:"use_ssl"`           // HTTPS to the endpoint
+	PollInterval    time.Duration     `yaml:"poll_interval"`     // Time between listings of the bucket
+	Format          string            `yaml:"format"`            // text or json
+	KeyOrgs         map[string]string `yaml:"key_orgs"`          // Key prefix below prefix -> org; the longest match wins
+	DefaultOrg      string            `yaml:"default_org"`       // Org of objects no key_orgs entry matches; empty skips them
+	Category        string            `yaml:"category"`          // Optional schema category of the submitted events
+	MaxLineSize     int               `yaml:"max_line_size"`     // Longer lines are rejected
+	BatchSize       int               `yaml:"batch_size"`        // Lines per submission and checkpoint, at most max_batch_entries
+	LeaseDuration   time.Duration     `yaml:"lease_duration"`    // How long an object stays claimed without progress before another replica takes it over
+}
+
+// SetDefaults sets reasonable default values for an S3 source; prefix names it in warnings
+func (c *S3SourceConfig) SetDefaults(prefix string) {
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Minute
+		fmt.Printf("Warning: %s.poll_interval not set or invalid, defaulting to %v\n", prefix, c.PollInterval)
+	}
+	if c.Format == "" {
+		c.Format = S3FormatText
+		fmt.Printf("Warning: %s.format not set, defaulting to %s\n", prefix, c.Format)
+	}
+	if c.MaxLineSize <= 0 {
+		c.MaxLineSize = 1024 * 1024
+		fmt.Printf("Warning: %s.max_line_size not set or invalid, defaulting to %d\n", prefix, c.MaxLineSize)
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+		fmt.Printf("Warning: %s.batch_size not set or invalid, defaulting to %d\n", prefix, c.BatchSize)
+	}
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = 5 * time.Minute
+		fmt.Printf("Warning: %s.lease_duration not set or invalid, defaulting to %v\n", prefix, c.LeaseDuration)
+	}
+}
+
+// Validate checks an S3 source configuration
+func (c *S3SourceConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.Endpoint == "" || c.Bucket == "" {
+		return fmt.Errorf("endpoint and bucket are required")
+	}
+	if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+		return fmt.Errorf("access_key_id and secret_access_key must be set together")
+	}
+	if c.Format != S3FormatText && c.Format != S3FormatJSON {
+		return fmt.Errorf("format must be '%s' or '%s', got '%s'", S3FormatText, S3FormatJSON, c.Format)
+	}
+	if len(c.KeyOrgs) == 0 && c.DefaultOrg == "" {
+		return fmt.Errorf("no org mapping: set key_orgs or default_org")
+	}
+	for keyPrefix, org := range c.KeyOrgs {
+		if org == "" {
+			return fmt.Errorf("key_orgs: empty org for '%s'", keyPrefix)
+		}
+	}
+	if c.LeaseDuration < 10*time.Second {
+		return fmt.Errorf("lease_duration must be at least 10s, got %v", c.LeaseDuration)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+// SyslogConfig defines the native syslog receiver (RFC 5424 and RFC 3164 over UDP, TCP and TLS).
+// Every message is submitted for the org its sender maps to; messages of unmapped senders are dropped.
+type SyslogConfig struct {
+	Enabled        bool              `yaml:"enabled"`
+	UDPAddr        string            `yaml:"udp_addr"`         // One message per datagram; empty disables the listener
+	TCPAddr        string            `yaml:"tcp_addr"`         // Octet-counted or newline-delimited frames (RFC 6587); empty disables the listener
+	TLSAddr        string            `yaml:"tls_addr"`         // Same framing over TLS (RFC 5425); empty disables the listener
+	TLS            SyslogTLSConfig   `yaml:"tls"`              // Certificates of tls_addr
+	MaxMessageSize int               `yaml:"max_message_size"` // Larger messages are dropped
+	IdleTimeout    time.Duration     `yaml:"idle_timeout"`     // TCP and TLS connections without a message for this long are closed
+	CertOrgs       map[string]string `yaml:"cert_orgs"`        // Client certificate Common Name (CN) -> org, tried first on tls_addr
+	HostnameOrgs   map[string]string `yaml:"hostname_orgs"`    // HOSTNAME (or sender IP if absent) -> org; exact names win, then the longest matching glob
+	DefaultOrg     string            `yaml:"default_org"`      // Org of senders no mapping matches; empty drops their messages
+	Category       string            `yaml:"category"`         // Optional schema category of the submitted events
+}
+
+// SyslogTLSConfig defines the certificates of the syslog TLS listener
+type SyslogTLSConfig struct {
+	CertFile     string `yaml:"cert_file"`
+	KeyFile      string `yaml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file"` // CA bundle client certificates must chain to; required for cert_orgs
+	ClientAuth   string `yaml:"client_auth"`    // request or require
+}
+
+// SetDefaults sets reasonable default values for syslog receiver configuration
+func (c *SyslogConfig) SetDefaults() {
+	if !c.Enabled {
+		return
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = 64 * 1024
+		fmt.Printf("Warning: syslog.max_message_size not set or invalid, defaulting to %d\n", c.MaxMessageSize)
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = 5 * time.Minute
+		fmt.Printf("Warning: syslog.idle_timeout not set, defaulting to %v\n", c.IdleTimeout)
+	}
+	if c.TLSAddr != "" && c.TLS.ClientCAFile != "" && c.TLS.ClientAuth == "" {
+		c.TLS.ClientAuth = GrpcClientAuthRequire
+		fmt.Printf("Warning: syslog.tls.client_auth not set, defaulting to %s\n", c.TLS.ClientAuth)
+	}
+}
+
+// Validate checks the syslog receiver configuration
+func (c *SyslogConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.UDPAddr == "" && c.TCPAddr == "" && c.TLSAddr == "" {
+		return fmt.Errorf("at least one of udp_addr, tcp_addr or tls_addr is required")
+	}
+	if c.TLSAddr != "" {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			return fmt.Errorf("tls.cert_file and tls.key_file are required for tls_addr")
+		}
+		if c.TLS.ClientCAFile != "" && c.TLS.ClientAuth != GrpcClientAuthRequest && c.TLS.ClientAuth != GrpcClientAuthRequire {
+			return fmt.Errorf("tls.client_auth must be '%s' or '%s', got '%s'", GrpcClientAuthRequest, GrpcClientAuthRequire, c.TLS.ClientAuth)
+		}
+	}
+	if len(c.CertOrgs) > 0 && (c.TLSAddr == "" || c.TLS.ClientCAFile == "") {
+		return fmt.Errorf("cert_orgs requires tls_addr and tls.client_ca_file")
+	}
+	if len(c.CertOrgs) == 0 && len(c.HostnameOrgs) == 0 && c.DefaultOrg == "" {
+		return fmt.Errorf("no org mapping: set cert_orgs, hostname_orgs or default_org")
+	}
+	for pattern, org := range c.HostnameOrgs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("hostname_orgs: invalid pattern '%s': %w", pattern, err)
+		}
+		if org == "" {
+			return fmt.Errorf("hostname_orgs: empty org for '%s'", pattern)
+		}
+	}
+	for cn, org := range c.CertOrgs {
+		if org == "" {
+			return fmt.Errorf("cert_orgs: empty org for '%s'", cn)
+		}
+	}
+	return nil
+}
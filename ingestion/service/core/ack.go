@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AckLevel selects how far a submission must progress before SubmitLog returns
+type AckLevel string
+
+const (
+	// AckAccepted returns as soon as the log has been handed to the batch processor
+	AckAccepted AckLevel = "accepted"
+	// AckDurable returns after the log has been written to the state DB and published to Kafka,
+	// or with the outbox enabled, written to the state DB together with its outbox record
+	AckDurable AckLevel = "durable"
+	// AckAttested returns after the engine marks the log COMPLETED, or when the deadline passes
+	AckAttested AckLevel = "attested"
+)
+
+// Status values reported in LogResult.Status for the accepted and durable levels.
+// The attested level reports the state DB status (store.Status) instead.
+const (
+	ResultStatusAccepted = "ACCEPTED"
+	ResultStatusDurable  = "DURABLE"
+)
+
+// ParseAckLevel converts a client-supplied ack_level; an empty value means accepted
+func ParseAckLevel(s string) (AckLevel, error) {
+	switch level := AckLevel(strings.ToLower(strings.TrimSpace(s))); level {
+	case "":
+		return AckAccepted, nil
+	case AckAccepted, AckDurable, AckAttested:
+		return level, nil
+	default:
+		return "", fmt.Errorf("%w: '%s' (expected accepted, durable or attested)", ErrInvalidAckLevel, s)
+	}
+}
@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"tlng/internal/messaging/producer"
@@ -11,6 +16,12 @@ import (
 	"tlng/storage/store"
 )
 
+// queuePollInterval is how often WaitAvailable checks a full queue for room
+const queuePollInterval = 10 * time.Millisecond
+
+// errNotPublished is returned by writeBatch when the batch is in the state DB but not in Kafka
+var errNotPublished = errors.New("kafka publish failed")
+
 // BatchProcessor handles batching of log requests for improved throughput
 type BatchProcessor struct {
 	batchSize    int
@@ -18,6 +29,8 @@ type BatchProcessor struct {
 	logger       *log.Logger
 	store        store.Store
 	producer     producer.Producer
+	useOutbox    bool   // Write Kafka messages to tbl_log_outbox instead of publishing them
+	spool        *Spool // Optional, keeps failed batches for replay
 
 	// Buffers
 	buffer      []*batchEntry
@@ -25,6 +38,11 @@ type BatchProcessor struct {
 	ticker      *time.Ticker
 	flushChan   chan []*batchEntry
 
+	// Queue bound: entries in the buffer, in flushChan or being written
+	maxBufferSize int
+	queued        atomic.Int64 // Only incremented under bufferMutex
+	rejected      atomic.Int64
+
 	// Context for graceful shutdown
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -32,13 +50,25 @@ type BatchProcessor struct {
 }
 
 type batchEntry struct {
-	input     *LogInput
-	requestID string
+	input  *LogInput
+	result *LogResult   // Request ID, receive time and clock skew flag assigned by the service
+	done   chan<- error // Optional, receives the outcome once the batch is written
+}
+
+// QueueStats is a snapshot of the batch processor queue for the metrics endpoint
+type QueueStats struct {
+	Depth    int64 `json:"depth"`
+	Capacity int   `json:"capacity"`
+	Rejected int64 `json:"rejected"`
 }
 
-// NewBatchProcessor creates a new batch processor
-func NewBatchProcessor(batchSize int, batchTimeout time.Duration, flushChannelBuffer int,
-	store store.Store, producer producer.Producer, logger *log.Logger) *BatchProcessor {
+// NewBatchProcessor creates a new batch processor.
+// At most maxBufferSize entries are queued; further submissions fail with ErrBufferFull.
+// With useOutbox, batches are written to the state DB together with their outbox records and
+// an OutboxRelay publishes them; otherwise they are published to Kafka directly.
+// If spool is non-nil, failed batches are spooled and replayed in the background.
+func NewBatchProcessor(batchSize int, batchTimeout time.Duration, flushChannelBuffer, maxBufferSize int,
+	store store.Store, producer producer.Producer, useOutbox bool, spool *Spool, logger *log.Logger) *BatchProcessor {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -48,10 +78,14 @@ func NewBatchProcessor(batchSize int, batchTimeout time.Duration, flushChannelBu
 		logger:       logger,
 		store:        store,
 		producer:     producer,
+		useOutbox:    useOutbox,
+		spool:        spool,
 		buffer:       make([]*batchEntry, 0, batchSize),
 		flushChan:    make(chan []*batchEntry, flushChannelBuffer), // Configurable buffer for flush requests
 		ctx:          ctx,
 		cancel:       cancel,
+
+		maxBufferSize: maxBufferSize,
 	}
 
 	// Start background goroutines
@@ -59,30 +93,86 @@ func NewBatchProcessor(batchSize int, batchTimeout time.Duration, flushChannelBu
 	go bp.batchTimer()
 	go bp.batchProcessor()
 
+	if spool != nil {
+		bp.wg.Add(1)
+		go bp.spoolReplayer()
+	}
+
 	return bp
 }
 
-// SubmitLog adds a log to the batch with pre-generated request ID
-func (bp *BatchProcessor) SubmitLog(input *LogInput, requestID string) {
-	entry := &batchEntry{
-		input:     input,
-		requestID: requestID,
+// SubmitLog adds a log to the batch without blocking. The request ID, receive time and
+// clock skew flag are taken from result, which must not be modified until done fires.
+// It returns ErrBufferFull if maxBufferSize entries are already queued.
+// If done is non-nil it receives nil once the entry is in the DB and Kafka, or the write error.
+func (bp *BatchProcessor) SubmitLog(input *LogInput, result *LogResult, done chan<- error) error {
+	if !bp.enqueue(&batchEntry{input: input, result: result, done: done}) {
+		bp.rejected.Add(1)
+		return fmt.Errorf("%w: %d logs queued", ErrBufferFull, bp.maxBufferSize)
 	}
+	return nil
+}
 
-	// Add to buffer
+// SubmitLogWait is SubmitLog, but waits for room in the queue instead of failing with
+// ErrBufferFull. It returns the context error if ctx ends first.
+func (bp *BatchProcessor) SubmitLogWait(ctx context.Context, input *LogInput, result *LogResult, done chan<- error) error {
+	entry := &batchEntry{input: input, result: result, done: done}
+	for !bp.enqueue(entry) {
+		if err := bp.WaitAvailable(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue adds an entry to the buffer unless the queue is at capacity
+func (bp *BatchProcessor) enqueue(entry *batchEntry) bool {
 	bp.bufferMutex.Lock()
+	if bp.queued.Load() >= int64(bp.maxBufferSize) {
+		bp.bufferMutex.Unlock()
+		return false
+	}
+	bp.queued.Add(1)
 	bp.buffer = append(bp.buffer, entry)
 	shouldFlush := len(bp.buffer) >= bp.batchSize
 	bp.bufferMutex.Unlock()
 
 	// Trigger flush if buffer is full
 	if shouldFlush {
+		bp.flushIfNeeded()
+	}
+	return true
+}
+
+// Available returns how many more entries can be queued right now
+func (bp *BatchProcessor) Available() int {
+	return bp.maxBufferSize - int(bp.queued.Load())
+}
+
+// WaitAvailable blocks until the queue has room or ctx ends. The queue is polled, since it
+// drains a whole batch at a time.
+func (bp *BatchProcessor) WaitAvailable(ctx context.Context) error {
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+	for bp.Available() <= 0 {
 		select {
-		case bp.flushChan <- bp.getAndResetBuffer():
-		default:
-			bp.logger.Printf("Flush channel full, will flush on next timer")
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-bp.ctx.Done():
+			return fmt.Errorf("%w: batch processor closed", ErrBufferFull)
 		}
 	}
+	return nil
+}
+
+// Stats returns a snapshot of the queue counters
+func (bp *BatchProcessor) Stats() QueueStats {
+	return QueueStats{
+		Depth:    bp.queued.Load(),
+		Capacity: bp.maxBufferSize,
+		Rejected: bp.rejected.Load(),
+	}
 }
 
 // batchTimer handles periodic flushing
@@ -111,9 +201,20 @@ func (bp *BatchProcessor) batchProcessor() {
 		case batch := <-bp.flushChan:
 			if len(batch) > 0 {
 				bp.processBatch(batch)
+				bp.queued.Add(-int64(len(batch)))
 			}
 		case <-bp.ctx.Done():
-			// Process remaining buffer before shutdown
+			// Process queued flushes and the remaining buffer before shutdown
+			for pending := true; pending; {
+				select {
+				case batch := <-bp.flushChan:
+					bp.processBatch(batch)
+					bp.queued.Add(-int64(len(batch)))
+				default:
+					pending = false
+				}
+			}
+
 			bp.bufferMutex.Lock()
 			remaining := bp.buffer
 			bp.buffer = nil
@@ -121,6 +222,7 @@ func (bp *BatchProcessor) batchProcessor() {
 
 			if len(remaining) > 0 {
 				bp.processBatch(remaining)
+				bp.queued.Add(-int64(len(remaining)))
 			}
 			return
 		}
@@ -150,52 +252,104 @@ func (bp *BatchProcessor) flushIfNeeded() {
 	}
 }
 
-// getAndResetBuffer safely gets the current buffer and resets it
-func (bp *BatchProcessor) getAndResetBuffer() []*batchEntry {
-	bp.bufferMutex.Lock()
-	defer bp.bufferMutex.Unlock()
-
-	batch := make([]*batchEntry, len(bp.buffer))
-	copy(batch, bp.buffer)
-	bp.buffer = bp.buffer[:0]
-	return batch
-}
-
 // processBatch handles the actual batch processing
 func (bp *BatchProcessor) processBatch(batch []*batchEntry) {
 	if len(batch) == 0 {
 		return
 	}
 
-	start := time.Now()
 	// bp.logger.Printf("Processing batch of %d logs", len(batch))
 
 	// Prepare batch data
-	logStatuses := make([]*store.LogStatus, len(batch))
 	kafkaMessages := make([]*models.LogMessage, len(batch))
 
 	for i := range batch {
+		kafkaMessages[i] = &models.LogMessage{
+			RequestID:              batch[i].result.RequestID,
+			LogContent:             batch[i].input.LogContent,
+			LogHash:                batch[i].input.ClientLogHash,
+			SourceOrgID:            batch[i].input.ClientSourceOrgID,
+			ReceivedTimestamp:      batch[i].result.ServerReceivedTimestamp.Format(time.RFC3339Nano),
+			ClientTimestampFlagged: batch[i].result.ClientTimestampFlagged,
+			AttestationMode:        batch[i].result.AttestationMode,
+			ContentFormat:          batch[i].result.ContentFormat,
+			Category:               batch[i].result.Category,
+			SchemaVersion:          batch[i].result.SchemaVersion,
+			ContentLocator:         batch[i].result.ContentLocator,
+		}
+		if batch[i].result.SignatureKeyID != "" {
+			kafkaMessages[i].Signature = base64.RawURLEncoding.EncodeToString(batch[i].input.Signature)
+			kafkaMessages[i].SignatureKeyID = batch[i].result.SignatureKeyID
+			kafkaMessages[i].SignatureAlgorithm = batch[i].result.SignatureAlgorithm
+			kafkaMessages[i].SignatureKeyFingerprint = batch[i].result.SignatureKeyFingerprint
+		}
+		// Hash-only content never leaves the ingestion service; offloaded content is in the blob store
+		if batch[i].result.AttestationMode == models.AttestationModeHashOnly || kafkaMessages[i].ContentLocator != "" {
+			kafkaMessages[i].LogContent = ""
+		}
+		if batch[i].input.ClientTimestamp != nil {
+			// UTC keeps '+' offsets out of the on-chain key=value record
+			kafkaMessages[i].ClientTimestamp = batch[i].input.ClientTimestamp.UTC().Format(time.RFC3339Nano)
+		}
+	}
 
-		logHash := batch[i].input.ClientLogHash
-		sourceOrgID := batch[i].input.ClientSourceOrgID
+	if err := bp.writeBatch(kafkaMessages); err != nil {
+		// Entries nobody waits for were already acknowledged as accepted, keep them for replay.
+		// Waiters are told about the failure and can retry themselves.
+		spoolWaiters := errors.Is(err, errNotPublished) && !bp.failWaiters(batch, err)
+		bp.spoolBatch(batch, kafkaMessages, spoolWaiters)
+		bp.notifyBatch(batch, err)
+		return
+	}
 
-		logStatuses[i] = &store.LogStatus{
-			RequestID:         batch[i].requestID,
-			LogHash:           logHash,
-			SourceOrgID:       sourceOrgID,
-			ReceivedTimestamp: time.Now(),
-			Status:            store.StatusReceived,
+	bp.notifyBatch(batch, nil)
+}
+
+// writeBatch inserts the batch into the state DB and publishes it to Kafka,
+// or with the outbox enabled writes it to the state DB only (see writeBatchWithOutbox).
+// The insert ignores request IDs that already exist, so a replayed batch is written safely.
+func (bp *BatchProcessor) writeBatch(kafkaMessages []*models.LogMessage) error {
+	start := time.Now()
+
+	logStatuses := make([]*store.LogStatus, len(kafkaMessages))
+	for i, msg := range kafkaMessages {
+		receivedTimestamp, err := time.Parse(time.RFC3339Nano, msg.ReceivedTimestamp)
+		if err != nil {
+			receivedTimestamp = time.Now()
 		}
 
-		kafkaMessages[i] = &models.LogMessage{
-			RequestID:         batch[i].requestID,
-			LogContent:        batch[i].input.LogContent,
-			LogHash:           logHash,
-			SourceOrgID:       sourceOrgID,
-			ReceivedTimestamp: time.Now().Format(time.RFC3339Nano),
+		logStatuses[i] = &store.LogStatus{
+			RequestID:              msg.RequestID,
+			LogHash:                msg.LogHash,
+			SourceOrgID:            msg.SourceOrgID,
+			ReceivedTimestamp:      receivedTimestamp,
+			ClientTimestampFlagged: msg.ClientTimestampFlagged,
+			AttestationMode:        msg.AttestationMode,
+			ContentFormat:          msg.ContentFormat,
+			Status:                 store.StatusReceived,
+		}
+		if logStatuses[i].AttestationMode == "" {
+			logStatuses[i].AttestationMode = models.AttestationModeFull // Spooled before modes existed
+		}
+		if logStatuses[i].ContentFormat == "" {
+			logStatuses[i].ContentFormat = models.ContentFormatText // Spooled before structured logs existed
+		}
+		if msg.Category != "" {
+			category, schemaVersion := msg.Category, msg.SchemaVersion
+			logStatuses[i].Category = &category
+			logStatuses[i].SchemaVersion = &schemaVersion
+		}
+		if msg.ClientTimestamp != "" {
+			if clientTimestamp, err := time.Parse(time.RFC3339Nano, msg.ClientTimestamp); err == nil {
+				logStatuses[i].ClientTimestamp = &clientTimestamp
+			}
 		}
 	}
 
+	if bp.useOutbox {
+		return bp.writeBatchWithOutbox(logStatuses, kafkaMessages)
+	}
+
 	// Batch database insert
 	dbStart := time.Now()
 	dbErr := bp.store.InsertLogStatusBatch(context.Background(), logStatuses)
@@ -203,11 +357,7 @@ func (bp *BatchProcessor) processBatch(batch []*batchEntry) {
 
 	if dbErr != nil {
 		bp.logger.Printf("Batch database insert failed: %v", dbErr)
-		// Notify all entries of failure
-		for range batch {
-			// In production, you might want to retry or use a dead letter queue
-		}
-		return
+		return fmt.Errorf("database insert failed: %w", dbErr)
 	}
 
 	// Batch Kafka publish
@@ -217,13 +367,150 @@ func (bp *BatchProcessor) processBatch(batch []*batchEntry) {
 
 	if kafkaErr != nil {
 		bp.logger.Printf("Batch Kafka publish failed: %v", kafkaErr)
-		// Handle failure - might need to retry or use dead letter queue
-		return
+		return fmt.Errorf("%w: %w", errNotPublished, kafkaErr)
 	}
 
 	totalDuration := time.Since(start)
 	bp.logger.Printf("Batch processed: %d logs, DB: %v, Kafka: %v, Total: %v",
-		len(batch), dbDuration, kafkaDuration, totalDuration)
+		len(kafkaMessages), dbDuration, kafkaDuration, totalDuration)
+	return nil
+}
+
+// writeBatchWithOutbox inserts the batch and its outbox records in one transaction.
+// Kafka is not contacted; the OutboxRelay publishes the records.
+func (bp *BatchProcessor) writeBatchWithOutbox(logStatuses []*store.LogStatus, kafkaMessages []*models.LogMessage) error {
+	start := time.Now()
+
+	outbox := make([]*store.OutboxRecord, len(kafkaMessages))
+	for i, msg := range kafkaMessages {
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to serialize log message (RequestID: %s): %w", msg.RequestID, err)
+		}
+		outbox[i] = &store.OutboxRecord{
+			RequestID:   msg.RequestID,
+			SourceOrgID: msg.SourceOrgID,
+			Payload:     payload,
+		}
+	}
+
+	if err := bp.store.InsertLogStatusBatchWithOutbox(context.Background(), logStatuses, outbox); err != nil {
+		bp.logger.Printf("Batch database insert with outbox failed: %v", err)
+		return fmt.Errorf("database insert failed: %w", err)
+	}
+
+	bp.logger.Printf("Batch processed: %d logs, DB with outbox: %v", len(kafkaMessages), time.Since(start))
+	return nil
+}
+
+// failWaiters marks the rows of waiting entries failed after their batch was inserted but not
+// published, so they are not left RECEIVED while the callers retry under new request IDs.
+// It reports whether the rows were marked.
+func (bp *BatchProcessor) failWaiters(batch []*batchEntry, err error) bool {
+	var failures []store.FailureRecord
+	for _, entry := range batch {
+		if entry.done != nil {
+			failures = append(failures, store.FailureRecord{RequestID: entry.result.RequestID, ErrorMessage: err.Error()})
+		}
+	}
+	if len(failures) == 0 {
+		return true
+	}
+	if markErr := bp.store.MarkBatchAsFailed(context.Background(), failures); markErr != nil {
+		bp.logger.Printf("Failed to mark %d unpublished logs failed, spooling them: %v", len(failures), markErr)
+		return false
+	}
+	return true
+}
+
+// spoolBatch appends the entries of a failed batch that have no waiter to the spool, and
+// with spoolWaiters the waiting ones too, so rows that failWaiters could not mark get published
+func (bp *BatchProcessor) spoolBatch(batch []*batchEntry, kafkaMessages []*models.LogMessage, spoolWaiters bool) {
+	pending := make([]*models.LogMessage, 0, len(batch))
+	for i, entry := range batch {
+		if entry.done == nil || spoolWaiters {
+			pending = append(pending, kafkaMessages[i])
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	if bp.spool == nil {
+		bp.logger.Printf("Spool disabled, %d accepted logs lost", len(pending))
+		return
+	}
+	if err := bp.spool.Append(pending); err != nil {
+		bp.logger.Printf("Spool: Failed to spool %d accepted logs, they are lost: %v", len(pending), err)
+		return
+	}
+	bp.logger.Printf("Spool: Spooled %d logs for replay", len(pending))
+}
+
+// spoolReplayer replays spooled batches, starting right away so batches left by a
+// previous run are picked up on startup. After a failure the wait doubles up to
+// max_replay_backoff and resets once a replay succeeds.
+func (bp *BatchProcessor) spoolReplayer() {
+	defer bp.wg.Done()
+
+	wait := bp.spool.replayInterval
+	for {
+		if err := bp.replaySpool(); err != nil {
+			wait *= 2
+			if wait > bp.spool.maxReplayBackoff {
+				wait = bp.spool.maxReplayBackoff
+			}
+			bp.logger.Printf("Spool: Replay failed, retrying in %v: %v", wait, err)
+		} else {
+			wait = bp.spool.replayInterval
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-bp.ctx.Done():
+			return
+		}
+	}
+}
+
+// replaySpool writes spooled segments oldest first, stopping at the first failure
+func (bp *BatchProcessor) replaySpool() error {
+	names, err := bp.spool.segments()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if bp.ctx.Err() != nil {
+			return nil
+		}
+
+		msgs, err := bp.spool.read(name)
+		if err != nil {
+			return err
+		}
+		if msgs == nil {
+			continue // Corrupt segment moved aside
+		}
+
+		if err := bp.writeBatch(msgs); err != nil {
+			return fmt.Errorf("segment %s: %w", name, err)
+		}
+		if err := bp.spool.remove(name, len(msgs)); err != nil {
+			return err
+		}
+		bp.logger.Printf("Spool: Replayed %d logs from %s", len(msgs), name)
+	}
+	return nil
+}
+
+// notifyBatch reports the batch outcome to entries that are waiting for durability
+func (bp *BatchProcessor) notifyBatch(batch []*batchEntry, err error) {
+	for _, entry := range batch {
+		if entry.done != nil {
+			entry.done <- err // done is buffered by the caller, never blocks
+		}
+	}
 }
 
 // Close gracefully shuts down the batch processor
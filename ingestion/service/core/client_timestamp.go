@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"tlng/config"
+)
+
+// checkClientTimestamp compares the client-reported event time with the receive time.
+// It returns true if the timestamp is outside the allowed skew and the configured action is to flag it,
+// or ErrClockSkew if the action is to reject it. A missing client timestamp is never flagged.
+func (s *Service) checkClientTimestamp(clientTimestamp *time.Time, receivedTimestamp time.Time) (bool, error) {
+	if clientTimestamp == nil {
+		return false, nil
+	}
+
+	skew := receivedTimestamp.Sub(*clientTimestamp)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew <= s.clientTimestampCfg.MaxSkew {
+		return false, nil
+	}
+
+	if s.clientTimestampCfg.Action == config.ClockSkewActionReject {
+		return false, fmt.Errorf("%w: %s differs from receive time %s by %v (max %v)", ErrClockSkew,
+			clientTimestamp.Format(time.RFC3339Nano), receivedTimestamp.Format(time.RFC3339Nano), skew.Round(time.Millisecond), s.clientTimestampCfg.MaxSkew)
+	}
+	return true, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+
+	"tlng/internal/hashing"
+	"tlng/internal/schema"
+	"tlng/internal/signing"
+)
+
+// Standard errors for ingestion service
+var (
+	ErrInvalidAckLevel = errors.New("invalid ack_level")
+	ErrNotDurable      = errors.New("log could not be persisted")
+	ErrInvalidBatch    = errors.New("invalid batch")
+	ErrSpoolFull       = errors.New("spool is full")
+	ErrBufferFull      = errors.New("ingestion queue is full, retry later")
+	ErrEmptyLogContent = errors.New("log_content cannot be empty")
+	ErrHashMismatch    = errors.New("client_log_hash does not match the server calculated hash")
+
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	ErrIdempotencyConflict   = errors.New("idempotency key was already used with different content")
+
+	ErrClockSkew = errors.New("client_timestamp is outside the allowed clock skew")
+
+	ErrInvalidAttestationMode = errors.New("invalid attestation_mode")
+
+	ErrInvalidLogJSON  = errors.New("invalid log_json")
+	ErrUnknownCategory = errors.New("no schema registered for category")
+
+	ErrBlobStore = errors.New("log content could not be stored off chain")
+
+	ErrSignatureRequired = errors.New("a client signature is required")
+	ErrUnknownSigningKey = errors.New("unknown or revoked signature_key_id")
+)
+
+// rejectedErrors are the errors of logs that are invalid as submitted
+var rejectedErrors = []error{
+	ErrEmptyLogContent, ErrHashMismatch, ErrInvalidAckLevel, ErrInvalidIdempotencyKey, ErrIdempotencyConflict,
+	ErrClockSkew, ErrInvalidAttestationMode, ErrInvalidLogJSON, ErrUnknownCategory, ErrSignatureRequired, ErrUnknownSigningKey,
+	schema.ErrInvalidCategory, schema.ErrValidation,
+	hashing.ErrUnsupportedAlgorithm, hashing.ErrInvalidHash,
+	signing.ErrInvalidSignature, signing.ErrInvalidKeyID,
+}
+
+// IsRejected reports whether a submission failed because the log itself is invalid, so that
+// resubmitting it cannot succeed. Other errors, such as ErrBufferFull or ErrNotDurable, are transient.
+func IsRejected(err error) bool {
+	for _, target := range rejectedErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"tlng/storage/store"
+)
+
+// maxIdempotencyKeyLength bounds client-chosen keys
+const maxIdempotencyKeyLength = 255
+
+// reserveIdempotencyKey records that input.IdempotencyKey belongs to this submission.
+// It returns the earlier submission if the key is already in use within its TTL.
+func (s *Service) reserveIdempotencyKey(ctx context.Context, input *LogInput, requestID string, receivedTimestamp time.Time) (*store.IdempotencyRecord, error) {
+	if len(input.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", ErrInvalidIdempotencyKey, maxIdempotencyKeyLength)
+	}
+
+	existing, err := s.store.ReserveIdempotencyKey(ctx, &store.IdempotencyRecord{
+		SourceOrgID:       input.ClientSourceOrgID,
+		IdempotencyKey:    input.IdempotencyKey,
+		RequestID:         requestID,
+		LogHash:           input.ClientLogHash,
+		ReceivedTimestamp: receivedTimestamp,
+		ExpiresAt:         receivedTimestamp.Add(s.idempotencyCfg.TTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// releaseIdempotencyKey frees the key of a submission that was not queued or not persisted,
+// so the client can retry with the same key
+func (s *Service) releaseIdempotencyKey(input *LogInput, requestID string) {
+	if input.IdempotencyKey == "" {
+		return
+	}
+	if err := s.store.ReleaseIdempotencyKey(context.Background(), input.ClientSourceOrgID, input.IdempotencyKey, requestID); err != nil {
+		s.logger.Printf("Service: Failed to release idempotency key for request_id %s: %v", requestID, err)
+	}
+}
+
+// replayResult answers a retry from the record of the original submission.
+// The content must match; the status reflects where the original submission is now.
+func (s *Service) replayResult(ctx context.Context, existing *store.IdempotencyRecord, serverLogHash string, ackLevel AckLevel, ackTimeout time.Duration) (*LogResult, error) {
+	if existing.LogHash != serverLogHash {
+		return nil, fmt.Errorf("%w: key '%s' belongs to request_id %s", ErrIdempotencyConflict, existing.IdempotencyKey, existing.RequestID)
+	}
+
+	result := &LogResult{
+		RequestID:               existing.RequestID,
+		ServerLogHash:           existing.LogHash,
+		ServerReceivedTimestamp: existing.ReceivedTimestamp,
+		Status:                  ResultStatusAccepted,
+		IdempotentReplay:        true,
+	}
+
+	switch ackLevel {
+	case AckDurable:
+		// Durable once the original row is in the state DB. Until then the original is still in
+		// flight, and the retryable error keeps the caller from settling for less than durable.
+		status, err := s.store.GetLogStatusByRequestID(ctx, existing.RequestID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: request_id %s is not in the state DB yet: %v", ErrNotDurable, existing.RequestID, err)
+		}
+		result.Status = ResultStatusDurable
+		result.ClientTimestampFlagged = status.ClientTimestampFlagged
+		result.AttestationMode = status.AttestationMode
+		result.ContentFormat = status.ContentFormat
+		applySchema(result, status)
+	case AckAttested:
+		applyAttestation(result, s.waitForAttestation(ctx, existing.RequestID, s.attestTimeout(ackTimeout)))
+	}
+
+	return result, nil
+}
+
+// idempotencyJanitor purges expired keys until the service is closed
+func (s *Service) idempotencyJanitor() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.idempotencyCfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			purged, err := s.store.PurgeExpiredIdempotencyKeys(s.ctx)
+			if err != nil {
+				s.logger.Printf("Service: Failed to purge expired idempotency keys: %v", err)
+			} else if purged > 0 {
+				s.logger.Printf("Service: Purged %d expired idempotency keys", purged)
+			}
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
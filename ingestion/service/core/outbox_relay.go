@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"tlng/config"
+	"tlng/internal/messaging/producer"
+	"tlng/internal/models"
+	"tlng/storage/store"
+)
+
+const (
+	outboxMaxBackoff    = 30 * time.Second // Upper bound for the wait after a failed relay round
+	outboxPurgeInterval = time.Hour        // How often sent records past the retention are deleted
+)
+
+// OutboxRelay publishes tbl_log_outbox records to Kafka and marks them sent.
+// Delivery is at-least-once: a record is marked sent only after the producer returned,
+// so a crash in between publishes it again. The producer must be synchronous and keyed
+// by source_org_id for per-org ordering to hold. Ordering is best-effort across concurrent
+// inserts, see store.RelayOutboxBatch.
+type OutboxRelay struct {
+	store         store.Store
+	producer      producer.Producer
+	logger        *log.Logger
+	pollInterval  time.Duration
+	batchSize     int
+	sentRetention time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewOutboxRelay creates an outbox relay; call Start to begin relaying
+func NewOutboxRelay(s store.Store, p producer.Producer, cfg config.OutboxConfig, l *log.Logger) *OutboxRelay {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OutboxRelay{
+		store:         s,
+		producer:      p,
+		logger:        l,
+		pollInterval:  cfg.PollInterval,
+		batchSize:     cfg.BatchSize,
+		sentRetention: cfg.SentRetention,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start runs the relay loop in the background
+func (r *OutboxRelay) Start() {
+	r.wg.Add(1)
+	go r.run()
+	r.logger.Printf("Outbox Relay: Started (batch size %d, poll interval %v)", r.batchSize, r.pollInterval)
+}
+
+// run relays batches back to back while the outbox is full, and polls once it is drained
+func (r *OutboxRelay) run() {
+	defer r.wg.Done()
+
+	wait := r.pollInterval
+	lastPurge := time.Time{}
+	for {
+		relayed, err := r.store.RelayOutboxBatch(r.ctx, r.batchSize, r.publish)
+		switch {
+		case err != nil:
+			if wait < r.pollInterval {
+				wait = r.pollInterval
+			} else {
+				wait *= 2
+			}
+			if wait > outboxMaxBackoff {
+				wait = outboxMaxBackoff
+			}
+			if r.ctx.Err() == nil {
+				r.logger.Printf("Outbox Relay: Relay failed, retrying in %v: %v", wait, err)
+			}
+		case relayed == r.batchSize:
+			wait = 0 // More records are likely pending
+		default:
+			wait = r.pollInterval
+		}
+
+		if time.Since(lastPurge) >= outboxPurgeInterval {
+			r.purge()
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// publish sends one batch of outbox records to Kafka in outbox order. Records whose payload
+// does not decode are returned as failures, so they do not block the records behind them.
+func (r *OutboxRelay) publish(records []*store.OutboxRecord) ([]store.OutboxFailure, error) {
+	var failures []store.OutboxFailure
+	msgs := make([]*models.LogMessage, 0, len(records))
+	for _, record := range records {
+		var msg models.LogMessage
+		if err := json.Unmarshal(record.Payload, &msg); err != nil {
+			r.logger.Printf("Outbox Relay: Setting aside undecodable record %d (request_id %s): %v", record.ID, record.RequestID, err)
+			failures = append(failures, store.OutboxFailure{
+				ID:           record.ID,
+				ErrorMessage: fmt.Sprintf("outbox message could not be decoded: %v", err),
+			})
+			continue
+		}
+		msgs = append(msgs, &msg)
+	}
+
+	if len(msgs) > 0 {
+		if err := r.producer.PublishBatch(r.ctx, msgs); err != nil {
+			return nil, fmt.Errorf("failed to publish outbox batch: %w", err)
+		}
+	}
+	return failures, nil
+}
+
+// purge deletes sent records older than the retention
+func (r *OutboxRelay) purge() {
+	purged, err := r.store.PurgeSentOutbox(r.ctx, time.Now().Add(-r.sentRetention))
+	if err != nil {
+		r.logger.Printf("Outbox Relay: Purge failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		r.logger.Printf("Outbox Relay: Purged %d sent records", purged)
+	}
+}
+
+// Close stops the relay; unsent records stay in the outbox for the next start
+func (r *OutboxRelay) Close() {
+	r.cancel()
+	r.wg.Wait()
+	r.logger.Println("Outbox Relay: Stopped")
+}
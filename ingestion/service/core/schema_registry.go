@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tlng/internal/models"
+	"tlng/internal/schema"
+	"tlng/storage/store"
+)
+
+// schemaKey identifies a category schema of an org
+type schemaKey struct {
+	orgID    string
+	category string
+}
+
+// schemaRegistry caches the latest schema of each org category for ttl, so validation does not
+// hit the state DB per log. A new version or a deleted category takes effect once the entry expires.
+type schemaRegistry struct {
+	store store.Store
+	cache *ttlCache[schemaKey, *schema.Schema]
+}
+
+func newSchemaRegistry(s store.Store, ttl time.Duration) *schemaRegistry {
+	r := &schemaRegistry{store: s}
+	r.cache = newTTLCache(ttl, registryCacheSize, r.load)
+	return r
+}
+
+// latest returns the latest schema of an org category, or nil if none is registered
+func (r *schemaRegistry) latest(ctx context.Context, orgID, category string) (*schema.Schema, error) {
+	return r.cache.get(ctx, schemaKey{orgID: orgID, category: category})
+}
+
+// load reads and compiles the latest schema of an org category from the state DB
+func (r *schemaRegistry) load(ctx context.Context, key schemaKey) (*schema.Schema, error) {
+	stored, err := r.store.GetLogSchema(ctx, key.orgID, key.category, 0)
+	if errors.Is(err, store.ErrLogSchemaNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema for category '%s': %w", key.category, err)
+	}
+
+	// Registered schemas were compiled by the admin API, so this only fails on a corrupted row
+	compiled, err := schema.Compile(key.orgID, key.category, stored.Version, []byte(stored.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema version %d of category '%s': %w", stored.Version, key.category, err)
+	}
+	return compiled, nil
+}
+
+// validateCategory checks a structured log against the latest schema of its declared category
+// and returns the schema version it matched; logs without a category are not validated
+func (s *Service) validateCategory(ctx context.Context, input *LogInput, contentFormat string) (int, error) {
+	if input.Category == "" {
+		return 0, nil
+	}
+	if err := schema.ValidateCategory(input.Category); err != nil {
+		return 0, err
+	}
+	if contentFormat != models.ContentFormatJSON {
+		return 0, fmt.Errorf("%w: category requires log_json", schema.ErrInvalidCategory)
+	}
+
+	sch, err := s.schemas.latest(ctx, input.ClientSourceOrgID, input.Category)
+	if err != nil {
+		return 0, err
+	}
+	if sch == nil {
+		return 0, fmt.Errorf("%w: '%s'", ErrUnknownCategory, input.Category)
+	}
+	if err := sch.Validate([]byte(input.LogContent)); err != nil {
+		return 0, err
+	}
+	return sch.Version, nil
+}
+
+// applySchema copies the category and schema version recorded in the state DB into the result
+func applySchema(result *LogResult, status *store.LogStatus) {
+	if status.Category != nil {
+		result.Category = *status.Category
+	}
+	if status.SchemaVersion != nil {
+		result.SchemaVersion = *status.SchemaVersion
+	}
+}
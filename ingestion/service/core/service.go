@@ -2,12 +2,20 @@ package service
 
 import (
 	"context"
-	"crypto/sha256"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
+	"tlng/config"
+	"tlng/internal/blobstore"
+	"tlng/internal/encryption"
+	"tlng/internal/hashing"
+	"tlng/internal/jcs"
 	"tlng/internal/messaging/producer"
+	"tlng/internal/models"
 	"tlng/storage/store"
 
 	"github.com/google/uuid"
@@ -16,9 +24,18 @@ import (
 // LogInput defines the core information required for log submission
 type LogInput struct {
 	LogContent        string
-	ClientLogHash     string     // Optional
-	ClientSourceOrgID string     // Optional
-	ClientTimestamp   *time.Time // Optional
+	LogJSON           []byte        // Structured event instead of LogContent; canonicalized (RFC 8785) into LogContent
+	ClientLogHash     string        // Optional, for LogJSON the hash of the canonical form
+	ClientSourceOrgID string        // Optional
+	ClientTimestamp   *time.Time    // Optional
+	AckLevel          AckLevel      // Optional, defaults to AckAccepted
+	AckTimeout        time.Duration // Optional, deadline for AckAttested
+	IdempotencyKey    string        // Optional, makes retries return the original submission
+	HashAlgorithm     string        // Optional, overrides the org's configured digest algorithm
+	AttestationMode   string        // Optional, "full" or "hash_only"; overrides the org's configured mode
+	Category          string        // Optional, LogJSON is validated against the org's latest schema for it
+	Signature         []byte        // Optional, client signature over the server log hash, see internal/signing
+	SignatureKeyID    string        // Required with Signature, a key registered for ClientSourceOrgID
 }
 
 // LogResult defines the return information after successful submission
@@ -26,60 +43,251 @@ type LogResult struct {
 	RequestID               string
 	ServerLogHash           string
 	ServerReceivedTimestamp time.Time
+	Status                  string // ACCEPTED, DURABLE, or the state DB status for AckAttested
+	IdempotentReplay        bool   // Result of an earlier submission with the same idempotency key
+	ClientTimestampFlagged  bool   // ClientTimestamp was outside the allowed clock skew
+	AttestationMode         string // What the engine puts on chain, see models.AttestationModeFull
+	ContentFormat           string // models.ContentFormatJSON for structured logs, models.ContentFormatText otherwise
+	Category                string // Declared schema category, empty if none
+	SchemaVersion           int    // Schema version the log was validated against, 0 without a category
+	ContentLocator          string // Where the content is stored off chain, empty if it goes on chain
+
+	// Key the client signed the log hash with; empty for unsigned logs
+	SignatureKeyID          string
+	SignatureAlgorithm      string
+	SignatureKeyFingerprint string
+
+	// Set only for AckAttested once the engine has finished processing
+	TxHash       string
+	BlockHeight  int64
+	ErrorMessage string
+}
+
+// BatchItemResult is the outcome of one entry of SubmitLogs; exactly one of Result and Err is set
+type BatchItemResult struct {
+	Result *LogResult
+	Err    error
 }
 
 // Service encapsulates the core business logic of the API gateway
 type Service struct {
-	store          store.Store
-	producer       producer.Producer
-	logger         *log.Logger
-	batchProcessor *BatchProcessor
+	store              store.Store
+	producer           producer.Producer
+	logger             *log.Logger
+	batchProcessor     *BatchProcessor
+	spool              *Spool // nil when spooling is disabled
+	retryAfter         time.Duration
+	ackCfg             config.AckConfig
+	idempotencyCfg     config.IdempotencyConfig
+	clientTimestampCfg config.ClientTimestampConfig
+	hashingCfg         config.HashingConfig
+	attestationCfg     config.AttestationConfig
+	schemas            *schemaRegistry
+	signingKeys        *keyRegistry
+	signaturesCfg      config.SignaturesConfig
+	blobs              blobstore.Store // nil when the blob store is disabled
+	blobThreshold      int
+	encryptor          *encryption.Encryptor // Seals offloaded content; nil when encryption is disabled
+	maxBatchEntries    int
+
+	// Background maintenance (idempotency key purge)
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
-// NewService creates a new Service instance with configuration
-func NewService(s store.Store, p producer.Producer, l *log.Logger, batchSize int, batchTimeout time.Duration, flushChannelBuffer int) *Service {
-	return &Service{
-		store:          s,
-		producer:       p,
-		logger:         l,
-		batchProcessor: NewBatchProcessor(batchSize, batchTimeout, flushChannelBuffer, s, p, l),
+// NewService creates a new Service instance with configuration.
+// It fails if the local spool, the blob store or the key provider is enabled but cannot be opened.
+func NewService(s store.Store, p producer.Producer, l *log.Logger, cfg *config.ApiGatewayConfig) (*Service, error) {
+	var spool *Spool
+	if cfg.Spool.Enabled {
+		var err error
+		spool, err = NewSpool(cfg.Spool, l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spool: %w", err)
+		}
+	}
+	var blobs blobstore.Store
+	if cfg.BlobStore.Enabled {
+		var err error
+		blobs, err = blobstore.New(cfg.BlobStore, l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open blob store: %w", err)
+		}
+	}
+	var encryptor *encryption.Encryptor
+	if cfg.BlobStore.Enabled && cfg.Encryption.Enabled {
+		keyProvider, err := encryption.NewKeyProvider(cfg.Encryption.KeyProvider, l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize key provider: %w", err)
+		}
+		encryptor = encryption.NewEncryptor(keyProvider, cfg.Encryption.Orgs)
+	}
+
+	bpCfg := cfg.BatchProcessor
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := &Service{
+		store:              s,
+		producer:           p,
+		logger:             l,
+		batchProcessor:     NewBatchProcessor(bpCfg.BatchSize, bpCfg.BatchTimeout, bpCfg.FlushChannelBuffer, bpCfg.MaxBufferSize, s, p, cfg.Outbox.Enabled, spool, l),
+		spool:              spool,
+		retryAfter:         bpCfg.RetryAfter,
+		ackCfg:             cfg.Ack,
+		idempotencyCfg:     cfg.Idempotency,
+		clientTimestampCfg: cfg.ClientTimestamp,
+		hashingCfg:         cfg.Hashing,
+		attestationCfg:     cfg.Attestation,
+		schemas:            newSchemaRegistry(s, cfg.SchemaRegistry.CacheTTL),
+		signingKeys:        newKeyRegistry(s, cfg.Signatures.CacheTTL),
+		signaturesCfg:      cfg.Signatures,
+		blobs:              blobs,
+		blobThreshold:      cfg.BlobStore.ThresholdBytes,
+		encryptor:          encryptor,
+		maxBatchEntries:    cfg.MaxBatchEntries,
+		ctx:                ctx,
+		cancel:             cancel,
 	}
+
+	svc.wg.Add(1)
+	go svc.idempotencyJanitor()
+
+	return svc, nil
 }
 
-// SubmitLog handles the core logic of log submission
+// SubmitLog handles the core logic of log submission. It fails with ErrBufferFull if the
+// ingestion queue is full.
 func (s *Service) SubmitLog(ctx context.Context, input *LogInput) (*LogResult, error) {
+	return s.submitLog(ctx, input, false)
+}
+
+// SubmitLogWait is SubmitLog, but waits for room in the ingestion queue instead of failing
+// with ErrBufferFull. Streaming callers use it to hold entries back rather than reject them.
+func (s *Service) SubmitLogWait(ctx context.Context, input *LogInput) (*LogResult, error) {
+	return s.submitLog(ctx, input, true)
+}
+
+// WaitForQueue blocks until the ingestion queue has room or ctx ends
+func (s *Service) WaitForQueue(ctx context.Context) error {
+	return s.batchProcessor.WaitAvailable(ctx)
+}
+
+// submitLog validates, hashes and queues a log; waitForQueue selects whether a full queue
+// is waited for or rejected
+func (s *Service) submitLog(ctx context.Context, input *LogInput, waitForQueue bool) (*LogResult, error) {
 	// Log function start time
 	// totalStart := time.Now()
 	// s.logger.Println("Service: Starting to process SubmitLog request...")
 
-	// 1. Validate input
+	// 1. Validate input; structured logs are canonicalized so that the hash ignores formatting
+	contentFormat, err := canonicalizeLogJSON(input)
+	if err != nil {
+		return nil, err
+	}
 	if input.LogContent == "" {
-		return nil, fmt.Errorf("log_content cannot be empty")
+		return nil, ErrEmptyLogContent
+	}
+	schemaVersion, err := s.validateCategory(ctx, input, contentFormat)
+	if err != nil {
+		return nil, err
+	}
+	ackLevel, err := ParseAckLevel(string(input.AckLevel))
+	if err != nil {
+		return nil, err
+	}
+	attestationMode, err := s.attestationMode(input)
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. Get received timestamp
+	// 2. Get received timestamp and check the client timestamp against it
 	receivedTimestamp := time.Now()
+	clientTimestampFlagged, err := s.checkClientTimestamp(input.ClientTimestamp, receivedTimestamp)
+	if err != nil {
+		return nil, err
+	}
 
-	// 3. Calculate/validate hash
-	serverLogHashBytes := sha256.Sum256([]byte(input.LogContent))
-	serverLogHash := fmt.Sprintf("%x", serverLogHashBytes)
-	if input.ClientLogHash != "" && input.ClientLogHash != serverLogHash {
-		return nil, fmt.Errorf("client provided hash '%s' does not match server calculated hash '%s'", input.ClientLogHash, serverLogHash)
+	// 3. Calculate/validate hash and the client signature over it
+	serverLogHash, err := s.computeLogHash(input)
+	if err != nil {
+		return nil, err
 	}
 	input.ClientLogHash = serverLogHash
+	signer, err := s.verifySignature(ctx, input, serverLogHash)
+	if err != nil {
+		return nil, err
+	}
 
 	// 4. Generate Request ID
 	requestID := uuid.NewString()
 
-	// 5. Construct and return result immediately
+	// 5. Reserve the idempotency key; a retry gets the original submission back
+	if input.IdempotencyKey != "" {
+		existing, err := s.reserveIdempotencyKey(ctx, input, requestID, receivedTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return s.replayResult(ctx, existing, serverLogHash, ackLevel, input.AckTimeout)
+		}
+	}
+
+	// 6. Construct result
 	result := &LogResult{
 		RequestID:               requestID,
 		ServerLogHash:           serverLogHash,
 		ServerReceivedTimestamp: receivedTimestamp,
+		Status:                  ResultStatusAccepted,
+		ClientTimestampFlagged:  clientTimestampFlagged,
+		AttestationMode:         attestationMode,
+		ContentFormat:           contentFormat,
+		Category:                input.Category,
+		SchemaVersion:           schemaVersion,
 	}
+	applySigner(result, input.SignatureKeyID, signer)
 
-	// 6. Submit to batch processor (asynchronous)
-	go s.batchProcessor.SubmitLog(input, requestID)
+	// 7. Store large content off chain; only its hash and locator go through the pipeline
+	if err := s.offloadContent(ctx, input, result); err != nil {
+		s.releaseIdempotencyKey(input, requestID)
+		return nil, err
+	}
+
+	// 8. Queue in the batch processor (fails fast when the queue is full, unless waitForQueue),
+	// return immediately for accepted
+	var done chan error
+	if ackLevel != AckAccepted {
+		done = make(chan error, 1)
+	}
+	if waitForQueue {
+		err = s.batchProcessor.SubmitLogWait(ctx, input, result, done)
+	} else {
+		err = s.batchProcessor.SubmitLog(input, result, done)
+	}
+	if err != nil {
+		s.releaseIdempotencyKey(input, requestID)
+		return nil, err
+	}
+	if ackLevel == AckAccepted {
+		return result, nil
+	}
+
+	// 9. Wait until the batch containing this log is in the DB and Kafka
+	select {
+	case err := <-done:
+		if err != nil {
+			s.releaseIdempotencyKey(input, requestID)
+			return nil, fmt.Errorf("%w: request_id %s: %v", ErrNotDurable, requestID, err)
+		}
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for request_id %s to become durable: %w", requestID, ctx.Err())
+	}
+	result.Status = ResultStatusDurable
+	if ackLevel == AckDurable {
+		return result, nil
+	}
+
+	// 10. Wait for the engine to attest the log on chain
+	applyAttestation(result, s.waitForAttestation(ctx, requestID, s.attestTimeout(input.AckTimeout)))
 
 	// Log total function duration
 	// totalDuration := time.Since(totalStart)
@@ -88,7 +296,258 @@ func (s *Service) SubmitLog(ctx context.Context, input *LogInput) (*LogResult, e
 	return result, nil
 }
 
+// SubmitLogs submits several logs in one call. Each entry is validated and acknowledged
+// independently, so a bad entry only fails its own BatchItemResult. The returned slice
+// is in the same order as inputs; an error is returned only if the call as a whole is invalid.
+func (s *Service) SubmitLogs(ctx context.Context, inputs []*LogInput) ([]BatchItemResult, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: entries cannot be empty", ErrInvalidBatch)
+	}
+	if len(inputs) > s.maxBatchEntries {
+		return nil, fmt.Errorf("%w: %d entries exceeds the limit of %d", ErrInvalidBatch, len(inputs), s.maxBatchEntries)
+	}
+	// Reject the whole batch up front if it cannot fit in the queue
+	if available := s.batchProcessor.Available(); len(inputs) > available {
+		return nil, fmt.Errorf("%w: %d entries, room for %d", ErrBufferFull, len(inputs), max(available, 0))
+	}
+
+	// Entries are submitted concurrently so durable/attested waits overlap
+	// and all entries land in the same batch processor flush where possible
+	results := make([]BatchItemResult, len(inputs))
+	var wg sync.WaitGroup
+	for i, input := range inputs {
+		wg.Add(1)
+		go func(i int, input *LogInput) {
+			defer wg.Done()
+			results[i].Result, results[i].Err = s.SubmitLog(ctx, input)
+		}(i, input)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// computeLogHash hashes the log content with the algorithm selected by the request, the tag of
+// the client hash, or the org configuration, in that order, and checks it against the client hash
+func (s *Service) computeLogHash(input *LogInput) (string, error) {
+	algorithm := s.hashingCfg.AlgorithmFor(input.ClientSourceOrgID)
+	var err error
+	if input.HashAlgorithm != "" {
+		algorithm, err = hashing.ParseAlgorithm(input.HashAlgorithm)
+	} else if tag := hashing.Tag(input.ClientLogHash); tag != "" {
+		algorithm, err = hashing.ParseAlgorithm(tag)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	serverLogHash, err := hashing.Sum(algorithm, []byte(input.LogContent))
+	if err != nil {
+		return "", err
+	}
+	if input.ClientLogHash == "" {
+		return serverLogHash, nil
+	}
+
+	clientLogHash, err := hashing.Normalize(input.ClientLogHash, algorithm)
+	if err != nil {
+		return "", err
+	}
+	if clientLogHash != serverLogHash {
+		return "", fmt.Errorf("%w: client '%s', server '%s'", ErrHashMismatch, input.ClientLogHash, serverLogHash)
+	}
+	return serverLogHash, nil
+}
+
+// canonicalizeLogJSON replaces input.LogJSON by its RFC 8785 canonical form in input.LogContent
+// and returns the content format of the log
+func canonicalizeLogJSON(input *LogInput) (string, error) {
+	if len(input.LogJSON) == 0 {
+		return models.ContentFormatText, nil
+	}
+	if input.LogContent != "" {
+		return "", fmt.Errorf("%w: log_content and log_json are mutually exclusive", ErrInvalidLogJSON)
+	}
+
+	canonical, err := jcs.Canonicalize(input.LogJSON)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidLogJSON, err)
+	}
+	input.LogContent = string(canonical)
+	input.LogJSON = nil
+	return models.ContentFormatJSON, nil
+}
+
+// offloadContent puts content above the threshold into the blob store and records its locator.
+// Hash-only content never leaves the ingestion service, so it is not offloaded either.
+func (s *Service) offloadContent(ctx context.Context, input *LogInput, result *LogResult) error {
+	if s.blobs == nil || len(input.LogContent) <= s.blobThreshold || result.AttestationMode == models.AttestationModeHashOnly {
+		return nil
+	}
+	content, err := s.sealBlob(ctx, input.ClientSourceOrgID, result.ServerLogHash, input.LogContent)
+	if err != nil {
+		s.logger.Printf("Service: Failed to seal content of request_id %s: %v", result.RequestID, err)
+		return fmt.Errorf("%w: %v", ErrBlobStore, err)
+	}
+	if content == nil {
+		return nil // Erased; the engine anchors it hash-only
+	}
+	locator, err := s.blobs.Put(ctx, input.ClientSourceOrgID, result.ServerLogHash, content)
+	if err != nil {
+		s.logger.Printf("Service: Failed to store content of request_id %s off chain: %v", result.RequestID, err)
+		return fmt.Errorf("%w: %v", ErrBlobStore, err)
+	}
+	result.ContentLocator = locator
+	return nil
+}
+
+// sealBlob returns the blob of content: an enc:v2 envelope for orgs in encryption scope, sealed with a
+// record key stored like the engine's, so erasing the log crypto-shreds its blob. The log hash stays
+// the hash of the plaintext. It returns nil if the org's log was erased and its record key destroyed.
+func (s *Service) sealBlob(ctx context.Context, orgID, logHash, content string) ([]byte, error) {
+	if s.encryptor == nil || !s.encryptor.Applies(orgID) {
+		return []byte(content), nil
+	}
+
+	// 1. Store a record key; a key stored earlier for the org's log_hash wins
+	recordKey, err := s.encryptor.NewRecordKey(ctx, orgID, logHash)
+	if err != nil {
+		return nil, err
+	}
+	id := store.ContentKeyID{SourceOrgID: orgID, LogHash: logHash}
+	candidate := &store.ContentKey{
+		LogHash:     logHash,
+		SourceOrgID: orgID,
+		OrgKeyID:    recordKey.OrgKeyID,
+		WrappedKey:  &recordKey.WrappedKey,
+	}
+	if err := s.store.InsertContentKeys(ctx, []*store.ContentKey{candidate}); err != nil {
+		return nil, err
+	}
+	storedKeys, err := s.store.GetContentKeys(ctx, []store.ContentKeyID{id})
+	if err != nil {
+		return nil, err
+	}
+	storedKey, ok := storedKeys[id]
+	if !ok {
+		return nil, fmt.Errorf("record key for log_hash %s of org %s was not stored", logHash, orgID)
+	}
+	if storedKey.WrappedKey == nil {
+		return nil, nil
+	}
+
+	// 2. Seal with the stored key
+	recordKey = &encryption.RecordKey{OrgKeyID: storedKey.OrgKeyID, WrappedKey: *storedKey.WrappedKey}
+	sealed, err := s.encryptor.EncryptRecord(ctx, recordKey, orgID, logHash, content)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(sealed), nil
+}
+
+// attestationMode returns the requested attestation mode, or the one configured for the org
+func (s *Service) attestationMode(input *LogInput) (string, error) {
+	if input.AttestationMode == "" {
+		return s.attestationCfg.ModeFor(input.ClientSourceOrgID), nil
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(input.AttestationMode)); mode {
+	case models.AttestationModeFull, models.AttestationModeHashOnly:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: '%s' (expected %s or %s)", ErrInvalidAttestationMode, input.AttestationMode,
+			models.AttestationModeFull, models.AttestationModeHashOnly)
+	}
+}
+
+// attestTimeout bounds the caller-chosen deadline by the configured maximum
+func (s *Service) attestTimeout(requested time.Duration) time.Duration {
+	if requested <= 0 {
+		return s.ackCfg.DefaultAttestTimeout
+	}
+	if requested > s.ackCfg.MaxAttestTimeout {
+		return s.ackCfg.MaxAttestTimeout
+	}
+	return requested
+}
+
+// waitForAttestation polls the state DB until the log is COMPLETED or FAILED, or the timeout passes.
+// It returns the last status read, which is nil if the row could not be read at all.
+func (s *Service) waitForAttestation(ctx context.Context, requestID string, timeout time.Duration) *store.LogStatus {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(s.ackCfg.AttestPollInterval)
+	defer ticker.Stop()
+
+	var last *store.LogStatus
+	for {
+		status, err := s.store.GetLogStatusByRequestID(waitCtx, requestID)
+		switch {
+		case err == nil:
+			last = status
+			if status.Status == store.StatusCompleted || status.Status == store.StatusFailed {
+				return last
+			}
+		case !errors.Is(err, store.ErrLogNotFound) && waitCtx.Err() == nil:
+			s.logger.Printf("Service: Failed to poll status for request_id %s: %v", requestID, err)
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return last
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyAttestation copies the state DB status of an attested wait into the result
+func applyAttestation(result *LogResult, status *store.LogStatus) {
+	if status == nil {
+		return
+	}
+	result.Status = string(status.Status)
+	result.ClientTimestampFlagged = status.ClientTimestampFlagged
+	result.AttestationMode = status.AttestationMode
+	result.ContentFormat = status.ContentFormat
+	applySchema(result, status)
+	if status.TxHash != nil {
+		result.TxHash = *status.TxHash
+	}
+	if status.BlockHeight != nil {
+		result.BlockHeight = *status.BlockHeight
+	}
+	if status.ErrorMessage != nil && status.Status == store.StatusFailed {
+		result.ErrorMessage = *status.ErrorMessage
+	}
+}
+
+// QueueStats returns the batch processor queue counters
+func (s *Service) QueueStats() QueueStats {
+	return s.batchProcessor.Stats()
+}
+
+// MaxBatchEntries is the largest number of inputs SubmitLogs accepts in one call
+func (s *Service) MaxBatchEntries() int {
+	return s.maxBatchEntries
+}
+
+// RetryAfter is the delay suggested to clients whose submission was rejected with ErrBufferFull
+func (s *Service) RetryAfter() time.Duration {
+	return s.retryAfter
+}
+
+// SpoolStats returns the spool counters, or nil when spooling is disabled
+func (s *Service) SpoolStats() *SpoolStats {
+	if s.spool == nil {
+		return nil
+	}
+	stats := s.spool.Stats()
+	return &stats
+}
+
 // Close gracefully shuts down the service
 func (s *Service) Close() {
+	s.cancel()
+	s.wg.Wait()
 	s.batchProcessor.Close()
 }
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tlng/internal/signing"
+	"tlng/storage/store"
+)
+
+// clientKeyID identifies a public key of an org
+type clientKeyID struct {
+	orgID string
+	keyID string
+}
+
+// keyRegistry caches the public keys clients sign with for ttl, so verification does not
+// hit the state DB per log. A revocation takes effect once the entry expires.
+type keyRegistry struct {
+	store store.Store
+	cache *ttlCache[clientKeyID, *signing.PublicKey]
+}
+
+func newKeyRegistry(s store.Store, ttl time.Duration) *keyRegistry {
+	r := &keyRegistry{store: s}
+	r.cache = newTTLCache(ttl, registryCacheSize, r.load)
+	return r
+}
+
+// active returns an org's public key, or nil if it is not registered or revoked
+func (r *keyRegistry) active(ctx context.Context, orgID, keyID string) (*signing.PublicKey, error) {
+	return r.cache.get(ctx, clientKeyID{orgID: orgID, keyID: keyID})
+}
+
+// load reads and parses an org's public key from the state DB; revoked keys load as nil
+func (r *keyRegistry) load(ctx context.Context, id clientKeyID) (*signing.PublicKey, error) {
+	stored, err := r.store.GetClientKey(ctx, id.orgID, id.keyID)
+	if errors.Is(err, store.ErrClientKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client key '%s': %w", id.keyID, err)
+	}
+	if stored.RevokedAt != nil {
+		return nil, nil
+	}
+
+	key, err := signing.ParsePublicKey(signing.Algorithm(stored.Algorithm), []byte(stored.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client key '%s': %w", id.keyID, err)
+	}
+	return key, nil
+}
+
+// verifySignature checks the client signature over the log hash against the org's registered key
+// and returns that key, or nil for an unsigned log. Unsigned logs pass unless the org requires signatures.
+func (s *Service) verifySignature(ctx context.Context, input *LogInput, logHash string) (*signing.PublicKey, error) {
+	if len(input.Signature) == 0 && input.SignatureKeyID == "" {
+		if s.signaturesCfg.Required(input.ClientSourceOrgID) {
+			return nil, fmt.Errorf("%w for org '%s'", ErrSignatureRequired, input.ClientSourceOrgID)
+		}
+		return nil, nil
+	}
+	if len(input.Signature) == 0 || input.SignatureKeyID == "" {
+		return nil, fmt.Errorf("%w: signature and signature_key_id must be sent together", signing.ErrInvalidSignature)
+	}
+	if err := signing.ValidateKeyID(input.SignatureKeyID); err != nil {
+		return nil, err
+	}
+
+	key, err := s.signingKeys.active(ctx, input.ClientSourceOrgID, input.SignatureKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: '%s' of org '%s'", ErrUnknownSigningKey, input.SignatureKeyID, input.ClientSourceOrgID)
+	}
+	if err := key.Verify([]byte(logHash), input.Signature); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// applySigner records the key a log was signed with in the result
+func applySigner(result *LogResult, keyID string, key *signing.PublicKey) {
+	if key == nil {
+		return
+	}
+	result.SignatureKeyID = keyID
+	result.SignatureAlgorithm = string(key.Algorithm)
+	result.SignatureKeyFingerprint = key.Fingerprint
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tlng/config"
+	"tlng/internal/models"
+)
+
+const (
+	spoolSegmentExt = ".spool"   // Complete segment waiting for replay
+	spoolTempExt    = ".tmp"     // Segment being written, ignored until renamed
+	spoolCorruptExt = ".corrupt" // Unreadable segment, kept for manual inspection
+)
+
+// Spool is a disk-backed write-ahead spool for batches that could not be written
+// to the state DB or Kafka. Every batch is stored as one segment file, written to
+// a temporary name, fsynced and renamed, so a crash never leaves a partial segment.
+// Segments are replayed oldest first and removed once the batch has been written.
+type Spool struct {
+	dir              string
+	maxBytes         int64
+	replayInterval   time.Duration
+	maxReplayBackoff time.Duration
+	logger           *log.Logger
+
+	mu        sync.Mutex // Serializes appends and guards sizeBytes/seq
+	sizeBytes int64
+	seq       uint64
+
+	// Counters since startup
+	spooledEntries  atomic.Int64
+	replayedEntries atomic.Int64
+	droppedEntries  atomic.Int64
+}
+
+// SpoolStats is a snapshot of the spool counters for the metrics endpoint
+type SpoolStats struct {
+	SpooledEntries  int64 `json:"spooled_entries"`
+	ReplayedEntries int64 `json:"replayed_entries"`
+	DroppedEntries  int64 `json:"dropped_entries"`
+	PendingSegments int   `json:"pending_segments"`
+	PendingBytes    int64 `json:"pending_bytes"`
+}
+
+// NewSpool opens (or creates) the spool directory and accounts for segments left by a previous run
+func NewSpool(cfg config.SpoolConfig, logger *log.Logger) (*Spool, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory '%s': %w", cfg.Dir, err)
+	}
+
+	sp := &Spool{
+		dir:              cfg.Dir,
+		maxBytes:         cfg.MaxBytes,
+		replayInterval:   cfg.ReplayInterval,
+		maxReplayBackoff: cfg.MaxReplayBackoff,
+		logger:           logger,
+	}
+
+	entries, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory '%s': %w", cfg.Dir, err)
+	}
+
+	segments := 0
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, spoolTempExt):
+			// Interrupted write, the batch was never acknowledged as spooled
+			os.Remove(filepath.Join(cfg.Dir, name))
+		case strings.HasSuffix(name, spoolSegmentExt):
+			info, err := entry.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat spool segment '%s': %w", name, err)
+			}
+			sp.sizeBytes += info.Size()
+			segments++
+		}
+	}
+
+	logger.Printf("Spool: Opened %s with %d pending segments (%d bytes)", cfg.Dir, segments, sp.sizeBytes)
+	return sp, nil
+}
+
+// Append durably writes a batch as a new segment.
+// It fails with ErrSpoolFull if the segment would grow the spool beyond max_bytes.
+func (sp *Spool) Append(msgs []*models.LogMessage) error {
+	data, err := json.Marshal(msgs)
+	if err != nil {
+		return fmt.Errorf("failed to encode spool segment: %w", err)
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if sp.sizeBytes+int64(len(data)) > sp.maxBytes {
+		sp.droppedEntries.Add(int64(len(msgs)))
+		return fmt.Errorf("%w: %d of %d bytes used", ErrSpoolFull, sp.sizeBytes, sp.maxBytes)
+	}
+
+	// 1. Write to a temporary file; the timestamp prefix keeps segments in arrival order
+	sp.seq++
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), sp.seq%1000000, spoolSegmentExt)
+	path := filepath.Join(sp.dir, name)
+	tmpPath := path + spoolTempExt
+
+	if err := writeFileSync(tmpPath, data); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write spool segment: %w", err)
+	}
+
+	// 2. Publish the segment atomically and persist the directory entry
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to commit spool segment: %w", err)
+	}
+	if err := syncDir(sp.dir); err != nil {
+		sp.logger.Printf("Spool: Failed to sync directory %s: %v", sp.dir, err)
+	}
+
+	sp.sizeBytes += int64(len(data))
+	sp.spooledEntries.Add(int64(len(msgs)))
+	return nil
+}
+
+// segments lists pending segment names, oldest first
+func (sp *Spool) segments() ([]string, error) {
+	entries, err := os.ReadDir(sp.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), spoolSegmentExt) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// read loads the batch stored in a segment.
+// A segment that cannot be decoded is renamed to *.corrupt so it does not block replay.
+func (sp *Spool) read(name string) ([]*models.LogMessage, error) {
+	path := filepath.Join(sp.dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool segment '%s': %w", name, err)
+	}
+
+	var msgs []*models.LogMessage
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		sp.logger.Printf("Spool: Segment %s is corrupt, moving it aside: %v", name, err)
+		if renameErr := os.Rename(path, path+spoolCorruptExt); renameErr == nil {
+			sp.release(int64(len(data)))
+		}
+		return nil, nil
+	}
+	return msgs, nil
+}
+
+// remove deletes a segment after its batch was replayed
+func (sp *Spool) remove(name string, entries int) error {
+	path := filepath.Join(sp.dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat spool segment '%s': %w", name, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove spool segment '%s': %w", name, err)
+	}
+
+	sp.release(info.Size())
+	sp.replayedEntries.Add(int64(entries))
+	return nil
+}
+
+// release returns segment bytes to the size budget
+func (sp *Spool) release(size int64) {
+	sp.mu.Lock()
+	sp.sizeBytes -= size
+	sp.mu.Unlock()
+}
+
+// Stats returns a snapshot of the spool counters
+func (sp *Spool) Stats() SpoolStats {
+	stats := SpoolStats{
+		SpooledEntries:  sp.spooledEntries.Load(),
+		ReplayedEntries: sp.replayedEntries.Load(),
+		DroppedEntries:  sp.droppedEntries.Load(),
+	}
+
+	if names, err := sp.segments(); err == nil {
+		stats.PendingSegments = len(names)
+	}
+	sp.mu.Lock()
+	stats.PendingBytes = sp.sizeBytes
+	sp.mu.Unlock()
+
+	return stats
+}
+
+// writeFileSync writes data to a new file and fsyncs it before closing
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir fsyncs a directory so a rename inside it survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
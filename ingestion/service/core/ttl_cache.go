@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// registryCacheSize bounds the entries of each registry cache. Lookups of unknown categories
+// and key IDs are cached too, so the bound keeps callers from growing the cache without limit.
+const registryCacheSize = 10000
+
+// ttlCache caches the values of load for ttl, so lookups do not hit the state DB per log.
+// Values are cached even when they are nil (not found); load failures are not cached.
+// Once maxEntries are cached, expired entries are dropped first, then the oldest one.
+type ttlCache[K comparable, V any] struct {
+	load       func(ctx context.Context, key K) (V, error)
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[K]ttlEntry[V]
+}
+
+type ttlEntry[V any] struct {
+	value    V
+	loadedAt time.Time
+}
+
+func newTTLCache[K comparable, V any](ttl time.Duration, maxEntries int, load func(ctx context.Context, key K) (V, error)) *ttlCache[K, V] {
+	return &ttlCache[K, V]{load: load, ttl: ttl, maxEntries: maxEntries, entries: make(map[K]ttlEntry[V])}
+}
+
+// get returns the cached value of key, loading it if it is missing or expired
+func (c *ttlCache[K, V]) get(ctx context.Context, key K) (V, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.loadedAt) < c.ttl {
+		return entry.value, nil
+	}
+
+	loadedAt := time.Now()
+	value, err := c.load(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(loadedAt)
+	}
+	c.entries[key] = ttlEntry[V]{value: value, loadedAt: loadedAt}
+	return value, nil
+}
+
+// evict makes room for one entry: it drops the expired entries, or the oldest if none has expired
+func (c *ttlCache[K, V]) evict(now time.Time) {
+	var oldestKey K
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if now.Sub(entry.loadedAt) >= c.ttl {
+			delete(c.entries, key)
+			continue
+		}
+		if oldest.IsZero() || entry.loadedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.loadedAt
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,291 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"tlng/config"
+	"tlng/internal/auth"
+	pb "tlng/proto/logingestion"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// API key checks shared with the ingress (ingress/nginx/lua/auth_common.lua)
+const (
+	apiKeyPermission = "submit_log"     // Permission an API key needs to submit logs
+	apiKeyClockSkew  = 10 * time.Second // Tolerance for created_at and expires_at
+)
+
+// Metadata keys read by the authentication methods
+const (
+	metadataAPIKey      = "x-api-key"
+	metadataGatewayOrg  = "x-client-org-id" // Set and signed by the ingress after it validated the API key
+	metadataGatewayUser = "x-api-client-id" // Set and signed by the ingress after it validated the API key
+)
+
+// identity is the authenticated caller of a gRPC call
+type identity struct {
+	orgID    string
+	clientID string // API key client_id, certificate subject or the ingress-supplied client ID
+	method   string
+}
+
+// identityKey is the context key of the caller's identity
+type identityKey struct{}
+
+// OrgFromContext returns the org the Authenticator resolved for a call, or "" if the call
+// was not authenticated. Services whose requests carry no client_source_org_id, such as
+// the OTLP receiver, bind their submissions to it.
+func OrgFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(identityKey{}).(*identity); ok {
+		return id.orgID
+	}
+	return ""
+}
+
+// apiKey is an entry of the API key file, keyed by the key itself
+type apiKey struct {
+	ClientID    string   `json:"client_id"`
+	OrgID       string   `json:"org_id"`
+	Status      string   `json:"status"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   string   `json:"created_at"`
+	ExpiresAt   string   `json:"expires_at"`
+}
+
+// Authenticator resolves the org of every gRPC caller and rejects submissions for other orgs
+type Authenticator struct {
+	cfg     config.GrpcAuthConfig
+	gateway *auth.GatewayVerifier // Checks the ingress signature for the gateway method; nil rejects it
+	logger  *log.Logger
+
+	mu       sync.Mutex
+	keys     map[string]*apiKey
+	loadedAt time.Time
+}
+
+// NewAuthenticator creates an Authenticator; the API key file must load if the api_key method is enabled
+func NewAuthenticator(cfg config.GrpcAuthConfig, gateway *auth.GatewayVerifier, logger *log.Logger) (*Authenticator, error) {
+	a := &Authenticator{cfg: cfg, gateway: gateway, logger: logger}
+	if cfg.HasMethod(config.GrpcAuthMethodAPIKey) {
+		keys, err := loadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		a.keys, a.loadedAt = keys, time.Now()
+	}
+	logger.Printf("gRPC authentication enabled (methods %v)", cfg.Methods)
+	return a, nil
+}
+
+// loadAPIKeys reads an API key file in the ingress api-keys.json format
+func loadAPIKeys(path string) (map[string]*apiKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key file '%s': %w", path, err)
+	}
+	var keys map[string]*apiKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API key file '%s': %w", path, err)
+	}
+	return keys, nil
+}
+
+// apiKeys returns the API keys, re-reading the file every reload_interval.
+// A file that fails to load keeps the previous keys in use.
+func (a *Authenticator) apiKeys() map[string]*apiKey {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if time.Since(a.loadedAt) >= a.cfg.ReloadInterval {
+		if keys, err := loadAPIKeys(a.cfg.APIKeysFile); err != nil {
+			a.logger.Printf("gRPC Auth: keeping previous API keys: %v", err)
+		} else {
+			a.keys = keys
+		}
+		a.loadedAt = time.Now()
+	}
+	return a.keys
+}
+
+// UnaryInterceptor authenticates unary calls and checks the org of the submitted logs
+func (a *Authenticator) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	id, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		a.logger.Printf("gRPC Auth: rejected %s from %s: %v", info.FullMethod, peerAddr(ctx), err)
+		return nil, err
+	}
+	if err := authorize(id, req); err != nil {
+		a.logger.Printf("gRPC Auth: rejected %s by client %s (%s): %v", info.FullMethod, id.clientID, id.method, err)
+		return nil, err
+	}
+	return handler(context.WithValue(ctx, identityKey{}, id), req)
+}
+
+// StreamInterceptor authenticates streams when they are opened and checks the org of every received log
+func (a *Authenticator) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		a.logger.Printf("gRPC Auth: rejected %s from %s: %v", info.FullMethod, peerAddr(ss.Context()), err)
+		return err
+	}
+	return handler(srv, &authorizedStream{ServerStream: ss, identity: id})
+}
+
+// authorizedStream rejects received logs of orgs other than the caller's, which ends the stream
+type authorizedStream struct {
+	grpc.ServerStream
+	identity *identity
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return context.WithValue(s.ServerStream.Context(), identityKey{}, s.identity)
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return authorize(s.identity, m)
+}
+
+// authenticate tries the configured methods in order. A method whose credentials are absent
+// is skipped; credentials that are present but invalid fail the call.
+func (a *Authenticator) authenticate(ctx context.Context, fullMethod string) (*identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, method := range a.cfg.Methods {
+		switch method {
+		case config.GrpcAuthMethodAPIKey:
+			if key := firstValue(md, metadataAPIKey); key != "" {
+				return a.authenticateAPIKey(key)
+			}
+		case config.GrpcAuthMethodMTLS:
+			if id, ok, err := certificateIdentity(ctx); ok {
+				return id, err
+			}
+		case config.GrpcAuthMethodGateway:
+			if orgID := firstValue(md, metadataGatewayOrg); orgID != "" {
+				if a.gateway == nil {
+					return nil, status.Error(codes.Unauthenticated, "gateway identity cannot be verified")
+				}
+				// The ingress signs gRPC calls as POST requests to the method path
+				header := func(name string) string { return firstValue(md, name) }
+				if err := a.gateway.Verify(http.MethodPost, fullMethod, header); err != nil {
+					return nil, status.Error(codes.Unauthenticated, err.Error())
+				}
+				return &identity{orgID: orgID, clientID: firstValue(md, metadataGatewayUser), method: method}, nil
+			}
+		}
+	}
+	return nil, status.Errorf(codes.Unauthenticated, "authentication required (%v)", a.cfg.Methods)
+}
+
+// authenticateAPIKey applies the same checks as the ingress
+func (a *Authenticator) authenticateAPIKey(key string) (*identity, error) {
+	entry, ok := a.apiKeys()[key]
+	if !ok || entry == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	if entry.Status != "active" {
+		return nil, status.Error(codes.Unauthenticated, "API key is not active")
+	}
+
+	now := time.Now()
+	createdAt, err := time.Parse(time.RFC3339, entry.CreatedAt)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "API key has an invalid created_at")
+	}
+	if createdAt.After(now.Add(apiKeyClockSkew)) {
+		return nil, status.Error(codes.Unauthenticated, "API key is not yet active")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, entry.ExpiresAt)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "API key has an invalid expires_at")
+	}
+	if !expiresAt.After(now.Add(-apiKeyClockSkew)) {
+		return nil, status.Error(codes.Unauthenticated, "API key has expired")
+	}
+
+	if !slices.Contains(entry.Permissions, apiKeyPermission) {
+		return nil, status.Errorf(codes.PermissionDenied, "permission '%s' is required", apiKeyPermission)
+	}
+	if entry.OrgID == "" {
+		return nil, status.Error(codes.PermissionDenied, "API key is not bound to an org")
+	}
+	return &identity{orgID: entry.OrgID, clientID: entry.ClientID, method: config.GrpcAuthMethodAPIKey}, nil
+}
+
+// certificateIdentity returns the org of a verified client certificate, its subject Organization (O).
+// ok is false if the connection carries no verified certificate.
+func certificateIdentity(ctx context.Context) (id *identity, ok bool, err error) {
+	p, found := peer.FromContext(ctx)
+	if !found {
+		return nil, false, nil
+	}
+	tlsInfo, isTLS := p.AuthInfo.(credentials.TLSInfo)
+	if !isTLS || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false, nil
+	}
+
+	leaf := tlsInfo.State.VerifiedChains[0][0]
+	if len(leaf.Subject.Organization) == 0 || leaf.Subject.Organization[0] == "" {
+		return nil, true, status.Errorf(codes.PermissionDenied, "client certificate '%s' has no Organization (O)", leaf.Subject)
+	}
+	return &identity{orgID: leaf.Subject.Organization[0], clientID: leaf.Subject.String(), method: config.GrpcAuthMethodMTLS}, true, nil
+}
+
+// authorize binds submitted logs to the caller's org: an empty client_source_org_id is set to it,
+// any other org is rejected
+func authorize(id *identity, req interface{}) error {
+	switch r := req.(type) {
+	case *pb.SubmitLogRequest:
+		return authorizeOrg(id, r)
+	case *pb.SubmitLogsBatchRequest:
+		for i, entry := range r.GetEntries() {
+			if err := authorizeOrg(id, entry); err != nil {
+				return status.Errorf(codes.PermissionDenied, "entry %d: %s", i, status.Convert(err).Message())
+			}
+		}
+	}
+	return nil
+}
+
+func authorizeOrg(id *identity, req *pb.SubmitLogRequest) error {
+	switch req.GetClientSourceOrgId() {
+	case "":
+		req.ClientSourceOrgId = id.orgID
+	case id.orgID:
+	default:
+		return status.Errorf(codes.PermissionDenied, "client_source_org_id '%s' does not match the authenticated org '%s'",
+			req.GetClientSourceOrgId(), id.orgID)
+	}
+	return nil
+}
+
+// firstValue returns the first value of a metadata key, or ""
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerAddr returns the caller's address for logging
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return "-"
+}
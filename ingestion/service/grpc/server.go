@@ -2,13 +2,21 @@ package grpc
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"io"
 	"log"
+	"sync"
+	"time"
 
 	// Import generated proto code and service layer
 	core "tlng/ingestion/service/core"
+	"tlng/internal/hashing"
+	"tlng/internal/schema"
+	"tlng/internal/signing"
 	pb "tlng/proto/logingestion"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb" // For Protobuf Timestamp
 )
 
@@ -17,11 +25,12 @@ type Server struct {
 	pb.UnimplementedLogIngestionServer // Embed unimplemented service for forward compatibility
 	svc                                *core.Service
 	logger                             *log.Logger
+	streamMaxInFlight                  int // Unacknowledged entries allowed per SubmitLogStream
 }
 
 // NewServer creates a new gRPC Server instance
-func NewServer(s *core.Service, l *log.Logger) *Server {
-	return &Server{svc: s, logger: l}
+func NewServer(s *core.Service, l *log.Logger, streamMaxInFlight int) *Server {
+	return &Server{svc: s, logger: l, streamMaxInFlight: streamMaxInFlight}
 }
 
 // SubmitLog implements the SubmitLog method in the gRPC interface
@@ -29,35 +38,223 @@ func (s *Server) SubmitLog(ctx context.Context, req *pb.SubmitLogRequest) (*pb.S
 	s.logger.Println("gRPC Server: Received SubmitLog request")
 
 	// 1. Convert Protobuf request to Service layer input structure
+	input := toLogInput(req)
+
+	// 2. Call core Service layer processing logic
+	result, err := s.svc.SubmitLog(ctx, input)
+	if err != nil {
+		s.logger.Printf("gRPC Server: Service layer error: %v", err)
+		return nil, statusError(err, "failed to process log submission")
+	}
+
+	// 3. Convert Service layer result to Protobuf response
+	response := toResponse(result)
+
+	s.logger.Printf("gRPC Server: Successfully processed request_id: %s", result.RequestID)
+	return response, nil
+}
+
+// SubmitLogsBatch implements the SubmitLogsBatch method in the gRPC interface
+func (s *Server) SubmitLogsBatch(ctx context.Context, req *pb.SubmitLogsBatchRequest) (*pb.SubmitLogsBatchResponse, error) {
+	s.logger.Printf("gRPC Server: Received SubmitLogsBatch request with %d entries", len(req.GetEntries()))
+
+	// 1. Convert every entry to Service layer input
+	inputs := make([]*core.LogInput, len(req.GetEntries()))
+	for i, entry := range req.GetEntries() {
+		inputs[i] = toLogInput(entry)
+	}
+
+	// 2. Submit all entries; only a malformed batch fails the whole call
+	items, err := s.svc.SubmitLogs(ctx, inputs)
+	if err != nil {
+		s.logger.Printf("gRPC Server: Service layer error: %v", err)
+		return nil, statusError(err, "failed to process batch submission")
+	}
+
+	// 3. Build one result per entry
+	response := &pb.SubmitLogsBatchResponse{Results: make([]*pb.SubmitLogResult, len(items))}
+	for i, item := range items {
+		res := &pb.SubmitLogResult{Index: int32(i)}
+		if item.Err != nil {
+			res.Error = item.Err.Error()
+			res.Code = uint32(status.Code(statusError(item.Err, "failed to process log submission")))
+		} else {
+			res.Response = toResponse(item.Result)
+		}
+		response.Results[i] = res
+	}
+
+	return response, nil
+}
+
+// SubmitLogStream implements the bidirectional SubmitLogStream method in the gRPC interface.
+// Each entry is acknowledged once it reaches its ack_level (durable unless set otherwise).
+// At most streamMaxInFlight entries are unacknowledged; while that limit is reached the
+// server stops calling Recv, so HTTP/2 flow control pushes back on the client.
+func (s *Server) SubmitLogStream(stream pb.LogIngestion_SubmitLogStreamServer) error {
+	ctx := stream.Context()
+	s.logger.Println("gRPC Server: SubmitLogStream opened")
+
+	inFlight := make(chan struct{}, s.streamMaxInFlight)
+	acks := make(chan *pb.SubmitLogStreamAck, s.streamMaxInFlight)
+
+	// 1. Single sender goroutine, stream.Send must not be called concurrently.
+	// After a send error it keeps draining so submitters never block.
+	sendDone := make(chan error, 1)
+	go func() {
+		var sendErr error
+		for ack := range acks {
+			if sendErr != nil {
+				continue
+			}
+			if err := stream.Send(ack); err != nil {
+				s.logger.Printf("gRPC Server: SubmitLogStream send failed: %v", err)
+				sendErr = err
+			}
+		}
+		sendDone <- sendErr
+	}()
+
+	// 2. Receive loop, one submitter goroutine per entry
+	var wg sync.WaitGroup
+	var recvErr error
+	var sequence uint64
+	for {
+		// Reserve an in-flight slot before reading the next entry
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			recvErr = ctx.Err()
+		}
+		if recvErr != nil {
+			break
+		}
+
+		// Stop reading while the ingestion queue is full, instead of rejecting entries
+		if err := s.svc.WaitForQueue(ctx); err != nil {
+			<-inFlight
+			recvErr = err
+			break
+		}
+
+		req, err := stream.Recv()
+		if err != nil {
+			<-inFlight
+			if !errors.Is(err, io.EOF) {
+				recvErr = err
+			}
+			break
+		}
+
+		input := toLogInput(req)
+		if input.AckLevel == "" {
+			input.AckLevel = core.AckDurable
+		}
+
+		wg.Add(1)
+		go func(sequence uint64, input *core.LogInput) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			ack := &pb.SubmitLogStreamAck{Sequence: sequence}
+			result, err := s.svc.SubmitLogWait(ctx, input)
+			if err != nil {
+				ack.Error = err.Error()
+				ack.Code = uint32(status.Code(statusError(err, "failed to process log submission")))
+			} else {
+				ack.Response = toResponse(result)
+			}
+			acks <- ack
+		}(sequence, input)
+		sequence++
+	}
+
+	// 3. Wait for outstanding acknowledgements, then finish the stream
+	wg.Wait()
+	close(acks)
+	sendErr := <-sendDone
+
+	s.logger.Printf("gRPC Server: SubmitLogStream closed after %d entries", sequence)
+	if recvErr != nil {
+		return statusError(recvErr, "failed to receive log stream")
+	}
+	return sendErr
+}
+
+// toLogInput converts a Protobuf request to the Service layer input structure
+func toLogInput(req *pb.SubmitLogRequest) *core.LogInput {
 	input := &core.LogInput{
 		LogContent:        req.GetLogContent(),
+		LogJSON:           []byte(req.GetLogJson()),
 		ClientLogHash:     req.GetClientLogHash(),
 		ClientSourceOrgID: req.GetClientSourceOrgId(),
+		AckLevel:          core.AckLevel(req.GetAckLevel()),
+		AckTimeout:        time.Duration(req.GetAckTimeoutMs()) * time.Millisecond,
+		IdempotencyKey:    req.GetIdempotencyKey(),
+		HashAlgorithm:     req.GetHashAlgorithm(),
+		AttestationMode:   req.GetAttestationMode(),
+		Category:          req.GetCategory(),
+		Signature:         req.GetSignature(),
+		SignatureKeyID:    req.GetSignatureKeyId(),
 	}
 	// Handle optional timestamp
 	if req.ClientTimestamp != nil && req.ClientTimestamp.IsValid() {
 		ts := req.ClientTimestamp.AsTime()
 		input.ClientTimestamp = &ts
 	}
+	return input
+}
 
-	// 2. Call core Service layer processing logic
-	result, err := s.svc.SubmitLog(ctx, input)
-	if err != nil {
-		s.logger.Printf("gRPC Server: Service layer error: %v", err)
-		// Can return different gRPC error codes based on error type
-		return nil, fmt.Errorf("failed to process log submission: %w", err) // Return generic error
-	}
-
-	// 3. Convert Service layer result to Protobuf response
-	response := &pb.SubmitLogResponse{
+// toResponse converts a Service layer result to the Protobuf response
+func toResponse(result *core.LogResult) *pb.SubmitLogResponse {
+	return &pb.SubmitLogResponse{
 		RequestId:               result.RequestID,
 		ServerLogHash:           result.ServerLogHash,
 		ServerReceivedTimestamp: timestamppb.New(result.ServerReceivedTimestamp),
-		Status:                  "ACCEPTED",
+		Status:                  result.Status,
+		TxHash:                  result.TxHash,
+		BlockHeight:             result.BlockHeight,
+		ErrorMessage:            result.ErrorMessage,
+		IdempotentReplay:        result.IdempotentReplay,
+		ClientTimestampFlagged:  result.ClientTimestampFlagged,
+		AttestationMode:         result.AttestationMode,
+		ContentFormat:           result.ContentFormat,
+		Category:                result.Category,
+		SchemaVersion:           int32(result.SchemaVersion),
+		ContentLocator:          result.ContentLocator,
+		SignatureKeyId:          result.SignatureKeyID,
+		SignatureAlgorithm:      result.SignatureAlgorithm,
+		SignatureKeyFingerprint: result.SignatureKeyFingerprint,
 	}
+}
 
-	s.logger.Printf("gRPC Server: Successfully processed request_id: %s", result.RequestID)
-	return response, nil
+// statusError maps Service layer errors to gRPC status codes. Errors that already carry a status,
+// such as those of the auth interceptors, are kept; unexpected errors are INTERNAL, prefixed with msg.
+func statusError(err error, msg string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, core.ErrBufferFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, core.ErrEmptyLogContent), errors.Is(err, core.ErrHashMismatch), errors.Is(err, core.ErrInvalidAckLevel),
+		errors.Is(err, core.ErrInvalidBatch), errors.Is(err, core.ErrInvalidIdempotencyKey), errors.Is(err, core.ErrClockSkew), errors.Is(err, core.ErrInvalidAttestationMode),
+		errors.Is(err, core.ErrInvalidLogJSON),
+		errors.Is(err, schema.ErrInvalidCategory), errors.Is(err, schema.ErrValidation), errors.Is(err, core.ErrUnknownCategory),
+		errors.Is(err, hashing.ErrUnsupportedAlgorithm), errors.Is(err, hashing.ErrInvalidHash),
+		errors.Is(err, signing.ErrInvalidSignature), errors.Is(err, signing.ErrInvalidKeyID),
+		errors.Is(err, core.ErrUnknownSigningKey), errors.Is(err, core.ErrSignatureRequired):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, core.ErrIdempotencyConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, core.ErrNotDurable), errors.Is(err, core.ErrBlobStore):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
 }
 
 // Ensure Server implements the interface (compile-time check)
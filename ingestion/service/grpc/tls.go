@@ -0,0 +1,20 @@
+package grpc
+
+import (
+	"fmt"
+
+	"tlng/config"
+	"tlng/internal/tlsconfig"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// NewServerCredentials builds the TLS credentials of the gRPC listener. With a client CA,
+// client certificates are verified against it, and required if client_auth is "require".
+func NewServerCredentials(cfg config.GrpcTLSConfig) (credentials.TransportCredentials, error) {
+	tlsConfig, err := tlsconfig.NewServer(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile, cfg.ClientAuth == config.GrpcClientAuthRequire)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC TLS: %w", err)
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
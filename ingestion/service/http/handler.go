@@ -1,13 +1,28 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"regexp"
+	"strconv"
 	"time"
 
 	core "tlng/ingestion/service/core"
+	"tlng/internal/auth"
+	"tlng/internal/hashing"
+	"tlng/internal/schema"
+	"tlng/internal/signing"
+	"tlng/storage/store"
+)
+
+// Errors of the org check against the auth context
+var (
+	errUnauthenticated = errors.New("authenticated organization required")
+	errOrgMismatch     = errors.New("client_source_org_id does not match the authenticated organization")
 )
 
 // LogHandler encapsulates the logic for handling HTTP log requests
@@ -21,64 +36,208 @@ func NewLogHandler(s *core.Service, l *log.Logger) *LogHandler {
 	return &LogHandler{svc: s, logger: l}
 }
 
+// logPayload is the JSON body of POST /v1/logs and one entry of POST /v1/logs/batch
+type logPayload struct {
+	LogContent        string          `json:"log_content"`
+	LogJSON           json.RawMessage `json:"log_json,omitempty"` // Structured event instead of log_content, hashed in RFC 8785 canonical form
+	ClientLogHash     string          `json:"client_log_hash,omitempty"`
+	ClientSourceOrgID string          `json:"client_source_org_id,omitempty"`
+	ClientTimestamp   string          `json:"client_timestamp,omitempty"`
+	AckLevel          string          `json:"ack_level,omitempty"`
+	AckTimeoutMs      int64           `json:"ack_timeout_ms,omitempty"`
+	IdempotencyKey    string          `json:"idempotency_key,omitempty"`  // Batch entries; single submissions use the Idempotency-Key header
+	HashAlgorithm     string          `json:"hash_algorithm,omitempty"`   // sha256, sha3-256 or sm3; defaults to the org's algorithm
+	AttestationMode   string          `json:"attestation_mode,omitempty"` // full or hash_only; defaults to the org's mode
+	Category          string          `json:"category,omitempty"`         // log_json only; validated against the org's schema for it
+	Signature         []byte          `json:"signature,omitempty"`        // Base64 client signature over server_log_hash
+	SignatureKeyID    string          `json:"signature_key_id,omitempty"` // Registered key of the org the signature verifies with
+}
+
 // SubmitLog handles POST /v1/logs requests
 func (h *LogHandler) SubmitLog(w http.ResponseWriter, r *http.Request) {
 	// start := time.Now()
 
-	if r.Method != http.MethodPost {
-		h.respondError(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+	if !h.validateSubmitRequest(w, r) {
 		return
 	}
 
-	// Content-Type validation
-	if r.Header.Get("Content-Type") != "application/json" {
-		h.respondError(w, "Content-Type must be application/json", http.StatusBadRequest)
+	// 1. Parse request body JSON
+	var reqPayload logPayload
+
+	if err := json.NewDecoder(r.Body).Decode(&reqPayload); err != nil {
+		h.logger.Printf("HTTP Handler: Failed to parse JSON request: %v", err)
+		h.respondError(w, "Bad Request: Invalid JSON format", http.StatusBadRequest)
 		return
 	}
+	defer r.Body.Close()
 
-	// Request size limit
-	if r.ContentLength > 10*1024*1024 { // 10MB limit
-		h.respondError(w, "Request body too large", http.StatusRequestEntityTooLarge)
+	// 2. Validate required fields
+	if reqPayload.LogContent == "" && len(reqPayload.LogJSON) == 0 {
+		h.respondError(w, "log_content or log_json is required", http.StatusBadRequest)
+		return
+	}
+
+	// 3. Construct Service layer input for the authenticated org
+	input, err := h.toLogInput(r, &reqPayload)
+	if err != nil {
+		h.logger.Printf("HTTP Handler: Rejected submission: %v", err)
+		h.respondError(w, err.Error(), orgErrorStatusCode(err))
+		return
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		input.IdempotencyKey = key
+	}
+
+	// 4. Call Service layer processing logic
+	result, err := h.svc.SubmitLog(r.Context(), input)
+	if err != nil {
+		h.logger.Printf("HTTP Handler: Service layer processing failed: %v", err)
+		h.respondServiceError(w, err, errorStatusCode(err))
+		return
+	}
+
+	// 5. Log processing metrics
+	// duration := time.Since(start)
+	// h.logger.Printf("HTTP Handler: Processed log submission in %v, request_id: %s", duration, result.RequestID)
+
+	// 6. Construct and return success response
+	// HTTP 200 OK once attestation has finished, HTTP 202 Accepted otherwise
+	respPayload := resultPayload(result)
+	if result.IdempotentReplay {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	statusCode := http.StatusAccepted
+	if result.Status == string(store.StatusCompleted) || result.Status == string(store.StatusFailed) {
+		statusCode = http.StatusOK
+	}
+
+	h.respondJSON(w, respPayload, statusCode)
+}
+
+// SubmitLogsBatch handles POST /v1/logs/batch requests.
+// Every entry gets its own result; a bad entry does not fail the call.
+func (h *LogHandler) SubmitLogsBatch(w http.ResponseWriter, r *http.Request) {
+	if !h.validateSubmitRequest(w, r) {
 		return
 	}
 
 	// 1. Parse request body JSON
 	var reqPayload struct {
-		LogContent        string `json:"log_content"`
-		ClientLogHash     string `json:"client_log_hash,omitempty"`
-		ClientSourceOrgID string `json:"client_source_org_id,omitempty"`
-		ClientTimestamp   string `json:"client_timestamp,omitempty"`
+		Entries []logPayload `json:"entries"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&reqPayload); err != nil {
-		h.logger.Printf("HTTP Handler: Failed to parse JSON request: %v", err)
+		h.logger.Printf("HTTP Handler: Failed to parse JSON batch request: %v", err)
 		h.respondError(w, "Bad Request: Invalid JSON format", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
-	// 2. Validate required fields
-	if reqPayload.LogContent == "" {
-		h.respondError(w, "log_content is required", http.StatusBadRequest)
+	// 2. Construct Service layer inputs; an entry for another org rejects the whole batch
+	inputs := make([]*core.LogInput, len(reqPayload.Entries))
+	for i := range reqPayload.Entries {
+		input, err := h.toLogInput(r, &reqPayload.Entries[i])
+		if err != nil {
+			h.logger.Printf("HTTP Handler: Rejected batch entry %d: %v", i, err)
+			h.respondError(w, fmt.Sprintf("entry %d: %v", i, err), orgErrorStatusCode(err))
+			return
+		}
+		inputs[i] = input
+	}
+
+	// 3. Call Service layer processing logic
+	items, err := h.svc.SubmitLogs(r.Context(), inputs)
+	if err != nil {
+		h.logger.Printf("HTTP Handler: Batch submission rejected: %v", err)
+		statusCode := errorStatusCode(err)
+		if errors.Is(err, core.ErrInvalidBatch) {
+			statusCode = http.StatusBadRequest
+		}
+		h.respondServiceError(w, err, statusCode)
 		return
 	}
 
-	// 2.5. Get source_org_id from header (set by API Gateway) or from payload
-	sourceOrgID := r.Header.Get("X-Client-Org-ID")
-	if sourceOrgID == "" {
-		sourceOrgID = reqPayload.ClientSourceOrgID
+	// 4. Construct per-entry results
+	results := make([]map[string]interface{}, len(items))
+	accepted := 0
+	for i, item := range items {
+		if item.Err != nil {
+			results[i] = map[string]interface{}{
+				"index":       i,
+				"error":       item.Err.Error(),
+				"status_code": errorStatusCode(item.Err),
+			}
+			if fields := fieldErrors(item.Err); fields != nil {
+				results[i]["field_errors"] = fields
+			}
+			continue
+		}
+		results[i] = resultPayload(item.Result)
+		results[i]["index"] = i
+		accepted++
+	}
+
+	h.respondJSON(w, map[string]interface{}{
+		"results":  results,
+		"accepted": accepted,
+		"rejected": len(items) - accepted,
+	}, http.StatusOK)
+}
+
+// validateSubmitRequest checks method, Content-Type and size of a submission request,
+// writing the error response and returning false if the request is rejected
+func (h *LogHandler) validateSubmitRequest(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		h.respondError(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+
+	// Content-Type validation
+	if r.Header.Get("Content-Type") != "application/json" {
+		h.respondError(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return false
+	}
+
+	// Request size limit
+	if r.ContentLength > 10*1024*1024 { // 10MB limit
+		h.respondError(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return false
+	}
+
+	return true
+}
+
+// toLogInput converts a request payload to the Service layer input. The org is the one the
+// ingress authenticated; the payload may only repeat it.
+func (h *LogHandler) toLogInput(r *http.Request, payload *logPayload) (*core.LogInput, error) {
+	authCtx := auth.GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.OrgID == "" {
+		return nil, errUnauthenticated
+	}
+	sourceOrgID := authCtx.OrgID
+	if payload.ClientSourceOrgID != "" && payload.ClientSourceOrgID != sourceOrgID {
+		return nil, fmt.Errorf("%w: '%s' is not '%s'", errOrgMismatch, payload.ClientSourceOrgID, sourceOrgID)
 	}
 
-	// 3. Construct Service layer input
 	input := &core.LogInput{
-		LogContent:        reqPayload.LogContent,
-		ClientLogHash:     reqPayload.ClientLogHash,
+		LogContent:        payload.LogContent,
+		LogJSON:           payload.LogJSON,
+		ClientLogHash:     payload.ClientLogHash,
 		ClientSourceOrgID: sourceOrgID,
+		AckLevel:          core.AckLevel(payload.AckLevel),
+		AckTimeout:        time.Duration(payload.AckTimeoutMs) * time.Millisecond,
+		IdempotencyKey:    payload.IdempotencyKey,
+		HashAlgorithm:     payload.HashAlgorithm,
+		AttestationMode:   payload.AttestationMode,
+		Category:          payload.Category,
+		Signature:         payload.Signature,
+		SignatureKeyID:    payload.SignatureKeyID,
 	}
 
 	// Parse optional timestamp
-	if reqPayload.ClientTimestamp != "" {
-		if ts, err := time.Parse(time.RFC3339Nano, reqPayload.ClientTimestamp); err == nil {
+	if payload.ClientTimestamp != "" {
+		if ts, err := time.Parse(time.RFC3339Nano, payload.ClientTimestamp); err == nil {
 			input.ClientTimestamp = &ts
 		} else {
 			h.logger.Printf("HTTP Handler: Invalid client_timestamp format: %v", err)
@@ -86,36 +245,93 @@ func (h *LogHandler) SubmitLog(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// 4. Call Service layer processing logic
-	result, err := h.svc.SubmitLog(r.Context(), input)
-	if err != nil {
-		h.logger.Printf("HTTP Handler: Service layer processing failed: %v", err)
-
-		// Map service errors to appropriate HTTP status codes
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "log_content cannot be empty" {
-			statusCode = http.StatusBadRequest
-		} else if matched, _ := regexp.MatchString(`client provided hash .* does not match`, err.Error()); matched {
-			statusCode = http.StatusBadRequest
-		}
+	return input, nil
+}
 
-		h.respondError(w, err.Error(), statusCode)
-		return
+// orgErrorStatusCode maps the errors of the org check to HTTP status codes
+func orgErrorStatusCode(err error) int {
+	if errors.Is(err, errOrgMismatch) {
+		return http.StatusForbidden
 	}
+	return http.StatusUnauthorized
+}
 
-	// 5. Log processing metrics
-	// duration := time.Since(start)
-	// h.logger.Printf("HTTP Handler: Processed log submission in %v, request_id: %s", duration, result.RequestID)
+// errorStatusCode maps service errors to appropriate HTTP status codes
+func errorStatusCode(err error) int {
+	statusCode := http.StatusInternalServerError
+	if errors.Is(err, core.ErrEmptyLogContent) || errors.Is(err, core.ErrHashMismatch) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, core.ErrInvalidAckLevel) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, core.ErrInvalidIdempotencyKey) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, core.ErrClockSkew) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, core.ErrInvalidAttestationMode) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, core.ErrInvalidLogJSON) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, schema.ErrInvalidCategory) || errors.Is(err, schema.ErrValidation) || errors.Is(err, core.ErrUnknownCategory) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, hashing.ErrUnsupportedAlgorithm) || errors.Is(err, hashing.ErrInvalidHash) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, signing.ErrInvalidSignature) || errors.Is(err, signing.ErrInvalidKeyID) ||
+		errors.Is(err, core.ErrUnknownSigningKey) || errors.Is(err, core.ErrSignatureRequired) {
+		statusCode = http.StatusBadRequest
+	} else if errors.Is(err, core.ErrIdempotencyConflict) {
+		statusCode = http.StatusConflict
+	} else if errors.Is(err, core.ErrBufferFull) {
+		statusCode = http.StatusTooManyRequests
+	} else if errors.Is(err, core.ErrNotDurable) || errors.Is(err, core.ErrBlobStore) {
+		statusCode = http.StatusServiceUnavailable
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		statusCode = http.StatusGatewayTimeout
+	}
+	return statusCode
+}
 
-	// 6. Construct and return success response (HTTP 202 Accepted)
-	respPayload := map[string]interface{}{
+// resultPayload builds the JSON response fields for a successful submission
+func resultPayload(result *core.LogResult) map[string]interface{} {
+	payload := map[string]interface{}{
 		"request_id":                result.RequestID,
 		"server_log_hash":           result.ServerLogHash,
 		"server_received_timestamp": result.ServerReceivedTimestamp.Format(time.RFC3339Nano),
-		"status":                    "ACCEPTED",
+		"status":                    result.Status,
+	}
+	if result.IdempotentReplay {
+		payload["idempotent_replay"] = true
+	}
+	if result.ClientTimestampFlagged {
+		payload["client_timestamp_flagged"] = true
+	}
+	if result.AttestationMode != "" {
+		payload["attestation_mode"] = result.AttestationMode
+	}
+	if result.ContentFormat != "" {
+		payload["content_format"] = result.ContentFormat
+	}
+	if result.Category != "" {
+		payload["category"] = result.Category
+		payload["schema_version"] = result.SchemaVersion
+	}
+	if result.ContentLocator != "" {
+		payload["content_locator"] = result.ContentLocator
+	}
+	if result.SignatureKeyID != "" {
+		payload["signature_key_id"] = result.SignatureKeyID
+		payload["signature_algorithm"] = result.SignatureAlgorithm
+		payload["signature_key_fingerprint"] = result.SignatureKeyFingerprint
+	}
+
+	switch result.Status {
+	case string(store.StatusCompleted):
+		payload["tx_hash"] = result.TxHash
+		payload["block_height"] = result.BlockHeight
+	case string(store.StatusFailed):
+		payload["error_message"] = result.ErrorMessage
 	}
 
-	h.respondJSON(w, respPayload, http.StatusAccepted)
+	return payload
 }
 
 // HealthCheck handles GET /health requests
@@ -146,6 +362,10 @@ func (h *LogHandler) Metrics(w http.ResponseWriter, r *http.Request) {
 		"timestamp": time.Now().Unix(),
 		"service":   "api-gateway",
 		"version":   "1.0.0",
+		"queue":     h.svc.QueueStats(),
+	}
+	if spoolStats := h.svc.SpoolStats(); spoolStats != nil {
+		resp["spool"] = spoolStats
 	}
 
 	h.respondJSON(w, resp, http.StatusOK)
@@ -162,6 +382,34 @@ func (h *LogHandler) respondJSON(w http.ResponseWriter, data interface{}, status
 	}
 }
 
+// respondServiceError sends the error response for a Service layer error,
+// telling throttled clients when to retry
+func (h *LogHandler) respondServiceError(w http.ResponseWriter, err error, statusCode int) {
+	if statusCode == http.StatusTooManyRequests {
+		retryAfter := int(math.Ceil(h.svc.RetryAfter().Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	}
+	errorResp := map[string]interface{}{
+		"error":   err.Error(),
+		"status":  statusCode,
+		"message": http.StatusText(statusCode),
+	}
+	if fields := fieldErrors(err); fields != nil {
+		errorResp["field_errors"] = fields
+	}
+
+	h.respondJSON(w, errorResp, statusCode)
+}
+
+// fieldErrors returns the failed fields of a schema validation error, or nil for other errors
+func fieldErrors(err error) []schema.FieldError {
+	var validationErr *schema.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Fields
+	}
+	return nil
+}
+
 // respondError sends error response
 func (h *LogHandler) respondError(w http.ResponseWriter, message string, statusCode int) {
 	errorResp := map[string]interface{}{
@@ -0,0 +1,243 @@
+package kafkasource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"tlng/config"
+	core "tlng/ingestion/service/core"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Mapping errors; the message is rejected and its offset committed
+var (
+	errNoContent        = errors.New("message has no content")
+	errNoOrg            = errors.New("message has no source org")
+	errUnmappedOrg      = errors.New("org is not in org_map")
+	errInvalidValue     = errors.New("message value is not JSON")
+	errInvalidTimestamp = errors.New("invalid timestamp")
+)
+
+// message is a Kafka message with its value decoded on first use by a JSON path
+type message struct {
+	kafka.Message
+
+	decoded bool
+	doc     any
+	docErr  error
+}
+
+// toInput maps a message to the log it is submitted as. Every input carries an idempotency key
+// naming the record, so a redelivered message returns its original submission.
+func toInput(cfg config.KafkaSourceConfig, msg kafka.Message) (*core.LogInput, error) {
+	m := &message{Message: msg}
+	input := &core.LogInput{
+		Category:       cfg.Category,
+		AckLevel:       core.AckDurable,
+		IdempotencyKey: idempotencyKey(cfg.Name, msg),
+	}
+
+	// 1. Content
+	content, err := m.content(cfg.Content, cfg.Format)
+	if err != nil {
+		return nil, fmt.Errorf("content: %w", err)
+	}
+	if cfg.Format == config.KafkaFormatJSON {
+		input.LogJSON = content
+	} else {
+		input.LogContent = string(content)
+	}
+
+	// 2. Org
+	if input.ClientSourceOrgID, err = m.org(cfg); err != nil {
+		return nil, err
+	}
+
+	// 3. Client timestamp
+	if input.ClientTimestamp, err = m.timestamp(cfg.Timestamp, cfg.TimestampFormat); err != nil {
+		return nil, fmt.Errorf("timestamp: %w", err)
+	}
+	return input, nil
+}
+
+// idempotencyKey identifies a record within the org it is submitted for. The source name is part
+// of it, so the same topic, partition and offset on another cluster is a different record.
+func idempotencyKey(source string, msg kafka.Message) string {
+	topicID := sha256.Sum256([]byte(source + "\x00" + msg.Topic))
+	return fmt.Sprintf("kafka:%s:%d:%d", hex.EncodeToString(topicID[:16]), msg.Partition, msg.Offset)
+}
+
+// content selects the log content. A JSON field is kept as JSON for the json format.
+func (m *message) content(selector, format string) ([]byte, error) {
+	source, path := config.ParseKafkaSelector(selector)
+	if format == config.KafkaFormatJSON && source == config.KafkaSelectValue && path != "" {
+		field, ok, err := m.field(path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: no field '%s'", errNoContent, path)
+		}
+		return json.Marshal(field)
+	}
+
+	content, ok, err := m.text(selector)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || content == "" {
+		return nil, fmt.Errorf("%w: '%s' is empty", errNoContent, selector)
+	}
+	return []byte(content), nil
+}
+
+// org selects the source org: the selected value, translated through org_map if configured,
+// or default_org when the message has none
+func (m *message) org(cfg config.KafkaSourceConfig) (string, error) {
+	if cfg.Org != "" {
+		value, ok, err := m.text(cfg.Org)
+		if err != nil {
+			return "", fmt.Errorf("org: %w", err)
+		}
+		if ok && value != "" {
+			if len(cfg.OrgMap) == 0 {
+				return value, nil
+			}
+			if org, mapped := cfg.OrgMap[value]; mapped {
+				return org, nil
+			}
+			return "", fmt.Errorf("%w: %s='%s'", errUnmappedOrg, cfg.Org, value)
+		}
+	}
+	if cfg.DefaultOrg == "" {
+		return "", fmt.Errorf("%w: '%s' is empty", errNoOrg, cfg.Org)
+	}
+	return cfg.DefaultOrg, nil
+}
+
+// timestamp selects the client timestamp; nil if the message has none
+func (m *message) timestamp(selector, format string) (*time.Time, error) {
+	switch selector {
+	case config.KafkaSelectNone:
+		return nil, nil
+	case config.KafkaSelectRecord:
+		if m.Time.IsZero() {
+			return nil, nil
+		}
+		ts := m.Time.UTC()
+		return &ts, nil
+	}
+
+	value, ok, err := m.text(selector)
+	if err != nil || !ok || value == "" {
+		return nil, err
+	}
+	ts, err := parseTimestamp(value, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s' is not %s", errInvalidTimestamp, value, format)
+	}
+	ts = ts.UTC()
+	return &ts, nil
+}
+
+// parseTimestamp parses a timestamp in one of the configured formats. Unix seconds may have a fraction.
+func parseTimestamp(value, format string) (time.Time, error) {
+	switch format {
+	case config.KafkaTimestampUnix:
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+			return time.Time{}, errInvalidTimestamp
+		}
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(math.Round(fraction*1e9))), nil
+	case config.KafkaTimestampUnixMs:
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(ms), nil
+	case config.KafkaTimestampUnixNs:
+		ns, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, ns), nil
+	default:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+}
+
+// text returns the selected part of the message as text. JSON fields that are not strings are
+// returned as JSON; a missing header or field, or a JSON null, is not found.
+func (m *message) text(selector string) (string, bool, error) {
+	source, arg := config.ParseKafkaSelector(selector)
+	switch source {
+	case config.KafkaSelectKey:
+		return string(m.Key), m.Key != nil, nil
+	case config.KafkaSelectHeader:
+		for _, header := range m.Headers {
+			if header.Key == arg {
+				return string(header.Value), true, nil
+			}
+		}
+		return "", false, nil
+	case config.KafkaSelectValue:
+		if arg == "" {
+			return string(m.Value), m.Value != nil, nil
+		}
+		field, ok, err := m.field(arg)
+		if err != nil || !ok {
+			return "", false, err
+		}
+		if s, isString := field.(string); isString {
+			return s, true, nil
+		}
+		encoded, err := json.Marshal(field)
+		if err != nil {
+			return "", false, err
+		}
+		return string(encoded), true, nil
+	}
+	return "", false, fmt.Errorf("invalid selector '%s'", selector)
+}
+
+// field returns the value at a dotted path into the JSON value; numeric segments index arrays
+func (m *message) field(path string) (any, bool, error) {
+	if !m.decoded {
+		m.decoded = true
+		decoder := json.NewDecoder(bytes.NewReader(m.Value))
+		decoder.UseNumber() // Numbers keep their exact text
+		if err := decoder.Decode(&m.doc); err != nil {
+			m.docErr = fmt.Errorf("%w: %v", errInvalidValue, err)
+		}
+	}
+	if m.docErr != nil {
+		return nil, false, m.docErr
+	}
+
+	current := m.doc
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			current = node[segment]
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false, nil
+			}
+			current = node[i]
+		default:
+			return nil, false, nil
+		}
+	}
+	return current, current != nil, nil
+}
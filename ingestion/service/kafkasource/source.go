@@ -0,0 +1,244 @@
+// Package kafkasource consumes external Kafka topics and submits their messages to the core
+// service, committing offsets only once the submitted logs are durable.
+package kafkasource
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tlng/config"
+	core "tlng/ingestion/service/core"
+	"tlng/internal/messaging/consumer"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Retry delays of a batch with entries that are not durable yet
+const (
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// Stats are the source counters
+type Stats struct {
+	Received  uint64 // Messages fetched from the topic
+	Submitted uint64 // Messages durable in the pipeline
+	Rejected  uint64 // Messages the mapping or the core service rejected; committed without a log
+	Retried   uint64 // Submissions repeated after a full queue or a failed write
+}
+
+// Source consumes one external topic. Messages are fetched in batches, submitted with durable
+// acks and committed once every message of the batch is durable or rejected. A batch that is
+// interrupted is fetched again after a restart; the idempotency keys deduplicate it.
+type Source struct {
+	svc    *core.Service
+	cfg    config.KafkaSourceConfig
+	logger *log.Logger
+	reader *kafka.Reader
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	received  atomic.Uint64
+	submitted atomic.Uint64
+	rejected  atomic.Uint64
+	retried   atomic.Uint64
+}
+
+// NewSource creates a Kafka source; call Start to begin consuming
+func NewSource(svc *core.Service, cfg config.KafkaSourceConfig, logger *log.Logger) (*Source, error) {
+	reader, err := consumer.NewReader(cfg.Consumer, logger)
+	if err != nil {
+		return nil, fmt.Errorf("kafka source '%s': %w", cfg.Name, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Source{
+		svc:    svc,
+		cfg:    cfg,
+		logger: logger,
+		reader: reader,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Start consumes the topic in the background
+func (s *Source) Start() {
+	s.wg.Add(1)
+	go s.run()
+	s.logger.Printf("Kafka Source '%s': Consuming topic %s from %v as group %s", s.cfg.Name, s.cfg.Consumer.Topic, s.cfg.Consumer.Brokers, s.cfg.Consumer.GroupID)
+}
+
+// Close stops consuming, waits for the batch in flight and closes the reader.
+// Offsets of a batch that was not durable yet are left uncommitted.
+func (s *Source) Close() {
+	s.cancel()
+	s.wg.Wait()
+	if err := s.reader.Close(); err != nil {
+		s.logger.Printf("Kafka Source '%s': Failed to close reader: %v", s.cfg.Name, err)
+	}
+
+	stats := s.Stats()
+	s.logger.Printf("Kafka Source '%s': Stopped (received %d, submitted %d, rejected %d, retried %d)",
+		s.cfg.Name, stats.Received, stats.Submitted, stats.Rejected, stats.Retried)
+}
+
+// Stats returns the source counters
+func (s *Source) Stats() Stats {
+	return Stats{
+		Received:  s.received.Load(),
+		Submitted: s.submitted.Load(),
+		Rejected:  s.rejected.Load(),
+		Retried:   s.retried.Load(),
+	}
+}
+
+func (s *Source) run() {
+	defer s.wg.Done()
+	for {
+		// 1. Fetch a batch
+		batch, err := s.fetchBatch()
+		if len(batch) == 0 {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.logger.Printf("Kafka Source '%s': Fetch failed: %v", s.cfg.Name, err)
+			if !s.sleep(time.Second) {
+				return
+			}
+			continue
+		}
+		s.received.Add(uint64(len(batch)))
+
+		// 2. Submit until every message is durable or rejected
+		if !s.submit(batch) {
+			return
+		}
+
+		// 3. Commit, also while closing; a failed commit only means the batch is fetched
+		// and deduplicated again
+		commitCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := s.reader.CommitMessages(commitCtx, batch...); err != nil {
+			s.logger.Printf("Kafka Source '%s': Failed to commit %d offsets: %v", s.cfg.Name, len(batch), err)
+		}
+		cancel()
+	}
+}
+
+// fetchBatch blocks for a first message, then collects more until the batch is full
+// or batch_timeout has passed
+func (s *Source) fetchBatch() ([]kafka.Message, error) {
+	msg, err := s.reader.FetchMessage(s.ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := []kafka.Message{msg}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.cfg.BatchTimeout)
+	defer cancel()
+	for len(batch) < s.cfg.BatchSize {
+		msg, err := s.reader.FetchMessage(ctx)
+		if err != nil {
+			break
+		}
+		batch = append(batch, msg)
+	}
+	return batch, nil
+}
+
+// submit maps and submits a batch, retrying the entries that are not durable yet with backoff.
+// It returns false if the source was closed before the whole batch was done.
+func (s *Source) submit(batch []kafka.Message) bool {
+	// 1. Map messages; mapping errors are final
+	var pending []*core.LogInput
+	var offsets []kafka.Message
+	for _, msg := range batch {
+		input, err := toInput(s.cfg, msg)
+		if err != nil {
+			s.reject(msg, err)
+			continue
+		}
+		pending = append(pending, input)
+		offsets = append(offsets, msg)
+	}
+
+	// 2. Submit until nothing is pending
+	delay := minRetryDelay
+	for len(pending) > 0 {
+		// SubmitLog rewrites its input, so every attempt gets a fresh copy
+		inputs := make([]*core.LogInput, len(pending))
+		for i, input := range pending {
+			attempt := *input
+			inputs[i] = &attempt
+		}
+
+		items, err := s.svc.SubmitLogs(s.ctx, inputs)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return false
+			}
+			// The whole batch did not fit in the queue
+			s.retried.Add(uint64(len(pending)))
+			if !s.sleep(max(s.svc.RetryAfter(), minRetryDelay)) {
+				return false
+			}
+			continue
+		}
+
+		var retryInputs []*core.LogInput
+		var retryOffsets []kafka.Message
+		var lastErr error
+		for i, item := range items {
+			switch {
+			case item.Err == nil && item.Result.Status == core.ResultStatusDurable:
+				s.submitted.Add(1)
+			case item.Err != nil && core.IsRejected(item.Err):
+				s.reject(offsets[i], item.Err)
+			default:
+				// Not durable yet: a full queue, a failed write, or a replay of a
+				// submission that is still in flight
+				if item.Err != nil {
+					lastErr = item.Err
+				}
+				retryInputs = append(retryInputs, pending[i])
+				retryOffsets = append(retryOffsets, offsets[i])
+			}
+		}
+		if s.ctx.Err() != nil {
+			return false
+		}
+		pending, offsets = retryInputs, retryOffsets
+		if len(pending) == 0 {
+			break
+		}
+
+		s.retried.Add(uint64(len(pending)))
+		if lastErr != nil {
+			s.logger.Printf("Kafka Source '%s': Retrying %d messages in %v: %v", s.cfg.Name, len(pending), delay, lastErr)
+		}
+		if !s.sleep(delay) {
+			return false
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+	return true
+}
+
+func (s *Source) reject(msg kafka.Message, err error) {
+	s.rejected.Add(1)
+	s.logger.Printf("Kafka Source '%s': Rejected message at %s/%d offset %d: %v", s.cfg.Name, msg.Topic, msg.Partition, msg.Offset, err)
+}
+
+// sleep waits for d; false if the source was closed first
+func (s *Source) sleep(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,147 @@
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// maxSafeInteger is the largest integer a JSON number keeps exactly once canonicalized (RFC 8785)
+const maxSafeInteger = 1<<53 - 1
+
+// Event is the structured log submitted for every LogRecord. Resource and scope are repeated
+// in every event, so each attested record carries its full context.
+type Event struct {
+	Body              any            `json:"body,omitempty"`
+	EventName         string         `json:"event_name,omitempty"`
+	SeverityText      string         `json:"severity_text,omitempty"`
+	SeverityNumber    int32          `json:"severity_number,omitempty"`
+	Attributes        map[string]any `json:"attributes,omitempty"`
+	Resource          map[string]any `json:"resource,omitempty"`
+	Scope             *Scope         `json:"scope,omitempty"`
+	TraceID           string         `json:"trace_id,omitempty"` // Hex, as in W3C trace context
+	SpanID            string         `json:"span_id,omitempty"`
+	Flags             uint32         `json:"flags,omitempty"`
+	Timestamp         string         `json:"timestamp,omitempty"`
+	ObservedTimestamp string         `json:"observed_timestamp,omitempty"`
+}
+
+// Scope is the instrumentation scope of a record
+type Scope struct {
+	Name       string         `json:"name,omitempty"`
+	Version    string         `json:"version,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// newEvent converts a LogRecord with its resource and scope attributes
+func newEvent(record *logspb.LogRecord, resource map[string]any, scope *Scope) *Event {
+	event := &Event{
+		Body:           anyValue(record.GetBody()),
+		EventName:      record.GetEventName(),
+		SeverityText:   record.GetSeverityText(),
+		SeverityNumber: int32(record.GetSeverityNumber()),
+		Attributes:     attributes(record.GetAttributes()),
+		Resource:       resource,
+		Scope:          scope,
+		Flags:          record.GetFlags(),
+	}
+	if id := record.GetTraceId(); len(id) > 0 {
+		event.TraceID = hex.EncodeToString(id)
+	}
+	if id := record.GetSpanId(); len(id) > 0 {
+		event.SpanID = hex.EncodeToString(id)
+	}
+	if ts := unixNano(record.GetTimeUnixNano()); ts != nil {
+		event.Timestamp = ts.Format(time.RFC3339Nano)
+	}
+	if ts := unixNano(record.GetObservedTimeUnixNano()); ts != nil {
+		event.ObservedTimestamp = ts.Format(time.RFC3339Nano)
+	}
+	return event
+}
+
+// newScope converts an instrumentation scope; nil if it is empty
+func newScope(scope *commonpb.InstrumentationScope) *Scope {
+	if scope.GetName() == "" && scope.GetVersion() == "" && len(scope.GetAttributes()) == 0 {
+		return nil
+	}
+	return &Scope{Name: scope.GetName(), Version: scope.GetVersion(), Attributes: attributes(scope.GetAttributes())}
+}
+
+// recordTimestamp is the client timestamp of a record: when the event occurred, or when it was
+// observed if the source did not record that
+func recordTimestamp(record *logspb.LogRecord) *time.Time {
+	if ts := unixNano(record.GetTimeUnixNano()); ts != nil {
+		return ts
+	}
+	return unixNano(record.GetObservedTimeUnixNano())
+}
+
+func unixNano(ns uint64) *time.Time {
+	if ns == 0 || ns > math.MaxInt64 {
+		return nil
+	}
+	ts := time.Unix(0, int64(ns)).UTC()
+	return &ts
+}
+
+// attributes converts key-value pairs to a JSON object; a repeated key keeps its last value
+func attributes(kvs []*commonpb.KeyValue) map[string]any {
+	if len(kvs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(kvs))
+	for _, kv := range kvs {
+		m[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+	return m
+}
+
+// anyValue converts an AnyValue to its JSON form. Values JSON cannot hold exactly follow the
+// OTLP/JSON encoding: integers beyond 2^53 become decimal strings, bytes become base64, and
+// non-finite doubles become "NaN", "Infinity" or "-Infinity".
+func anyValue(v *commonpb.AnyValue) any {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return value.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return value.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		if value.IntValue > maxSafeInteger || value.IntValue < -maxSafeInteger {
+			return strconv.FormatInt(value.IntValue, 10)
+		}
+		return value.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		switch d := value.DoubleValue; {
+		case math.IsNaN(d):
+			return "NaN"
+		case math.IsInf(d, 1):
+			return "Infinity"
+		case math.IsInf(d, -1):
+			return "-Infinity"
+		default:
+			return d
+		}
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(value.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, len(value.ArrayValue.GetValues()))
+		for i, item := range value.ArrayValue.GetValues() {
+			values[i] = anyValue(item)
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		m := attributes(value.KvlistValue.GetValues())
+		if m == nil {
+			m = map[string]any{}
+		}
+		return m
+	default:
+		return nil
+	}
+}
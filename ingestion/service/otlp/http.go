@@ -0,0 +1,199 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+
+	core "tlng/ingestion/service/core"
+	"tlng/internal/auth"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLP/HTTP content types
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// maxRequestBytes limits the decompressed body of an export, as for the other submission APIs
+const maxRequestBytes = 10 * 1024 * 1024
+
+// ServeHTTP implements OTLP/HTTP logs export. Requests and responses are binary protobuf or
+// JSON; errors carry a google.rpc.Status in the request's encoding. The caller's org is the
+// one the ingress authenticated.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// Errors are encoded like the request, in protobuf unless it was JSON
+	requestType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	contentType := contentTypeProtobuf
+	if requestType == contentTypeJSON {
+		contentType = contentTypeJSON
+	}
+	if req.Method != http.MethodPost {
+		r.respondError(w, contentType, http.StatusMethodNotAllowed, codes.Unimplemented, "method must be POST")
+		return
+	}
+	if requestType != contentTypeJSON && requestType != contentTypeProtobuf {
+		r.respondError(w, contentType, http.StatusUnsupportedMediaType, codes.InvalidArgument,
+			fmt.Sprintf("Content-Type must be %s or %s", contentTypeProtobuf, contentTypeJSON))
+		return
+	}
+
+	// 1. Read and decode the body
+	body, err := readBody(req)
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			statusCode = http.StatusRequestEntityTooLarge
+		}
+		r.respondError(w, contentType, statusCode, codes.InvalidArgument, err.Error())
+		return
+	}
+	exportReq := &collogspb.ExportLogsServiceRequest{}
+	if contentType == contentTypeJSON {
+		err = unmarshalJSON(body, exportReq)
+	} else {
+		err = proto.Unmarshal(body, exportReq)
+	}
+	if err != nil {
+		r.respondError(w, contentType, http.StatusBadRequest, codes.InvalidArgument, fmt.Sprintf("invalid export request: %v", err))
+		return
+	}
+
+	// 2. Submit for the authenticated org
+	callerOrg := ""
+	if authCtx := auth.GetAuthContext(req.Context()); authCtx != nil {
+		callerOrg = authCtx.OrgID
+	}
+	resp, err := r.export(req.Context(), exportReq, callerOrg)
+	if err != nil {
+		if errors.Is(err, errRetry) {
+			// A full queue is throttling; other transient failures are an unavailable backend
+			statusCode := http.StatusServiceUnavailable
+			if errors.Is(err, core.ErrBufferFull) {
+				statusCode = http.StatusTooManyRequests
+			}
+			retryAfter := int(math.Ceil(r.svc.RetryAfter().Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			r.respondError(w, contentType, statusCode, codes.Unavailable, err.Error())
+			return
+		}
+		r.respondError(w, contentType, http.StatusInternalServerError, codes.Internal, err.Error())
+		return
+	}
+
+	// 3. Respond in the request's encoding
+	r.respond(w, contentType, http.StatusOK, resp)
+}
+
+// readBody reads the body, decompressing gzip
+func readBody(req *http.Request) ([]byte, error) {
+	var reader io.Reader = req.Body
+	switch req.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding '%s'", req.Header.Get("Content-Encoding"))
+	}
+	return io.ReadAll(http.MaxBytesReader(nil, io.NopCloser(reader), maxRequestBytes))
+}
+
+// unmarshalJSON decodes the OTLP/JSON encoding, which differs from the standard protobuf JSON
+// mapping in one respect: trace and span IDs are hex, not base64
+func unmarshalJSON(body []byte, req *collogspb.ExportLogsServiceRequest) error {
+	var doc map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return err
+	}
+	for _, resourceLogs := range objects(doc, "resourceLogs", "resource_logs") {
+		for _, scopeLogs := range objects(resourceLogs, "scopeLogs", "scope_logs") {
+			for _, record := range objects(scopeLogs, "logRecords", "log_records") {
+				for _, key := range []string{"traceId", "trace_id", "spanId", "span_id"} {
+					if err := hexToBase64(record, key); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(converted, req)
+}
+
+// objects returns the JSON objects in the array under either key
+func objects(doc map[string]any, keys ...string) []map[string]any {
+	var result []map[string]any
+	for _, key := range keys {
+		items, _ := doc[key].([]any)
+		for _, item := range items {
+			if obj, ok := item.(map[string]any); ok {
+				result = append(result, obj)
+			}
+		}
+	}
+	return result
+}
+
+// hexToBase64 re-encodes a hex ID field for protojson
+func hexToBase64(doc map[string]any, key string) error {
+	value, ok := doc[key].(string)
+	if !ok || value == "" {
+		return nil
+	}
+	id, err := hex.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("%s must be hex: %w", key, err)
+	}
+	doc[key] = base64.StdEncoding.EncodeToString(id)
+	return nil
+}
+
+// respondError sends a google.rpc.Status, as OTLP/HTTP requires for failed exports
+func (r *Receiver) respondError(w http.ResponseWriter, contentType string, statusCode int, code codes.Code, message string) {
+	r.logger.Printf("OTLP Receiver: HTTP export failed with %d: %s", statusCode, message)
+	r.respond(w, contentType, statusCode, status.New(code, message).Proto())
+}
+
+func (r *Receiver) respond(w http.ResponseWriter, contentType string, statusCode int, msg proto.Message) {
+	var body []byte
+	var err error
+	if contentType == contentTypeJSON {
+		body, err = protojson.Marshal(msg)
+	} else {
+		body, err = proto.Marshal(msg)
+	}
+	if err != nil {
+		r.logger.Printf("OTLP Receiver: Failed to encode response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
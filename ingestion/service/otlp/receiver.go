@@ -0,0 +1,167 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"tlng/config"
+	core "tlng/ingestion/service/core"
+	grpchandler "tlng/ingestion/service/grpc"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errRetry fails an export in which a record could not be submitted for a transient reason,
+// such as a full queue or a failed write; the exporter retries the whole export
+var errRetry = errors.New("export could not be submitted, retry later")
+
+// Errors of the org resolution; they reject the records of one resource
+var (
+	errNoOrg          = errors.New("no source org")
+	errUnmappedOrg    = errors.New("org attribute value is not in attribute_orgs")
+	errOrgMismatch    = errors.New("org does not match the authenticated org")
+	errInvalidOrgAttr = errors.New("org attribute must be a non-empty string")
+)
+
+// Receiver turns OTLP log exports into structured submissions to the core service, one per
+// LogRecord. It serves OTLP/gRPC as the LogsService and OTLP/HTTP through ServeHTTP.
+type Receiver struct {
+	collogspb.UnimplementedLogsServiceServer
+
+	svc    *core.Service
+	cfg    config.OTLPConfig
+	logger *log.Logger
+}
+
+// NewReceiver creates an OTLP logs receiver
+func NewReceiver(svc *core.Service, cfg config.OTLPConfig, logger *log.Logger) *Receiver {
+	return &Receiver{svc: svc, cfg: cfg, logger: logger}
+}
+
+// Export implements the OTLP/gRPC LogsService. The caller's org comes from the gRPC Authenticator.
+func (r *Receiver) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	resp, err := r.export(ctx, req, grpchandler.OrgFromContext(ctx))
+	if err != nil {
+		if errors.Is(err, errRetry) {
+			// Exporters retry UNAVAILABLE; RESOURCE_EXHAUSTED needs RetryInfo to be retried
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to process log export: %v", err)
+	}
+	return resp, nil
+}
+
+// export submits every record of a request and reports the rejected ones as a partial success.
+// callerOrg is the authenticated org, or "" if the caller was not authenticated.
+// If a record fails for a transient reason, an error wrapping errRetry is returned instead: the
+// exporter then retries the whole export, including records already submitted.
+func (r *Receiver) export(ctx context.Context, req *collogspb.ExportLogsServiceRequest, callerOrg string) (*collogspb.ExportLogsServiceResponse, error) {
+	// 1. Convert records; records of a resource without a valid org are rejected right away
+	var inputs []*core.LogInput
+	var positions []string
+	var total, rejected int64
+	var firstErr string
+	reject := func(position string, err error) {
+		rejected++
+		if firstErr == "" {
+			firstErr = fmt.Sprintf("%s: %v", position, err)
+		}
+	}
+
+	for i, resourceLogs := range req.GetResourceLogs() {
+		resource := attributes(resourceLogs.GetResource().GetAttributes())
+		orgID, orgErr := r.resolveOrg(resource, callerOrg)
+
+		for j, scopeLogs := range resourceLogs.GetScopeLogs() {
+			scope := newScope(scopeLogs.GetScope())
+			for k, record := range scopeLogs.GetLogRecords() {
+				total++
+				position := fmt.Sprintf("resource_logs[%d].scope_logs[%d].log_records[%d]", i, j, k)
+				if orgErr != nil {
+					reject(position, orgErr)
+					continue
+				}
+				event, err := json.Marshal(newEvent(record, resource, scope))
+				if err != nil {
+					reject(position, err)
+					continue
+				}
+				inputs = append(inputs, &core.LogInput{
+					LogJSON:           event,
+					ClientSourceOrgID: orgID,
+					ClientTimestamp:   recordTimestamp(record),
+					Category:          r.cfg.Category,
+				})
+				positions = append(positions, position)
+			}
+		}
+	}
+
+	// 2. Submit the remaining records in batches the service accepts; each succeeds or fails on its own.
+	// Only invalid records are rejected; any other failure fails the export.
+	batchSize := r.svc.MaxBatchEntries()
+	for start := 0; start < len(inputs); start += batchSize {
+		items, err := r.svc.SubmitLogs(ctx, inputs[start:min(start+batchSize, len(inputs))])
+		if err != nil {
+			r.logger.Printf("OTLP Receiver: Export of %d records failed at record %d: %v", len(inputs), start, err)
+			return nil, fmt.Errorf("%w: %w", errRetry, err)
+		}
+		for i, item := range items {
+			switch {
+			case item.Err == nil:
+			case core.IsRejected(item.Err):
+				reject(positions[start+i], item.Err)
+			default:
+				r.logger.Printf("OTLP Receiver: Export of %d records failed at %s: %v", len(inputs), positions[start+i], item.Err)
+				return nil, fmt.Errorf("%w: %s: %w", errRetry, positions[start+i], item.Err)
+			}
+		}
+	}
+
+	// 3. Report rejected records; the exporter must not retry them
+	resp := &collogspb.ExportLogsServiceResponse{}
+	if rejected > 0 {
+		r.logger.Printf("OTLP Receiver: Rejected %d of %d records, first %s", rejected, total, firstErr)
+		resp.PartialSuccess = &collogspb.ExportLogsPartialSuccess{
+			RejectedLogRecords: rejected,
+			ErrorMessage:       firstErr,
+		}
+	}
+	return resp, nil
+}
+
+// resolveOrg returns the org of a resource: its org attribute, translated through attribute_orgs
+// if configured, else the caller's org, else default_org. An authenticated caller may only
+// submit for its own org.
+func (r *Receiver) resolveOrg(resource map[string]any, callerOrg string) (string, error) {
+	orgID := ""
+	if value, ok := resource[r.cfg.OrgAttribute]; ok {
+		s, isString := value.(string)
+		if !isString || s == "" {
+			return "", fmt.Errorf("%w: '%s'", errInvalidOrgAttr, r.cfg.OrgAttribute)
+		}
+		orgID = s
+		if len(r.cfg.AttributeOrgs) > 0 {
+			if orgID, ok = r.cfg.AttributeOrgs[s]; !ok {
+				return "", fmt.Errorf("%w: %s='%s'", errUnmappedOrg, r.cfg.OrgAttribute, s)
+			}
+		}
+	}
+
+	switch {
+	case callerOrg != "" && orgID == "":
+		return callerOrg, nil
+	case callerOrg != "" && orgID != callerOrg:
+		return "", fmt.Errorf("%w: '%s' is not '%s'", errOrgMismatch, orgID, callerOrg)
+	case orgID == "" && r.cfg.DefaultOrg == "":
+		return "", fmt.Errorf("%w: resource has no '%s' attribute", errNoOrg, r.cfg.OrgAttribute)
+	case orgID == "":
+		return r.cfg.DefaultOrg, nil
+	}
+	return orgID, nil
+}
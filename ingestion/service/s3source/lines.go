@@ -0,0 +1,109 @@
+package s3source
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// gzipMagic starts every gzip member (RFC 1952)
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// errCorruptObject marks objects that cannot be read however often they are retried
+var errCorruptObject = errors.New("corrupt object")
+
+// line is one line of an object, without its line terminator
+type line struct {
+	number  int64 // 1-based
+	offset  int64 // Byte offset of the line in the decompressed object
+	text    []byte
+	tooLong bool // text was dropped because the line exceeds the maximum size
+}
+
+// lineReader splits a decompressed object into lines and tracks their positions
+type lineReader struct {
+	r       *bufio.Reader
+	maxSize int
+	number  int64 // Number of the last line returned
+	offset  int64 // Byte offset after the last line returned
+}
+
+// countingReader counts the bytes read from the object, to tell a truncated gzip object
+// from a connection that broke off
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// newLineReader reads the lines of an object, decompressing it if it starts like gzip,
+// whatever its key or content type
+func newLineReader(object io.Reader, maxSize int) (*lineReader, error) {
+	buffered := bufio.NewReader(object)
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	var content io.Reader = buffered
+	if bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(buffered) // Concatenated members are read as one stream
+		if err != nil {
+			return nil, err
+		}
+		content = gz
+	}
+	return &lineReader{r: bufio.NewReaderSize(content, 64*1024), maxSize: maxSize}, nil
+}
+
+// next returns the next line. The last line may lack a line terminator. io.EOF is returned
+// once the object ends.
+func (l *lineReader) next() (line, error) {
+	result := line{number: l.number + 1, offset: l.offset}
+	var consumed int64
+	for {
+		chunk, err := l.r.ReadSlice('\n')
+		consumed += int64(len(chunk))
+		if !result.tooLong {
+			result.text = append(result.text, chunk...)
+			if len(result.text) > l.maxSize+2 { // +2 for CRLF
+				result.text, result.tooLong = nil, true
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && !(errors.Is(err, io.EOF) && consumed > 0) {
+			return line{}, err
+		}
+		break
+	}
+
+	l.number++
+	l.offset += consumed
+	result.text = bytes.TrimSuffix(bytes.TrimSuffix(result.text, []byte("\n")), []byte("\r"))
+	if len(result.text) > l.maxSize {
+		result.text, result.tooLong = nil, true
+	}
+	return result, nil
+}
+
+// classifyReadError marks errors of objects that are corrupt rather than unreachable: broken
+// gzip data, or a gzip stream that ends early although the whole object was read
+func classifyReadError(err error, read, size int64) error {
+	var corrupt flate.CorruptInputError
+	if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) || errors.As(err, &corrupt) ||
+		(errors.Is(err, io.ErrUnexpectedEOF) && read >= size) {
+		return fmt.Errorf("%w: %v", errCorruptObject, err)
+	}
+	return err
+}
@@ -0,0 +1,262 @@
+package s3source
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// gzipped compresses each member separately and concatenates them
+func gzipped(t *testing.T, members ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, member := range members {
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write([]byte(member)); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// readLines reads every line of an object as "<number>@<offset>:<text>", or "<number>@<offset>: too long"
+func readLines(t *testing.T, object []byte, maxSize int) []string {
+	t.Helper()
+	r, err := newLineReader(bytes.NewReader(object), maxSize)
+	if err != nil {
+		t.Fatalf("newLineReader: %v", err)
+	}
+	var lines []string
+	for {
+		l, err := r.next()
+		if errors.Is(err, io.EOF) {
+			return lines
+		}
+		if err != nil {
+			t.Fatalf("next: unexpected error %v", err)
+		}
+		if l.tooLong {
+			lines = append(lines, fmt.Sprintf("%d@%d: too long", l.number, l.offset))
+		} else {
+			lines = append(lines, fmt.Sprintf("%d@%d:%s", l.number, l.offset, l.text))
+		}
+	}
+}
+
+func TestLineReader(t *testing.T) {
+	long := strings.Repeat("x", 100*1024) // Longer than the reader's 64 KiB buffer
+	tests := []struct {
+		name    string
+		object  string
+		maxSize int
+		want    []string
+	}{
+		{
+			name:   "empty object",
+			object: "",
+			want:   nil,
+		},
+		{
+			name:   "lf",
+			object: "a\nbb\n",
+			want:   []string{"1@0:a", "2@2:bb"},
+		},
+		{
+			name:   "crlf",
+			object: "a\r\nbb\r\n",
+			want:   []string{"1@0:a", "2@3:bb"},
+		},
+		{
+			name:   "cr inside a line is kept",
+			object: "a\rb\n",
+			want:   []string{"1@0:a\rb"},
+		},
+		{
+			name:   "unterminated last line",
+			object: "a\nbb",
+			want:   []string{"1@0:a", "2@2:bb"},
+		},
+		{
+			name:   "unterminated last line with cr",
+			object: "a\nbb\r",
+			want:   []string{"1@0:a", "2@2:bb"},
+		},
+		{
+			name:   "blank lines",
+			object: "\n\r\na\n",
+			want:   []string{"1@0:", "2@1:", "3@3:a"},
+		},
+		{
+			name:    "at max_line_size with crlf",
+			object:  "abcd\r\nef\n",
+			maxSize: 4,
+			want:    []string{"1@0:abcd", "2@6:ef"},
+		},
+		{
+			name:    "at max_line_size+2 without crlf",
+			object:  "abcdef\nef\n",
+			maxSize: 4,
+			want:    []string{"1@0: too long", "2@7:ef"},
+		},
+		{
+			name:    "one over max_line_size",
+			object:  "abcde\nef\n",
+			maxSize: 4,
+			want:    []string{"1@0: too long", "2@6:ef"},
+		},
+		{
+			name:    "extra cr counts towards the size",
+			object:  "abcd\r\r\n",
+			maxSize: 4,
+			want:    []string{"1@0: too long"},
+		},
+		{
+			name:    "unterminated last line over max_line_size",
+			object:  "ab\nabcde",
+			maxSize: 4,
+			want:    []string{"1@0:ab", "2@3: too long"},
+		},
+		{
+			name:    "line longer than the read buffer",
+			object:  long + "\nab\n",
+			maxSize: 200 * 1024,
+			want:    []string{"1@0:" + long, fmt.Sprintf("2@%d:ab", len(long)+1)},
+		},
+		{
+			name:    "too long line longer than the read buffer",
+			object:  long + "\nab\n",
+			maxSize: 1024,
+			want:    []string{"1@0: too long", fmt.Sprintf("2@%d:ab", len(long)+1)},
+		},
+		{
+			name:   "starts with half the gzip magic",
+			object: "\x1fa\n",
+			want:   []string{"1@0:\x1fa"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = 1024
+			}
+			got := readLines(t, []byte(tt.object), maxSize)
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineReaderGzip(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []string
+		want    []string
+	}{
+		{
+			name:    "single member",
+			members: []string{"a\r\nbb\n"},
+			want:    []string{"1@0:a", "2@3:bb"},
+		},
+		{
+			name:    "concatenated members",
+			members: []string{"a\n", "bb\n", "ccc"},
+			want:    []string{"1@0:a", "2@2:bb", "3@5:ccc"},
+		},
+		{
+			name:    "line split across members",
+			members: []string{"a\nb", "b\n"},
+			want:    []string{"1@0:a", "2@2:bb"},
+		},
+		{
+			name:    "empty member",
+			members: []string{"a\n", "", "b\n"},
+			want:    []string{"1@0:a", "2@2:b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readLines(t, gzipped(t, tt.members...), 1024)
+			if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// readAll reads an object to its end and returns the error that stopped it, classified
+func readAll(object []byte) error {
+	counting := &countingReader{r: bytes.NewReader(object)}
+	r, err := newLineReader(counting, 1024)
+	if err != nil {
+		return classifyReadError(err, counting.n, int64(len(object)))
+	}
+	for {
+		if _, err := r.next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return classifyReadError(err, counting.n, int64(len(object)))
+		}
+	}
+}
+
+func TestLineReaderCorruptGzip(t *testing.T) {
+	object := gzipped(t, strings.Repeat("user alice logged in\n", 100))
+
+	truncated := object[:len(object)-10]
+	if err := readAll(truncated); !errors.Is(err, errCorruptObject) {
+		t.Errorf("truncated object: %v, want %v", err, errCorruptObject)
+	}
+
+	badChecksum := bytes.Clone(object)
+	badChecksum[len(badChecksum)-8] ^= 0xff // CRC-32 of the trailer
+	if err := readAll(badChecksum); !errors.Is(err, errCorruptObject) {
+		t.Errorf("bad checksum: %v, want %v", err, errCorruptObject)
+	}
+
+	badHeader := bytes.Clone(object)
+	badHeader[2] = 0x00 // Compression method other than deflate
+	if err := readAll(badHeader); !errors.Is(err, errCorruptObject) {
+		t.Errorf("bad header: %v, want %v", err, errCorruptObject)
+	}
+}
+
+func TestClassifyReadError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		read    int64
+		size    int64
+		corrupt bool
+	}{
+		{"gzip header", gzip.ErrHeader, 10, 100, true},
+		{"gzip checksum", gzip.ErrChecksum, 100, 100, true},
+		{"corrupt deflate", flate.CorruptInputError(42), 50, 100, true},
+		{"wrapped corrupt deflate", fmt.Errorf("read: %w", flate.CorruptInputError(42)), 50, 100, true},
+		{"unexpected eof after the whole object", io.ErrUnexpectedEOF, 100, 100, true},
+		{"unexpected eof before the end", io.ErrUnexpectedEOF, 60, 100, false},
+		{"connection reset", errors.New("connection reset by peer"), 60, 100, false},
+		{"closed pipe", io.ErrClosedPipe, 100, 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyReadError(tt.err, tt.read, tt.size)
+			if got := errors.Is(err, errCorruptObject); got != tt.corrupt {
+				t.Errorf("classifyReadError(%v) = %v, corrupt %v, want %v", tt.err, err, got, tt.corrupt)
+			}
+			if !tt.corrupt && err != tt.err {
+				t.Errorf("classifyReadError(%v) = %v, want the error unchanged", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,521 @@
+// Package s3source polls S3-compatible buckets for newline-delimited log files and submits
+// every line to the core service, checkpointing each object so it is attested once.
+package s3source
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"tlng/config"
+	core "tlng/ingestion/service/core"
+	"tlng/storage/store"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// listPageSize is the number of listed objects whose checkpoints are loaded at once
+const listPageSize = 1000
+
+// Retry delays of lines that are not durable yet
+const (
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// Line errors; the line is rejected and counted in the checkpoint
+var (
+	errLineTooLong  = errors.New("line exceeds max_line_size")
+	errInvalidUTF8  = errors.New("line is not valid UTF-8")
+	errInvalidJSON  = errors.New("line is not a JSON document")
+	errObjectMoved  = errors.New("object changed or was deleted since it was listed")
+	errLeaseExpired = errors.New("checkpoint lease lost")
+)
+
+// Event is the structured log submitted for every line. The object and position are kept next
+// to the line, so they are hashed and attested with it.
+type Event struct {
+	Message string          `json:"message,omitempty"` // The line, format text
+	Record  json.RawMessage `json:"record,omitempty"`  // The line, format json
+	Bucket  string          `json:"s3_bucket"`
+	Key     string          `json:"s3_key"`
+	ETag    string          `json:"s3_etag"`
+	Line    int64           `json:"line"`   // 1-based line number
+	Offset  int64           `json:"offset"` // Byte offset of the line in the decompressed object
+}
+
+// Stats are the poller counters
+type Stats struct {
+	Objects  uint64 // Objects completed
+	Failed   uint64 // Objects that could not be read
+	Records  uint64 // Lines durable in the pipeline
+	Rejected uint64 // Lines that were too long, malformed or rejected by the core service
+}
+
+// keyOrg is a key_orgs entry
+type keyOrg struct {
+	prefix string
+	org    string
+}
+
+// pendingLine is a line waiting in a batch
+type pendingLine struct {
+	line  line
+	input *core.LogInput
+}
+
+// Poller lists a bucket prefix every poll_interval and ingests the objects that have no
+// completed checkpoint yet, one at a time. Several ingestion replicas may poll the same
+// bucket; the checkpoint lease keeps each object with one of them.
+type Poller struct {
+	svc     *core.Service
+	store   store.Store
+	client  *minio.Client
+	cfg     config.S3SourceConfig
+	logger  *log.Logger
+	owner   string   // Lease owner of this poller
+	keyOrgs []keyOrg // Longest prefix first
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	skipped  map[string]bool // Unmapped objects that were logged
+	objects  atomic.Uint64
+	failed   atomic.Uint64
+	records  atomic.Uint64
+	rejected atomic.Uint64
+}
+
+// NewPoller creates an S3 source; call Start to begin polling. Without static keys the
+// credentials are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+func NewPoller(svc *core.Service, st store.Store, cfg config.S3SourceConfig, logger *log.Logger) (*Poller, error) {
+	creds := credentials.NewEnvAWS()
+	if cfg.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 source '%s': failed to create S3 client for '%s': %w", cfg.Name, cfg.Endpoint, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Poller{
+		svc:     svc,
+		store:   st,
+		client:  client,
+		cfg:     cfg,
+		logger:  logger,
+		owner:   uuid.New().String(),
+		ctx:     ctx,
+		cancel:  cancel,
+		skipped: make(map[string]bool),
+	}
+	for prefix, org := range cfg.KeyOrgs {
+		p.keyOrgs = append(p.keyOrgs, keyOrg{prefix: prefix, org: org})
+	}
+	sort.Slice(p.keyOrgs, func(i, j int) bool {
+		a, b := p.keyOrgs[i].prefix, p.keyOrgs[j].prefix
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return p, nil
+}
+
+// Start polls the bucket in the background
+func (p *Poller) Start() {
+	p.wg.Add(1)
+	go p.run()
+	p.logger.Printf("S3 Source '%s': Polling s3://%s/%s at %s every %v", p.cfg.Name, p.cfg.Bucket, p.cfg.Prefix, p.cfg.Endpoint, p.cfg.PollInterval)
+}
+
+// Close stops polling and waits for the object in progress, which resumes from its
+// checkpoint on the next start
+func (p *Poller) Close() {
+	p.cancel()
+	p.wg.Wait()
+
+	stats := p.Stats()
+	p.logger.Printf("S3 Source '%s': Stopped (objects %d, failed %d, records %d, rejected %d)",
+		p.cfg.Name, stats.Objects, stats.Failed, stats.Records, stats.Rejected)
+}
+
+// Stats returns the poller counters
+func (p *Poller) Stats() Stats {
+	return Stats{
+		Objects:  p.objects.Load(),
+		Failed:   p.failed.Load(),
+		Records:  p.records.Load(),
+		Rejected: p.rejected.Load(),
+	}
+}
+
+func (p *Poller) run() {
+	defer p.wg.Done()
+	for {
+		if err := p.poll(); err != nil && p.ctx.Err() == nil {
+			p.logger.Printf("S3 Source '%s': Poll failed: %v", p.cfg.Name, err)
+		}
+		if !p.sleep(p.cfg.PollInterval) {
+			return
+		}
+	}
+}
+
+// poll lists the prefix and ingests every object without a completed or failed checkpoint
+func (p *Poller) poll() error {
+	// 1. List objects, keeping those still to be ingested
+	var pending []minio.ObjectInfo
+	var page []minio.ObjectInfo
+	flush := func() error {
+		todo, err := p.filterIngested(page)
+		pending = append(pending, todo...)
+		page = page[:0]
+		return err
+	}
+
+	listCtx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	for object := range p.client.ListObjects(listCtx, p.cfg.Bucket, minio.ListObjectsOptions{Prefix: p.cfg.Prefix, Recursive: true}) {
+		if object.Err != nil {
+			return fmt.Errorf("failed to list s3://%s/%s: %w", p.cfg.Bucket, p.cfg.Prefix, object.Err)
+		}
+		if strings.HasSuffix(object.Key, "/") {
+			continue // Folder marker
+		}
+		page = append(page, object)
+		if len(page) == listPageSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// 2. Ingest them one at a time, in key order
+	for _, object := range pending {
+		if p.ctx.Err() != nil {
+			return nil
+		}
+		if err := p.ingest(object); err != nil && p.ctx.Err() == nil {
+			p.logger.Printf("S3 Source '%s': Object '%s' not finished, retrying on the next poll: %v", p.cfg.Name, object.Key, err)
+		}
+	}
+	return nil
+}
+
+// filterIngested drops objects whose current version is completed or failed, and objects
+// without an org
+func (p *Poller) filterIngested(objects []minio.ObjectInfo) ([]minio.ObjectInfo, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(objects))
+	for i, object := range objects {
+		keys[i] = object.Key
+	}
+	checkpoints, err := p.store.ListS3Checkpoints(p.ctx, p.cfg.Name, p.cfg.Bucket, keys)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Status != store.S3CheckpointInProgress {
+			done[checkpoint.ObjectKey+"\x00"+checkpoint.ETag] = true
+		}
+	}
+
+	var todo []minio.ObjectInfo
+	for _, object := range objects {
+		if done[object.Key+"\x00"+object.ETag] {
+			continue
+		}
+		if p.resolveOrg(object.Key) == "" {
+			if !p.skipped[object.Key] {
+				p.skipped[object.Key] = true
+				p.logger.Printf("S3 Source '%s': Skipping '%s', no org is mapped to its key", p.cfg.Name, object.Key)
+			}
+			continue
+		}
+		todo = append(todo, object)
+	}
+	return todo, nil
+}
+
+// resolveOrg maps an object key below the prefix to its org: the longest matching key_orgs
+// prefix, then default_org. "" means the object is unmapped.
+func (p *Poller) resolveOrg(key string) string {
+	relative := strings.TrimPrefix(key, p.cfg.Prefix)
+	for _, entry := range p.keyOrgs {
+		if strings.HasPrefix(relative, entry.prefix) {
+			return entry.org
+		}
+	}
+	return p.cfg.DefaultOrg
+}
+
+// ingest claims an object and submits its lines from the checkpoint on. The checkpoint moves
+// after every durable batch; the object is completed at its end, or failed if it is corrupt.
+// An error leaves the object in progress for the next poll.
+func (p *Poller) ingest(object minio.ObjectInfo) error {
+	// 1. Claim the object, resuming from its checkpoint
+	checkpoint, err := p.store.ClaimS3Checkpoint(p.ctx, &store.S3Checkpoint{
+		SourceName: p.cfg.Name,
+		Bucket:     p.cfg.Bucket,
+		ObjectKey:  object.Key,
+		ETag:       object.ETag,
+		Size:       object.Size,
+		LeaseOwner: p.owner,
+	}, p.cfg.LeaseDuration)
+	if err != nil || checkpoint == nil {
+		return err // nil: completed or leased by another replica meanwhile
+	}
+	if checkpoint.NextLine > 1 {
+		p.logger.Printf("S3 Source '%s': Resuming '%s' at line %d", p.cfg.Name, object.Key, checkpoint.NextLine)
+	}
+
+	// 2. Read the version that was listed
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetMatchETag(object.ETag); err != nil {
+		return err
+	}
+	reader, err := p.client.GetObject(p.ctx, p.cfg.Bucket, object.Key, opts)
+	if err != nil {
+		return p.fail(checkpoint, classifyObjectError(err))
+	}
+	defer reader.Close()
+	counter := &countingReader{r: reader}
+	lines, err := newLineReader(counter, p.cfg.MaxLineSize)
+	if err != nil {
+		return p.fail(checkpoint, classifyReadError(classifyObjectError(err), counter.n, object.Size))
+	}
+
+	// 3. Submit lines in batches, moving the checkpoint after each
+	orgID := p.resolveOrg(object.Key)
+	var batch []pendingLine
+	for {
+		next, err := lines.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return p.fail(checkpoint, classifyReadError(classifyObjectError(err), counter.n, object.Size))
+		}
+		if next.number < checkpoint.NextLine {
+			continue // Durable before a restart
+		}
+		if len(bytes.TrimSpace(next.text)) == 0 && !next.tooLong {
+			if len(batch) == 0 {
+				// Nothing pending, so the blank line can be passed right away
+				checkpoint.NextLine, checkpoint.NextOffset = next.number+1, lines.offset
+			}
+			continue
+		}
+		batch = append(batch, pendingLine{line: next})
+		if len(batch) == p.cfg.BatchSize {
+			if err := p.submitBatch(checkpoint, object, orgID, batch, lines.offset); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := p.submitBatch(checkpoint, object, orgID, batch, lines.offset); err != nil {
+			return err
+		}
+	}
+
+	// 4. Complete the object
+	checkpoint.Status = store.S3CheckpointCompleted
+	checkpoint.NextLine, checkpoint.NextOffset = lines.number+1, lines.offset
+	checkpoint.LastError = nil
+	if err := p.store.UpdateS3Checkpoint(p.ctx, checkpoint, p.cfg.LeaseDuration); err != nil {
+		return err
+	}
+	p.objects.Add(1)
+	p.logger.Printf("S3 Source '%s': Completed '%s' (%d records, %d rejected)", p.cfg.Name, object.Key, checkpoint.Records, checkpoint.Rejected)
+	return nil
+}
+
+// submitBatch submits a batch until every line is durable or rejected and moves the checkpoint
+// past it. endOffset is the byte offset after the last line of the batch.
+func (p *Poller) submitBatch(checkpoint *store.S3Checkpoint, object minio.ObjectInfo, orgID string, batch []pendingLine, endOffset int64) error {
+	// 1. Build the events; lines that cannot be submitted are rejected right away
+	var pending []pendingLine
+	for _, item := range batch {
+		input, err := p.toInput(object, orgID, item.line)
+		if err != nil {
+			p.reject(checkpoint, item.line, err)
+			continue
+		}
+		pending = append(pending, pendingLine{line: item.line, input: input})
+	}
+
+	// 2. Submit until nothing is pending
+	delay := minRetryDelay
+	for len(pending) > 0 {
+		// SubmitLog rewrites its input, so every attempt gets a fresh copy
+		inputs := make([]*core.LogInput, len(pending))
+		for i, item := range pending {
+			attempt := *item.input
+			inputs[i] = &attempt
+		}
+
+		items, err := p.svc.SubmitLogs(p.ctx, inputs)
+		if err != nil {
+			if p.ctx.Err() != nil {
+				return p.ctx.Err()
+			}
+			if !p.sleep(max(p.svc.RetryAfter(), minRetryDelay)) {
+				return p.ctx.Err()
+			}
+			continue
+		}
+
+		var retry []pendingLine
+		var lastErr error
+		for i, item := range items {
+			switch {
+			case item.Err == nil && item.Result.Status == core.ResultStatusDurable:
+				checkpoint.Records++
+				p.records.Add(1)
+			case item.Err != nil && core.IsRejected(item.Err):
+				p.reject(checkpoint, pending[i].line, item.Err)
+			default:
+				// Not durable yet: a full queue, a failed write, or a replay of a
+				// submission that is still in flight
+				if item.Err != nil {
+					lastErr = item.Err
+				}
+				retry = append(retry, pending[i])
+			}
+		}
+		if p.ctx.Err() != nil {
+			return p.ctx.Err()
+		}
+		pending = retry
+		if len(pending) == 0 {
+			break
+		}
+		if lastErr != nil {
+			p.logger.Printf("S3 Source '%s': Retrying %d lines of '%s' in %v: %v", p.cfg.Name, len(pending), object.Key, delay, lastErr)
+		}
+		if !p.sleep(delay) {
+			return p.ctx.Err()
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+
+	// 3. Move the checkpoint past the batch, renewing the lease
+	checkpoint.NextLine = batch[len(batch)-1].line.number + 1
+	checkpoint.NextOffset = endOffset
+	if err := p.store.UpdateS3Checkpoint(p.ctx, checkpoint, p.cfg.LeaseDuration); err != nil {
+		if errors.Is(err, store.ErrS3LeaseLost) {
+			return fmt.Errorf("%w: %v", errLeaseExpired, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// toInput builds the structured log of a line. The idempotency key names the line, so lines
+// submitted again after a crash, before their checkpoint moved, return their first submission.
+func (p *Poller) toInput(object minio.ObjectInfo, orgID string, l line) (*core.LogInput, error) {
+	if l.tooLong {
+		return nil, fmt.Errorf("%w (%d bytes)", errLineTooLong, p.cfg.MaxLineSize)
+	}
+	event := &Event{
+		Bucket: p.cfg.Bucket,
+		Key:    object.Key,
+		ETag:   object.ETag,
+		Line:   l.number,
+		Offset: l.offset,
+	}
+	if p.cfg.Format == config.S3FormatJSON {
+		if !json.Valid(l.text) {
+			return nil, errInvalidJSON
+		}
+		event.Record = json.RawMessage(l.text)
+	} else {
+		if !utf8.Valid(l.text) {
+			return nil, errInvalidUTF8 // JSON would replace the invalid bytes, so the attested line would differ
+		}
+		event.Message = string(l.text)
+	}
+	logJSON, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	objectID := sha256.Sum256([]byte(p.cfg.Name + "\x00" + p.cfg.Bucket + "\x00" + object.Key + "\x00" + object.ETag))
+	return &core.LogInput{
+		LogJSON:           logJSON,
+		ClientSourceOrgID: orgID,
+		Category:          p.cfg.Category,
+		AckLevel:          core.AckDurable,
+		IdempotencyKey:    fmt.Sprintf("s3:%s:%d", hex.EncodeToString(objectID[:16]), l.number),
+	}, nil
+}
+
+// fail marks a corrupt or vanished object failed, so it is not retried; other errors are returned
+// and the object is retried on the next poll
+func (p *Poller) fail(checkpoint *store.S3Checkpoint, err error) error {
+	if !errors.Is(err, errCorruptObject) && !errors.Is(err, errObjectMoved) {
+		return err
+	}
+	message := err.Error()
+	checkpoint.Status = store.S3CheckpointFailed
+	checkpoint.LastError = &message
+	if updateErr := p.store.UpdateS3Checkpoint(p.ctx, checkpoint, p.cfg.LeaseDuration); updateErr != nil {
+		return updateErr
+	}
+	p.failed.Add(1)
+	p.logger.Printf("S3 Source '%s': Failed '%s' after line %d: %v", p.cfg.Name, checkpoint.ObjectKey, checkpoint.NextLine-1, err)
+	return nil
+}
+
+// classifyObjectError marks responses saying that the listed version is gone
+func classifyObjectError(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case minio.PreconditionFailed, minio.NoSuchKey:
+		return fmt.Errorf("%w: %v", errObjectMoved, err)
+	}
+	return err
+}
+
+func (p *Poller) reject(checkpoint *store.S3Checkpoint, l line, err error) {
+	checkpoint.Rejected++
+	p.rejected.Add(1)
+	p.logger.Printf("S3 Source '%s': Rejected line %d of '%s': %v", p.cfg.Name, l.number, checkpoint.ObjectKey, err)
+}
+
+// sleep waits for d; false if the poller was closed first
+func (p *Poller) sleep(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,114 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxFrameLengthDigits bounds the MSG-LEN of an octet-counted frame
+const maxFrameLengthDigits = 10
+
+// Framing errors. ErrFrameTooLarge skips one message; ErrMalformedFrame ends the connection,
+// because the position of the next frame is unknown.
+var (
+	ErrFrameTooLarge  = errors.New("syslog message exceeds max_message_size")
+	ErrMalformedFrame = errors.New("malformed syslog frame")
+)
+
+// FrameReader splits a TCP or TLS stream into messages (RFC 6587). Each frame is either
+// octet-counted ("MSG-LEN SP MSG", as RFC 5425 requires for TLS) or terminated by LF;
+// senders may mix both, since syslog messages start with '<' and MSG-LEN with a digit.
+type FrameReader struct {
+	r       *bufio.Reader
+	maxSize int
+}
+
+// NewFrameReader creates a FrameReader that rejects messages longer than maxSize
+func NewFrameReader(r io.Reader, maxSize int) *FrameReader {
+	return &FrameReader{r: bufio.NewReader(r), maxSize: maxSize}
+}
+
+// Next returns the next message. It returns io.EOF once the stream ends between frames.
+func (f *FrameReader) Next() ([]byte, error) {
+	// Skip blank lines and whitespace some senders put between frames
+	for {
+		c, err := f.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if c != '\n' && c != '\r' && c != ' ' && c != '\t' && c != 0 {
+			if err := f.r.UnreadByte(); err != nil {
+				return nil, err
+			}
+			if c >= '1' && c <= '9' {
+				return f.readOctetCounted()
+			}
+			return f.readLine()
+		}
+	}
+}
+
+// readOctetCounted reads "MSG-LEN SP MSG"
+func (f *FrameReader) readOctetCounted() ([]byte, error) {
+	digits := make([]byte, 0, maxFrameLengthDigits)
+	for {
+		c, err := f.r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: truncated MSG-LEN: %v", ErrMalformedFrame, err)
+		}
+		if c == ' ' {
+			break
+		}
+		if c < '0' || c > '9' || len(digits) == maxFrameLengthDigits {
+			return nil, fmt.Errorf("%w: invalid MSG-LEN", ErrMalformedFrame)
+		}
+		digits = append(digits, c)
+	}
+	length, err := strconv.Atoi(string(digits))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid MSG-LEN", ErrMalformedFrame)
+	}
+
+	if length > f.maxSize {
+		if _, err := f.r.Discard(length); err != nil {
+			return nil, fmt.Errorf("%w: truncated frame: %v", ErrMalformedFrame, err)
+		}
+		return nil, fmt.Errorf("%w: %d bytes (max %d)", ErrFrameTooLarge, length, f.maxSize)
+	}
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(f.r, frame); err != nil {
+		return nil, fmt.Errorf("%w: truncated frame: %v", ErrMalformedFrame, err)
+	}
+	return frame, nil
+}
+
+// readLine reads up to the next LF. A final line without LF is returned when the stream ends.
+func (f *FrameReader) readLine() ([]byte, error) {
+	var frame []byte
+	tooLarge := false
+	for {
+		chunk, err := f.r.ReadSlice('\n')
+		if !tooLarge {
+			frame = append(frame, chunk...)
+			if len(frame) > f.maxSize+1 { // +1 for the LF
+				frame, tooLarge = nil, true
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && !(errors.Is(err, io.EOF) && (len(frame) > 0 || tooLarge)) {
+			return nil, err
+		}
+		break
+	}
+	// A final line without LF may still be one byte too long
+	if tooLarge || len(bytes.TrimSuffix(frame, []byte("\n"))) > f.maxSize {
+		return nil, fmt.Errorf("%w: line longer than %d bytes", ErrFrameTooLarge, f.maxSize)
+	}
+	return frame, nil
+}
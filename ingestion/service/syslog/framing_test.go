@@ -0,0 +1,161 @@
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// octetCounted frames a message as "MSG-LEN SP MSG"
+func octetCounted(msg string) string {
+	return fmt.Sprintf("%d %s", len(msg), msg)
+}
+
+// readFrames reads every frame of input, writing errors as "error: <sentinel>". It stops at the end
+// of the stream and after a malformed frame, which ends the connection.
+func readFrames(t *testing.T, input string, maxSize int) []string {
+	t.Helper()
+	f := NewFrameReader(strings.NewReader(input), maxSize)
+	var frames []string
+	for {
+		frame, err := f.Next()
+		switch {
+		case errors.Is(err, io.EOF):
+			return frames
+		case errors.Is(err, ErrFrameTooLarge):
+			frames = append(frames, "error: too large")
+		case errors.Is(err, ErrMalformedFrame):
+			return append(frames, "error: malformed")
+		case err != nil:
+			t.Fatalf("Next: unexpected error %v", err)
+		default:
+			frames = append(frames, string(frame))
+		}
+	}
+}
+
+func TestFrameReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		maxSize int
+		want    []string
+	}{
+		{
+			name:  "empty stream",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "octet-counted",
+			input: octetCounted("<13>1 - - - - - - first") + octetCounted("<13>second"),
+			want:  []string{"<13>1 - - - - - - first", "<13>second"},
+		},
+		{
+			name:  "octet-counted message with LF",
+			input: octetCounted("<13>two\nlines"),
+			want:  []string{"<13>two\nlines"},
+		},
+		{
+			name:  "LF-terminated",
+			input: "<13>first\n<13>second\n",
+			want:  []string{"<13>first\n", "<13>second\n"},
+		},
+		{
+			name:  "final line without LF",
+			input: "<13>first\n<13>last",
+			want:  []string{"<13>first\n", "<13>last"},
+		},
+		{
+			name:  "mixed framing",
+			input: octetCounted("<13>counted") + "<13>line\n" + octetCounted("<13>counted again") + "plain text\r\n",
+			want:  []string{"<13>counted", "<13>line\n", "<13>counted again", "plain text\r\n"},
+		},
+		{
+			name:  "whitespace between frames",
+			input: "\r\n\n \t\x00" + octetCounted("<13>a") + "\n\n<13>b\n  ",
+			want:  []string{"<13>a", "<13>b\n"},
+		},
+		{
+			name:    "octet-counted at max size",
+			input:   octetCounted("<13>12345"),
+			maxSize: 9,
+			want:    []string{"<13>12345"},
+		},
+		{
+			name:    "oversized octet-counted frame is skipped",
+			input:   octetCounted("<13>123456") + octetCounted("<13>ok"),
+			maxSize: 9,
+			want:    []string{"error: too large", "<13>ok"},
+		},
+		{
+			name:    "line at max size",
+			input:   "<13>12345\n",
+			maxSize: 9,
+			want:    []string{"<13>12345\n"},
+		},
+		{
+			name:    "oversized line is skipped",
+			input:   "<13>123456\n<13>ok\n",
+			maxSize: 9,
+			want:    []string{"error: too large", "<13>ok\n"},
+		},
+		{
+			name:    "oversized line longer than the read buffer",
+			input:   "<13>" + strings.Repeat("x", 10000) + "\n" + octetCounted("<13>ok"),
+			maxSize: 100,
+			want:    []string{"error: too large", "<13>ok"},
+		},
+		{
+			name:    "oversized final line without LF",
+			input:   "<13>123456",
+			maxSize: 9,
+			want:    []string{"error: too large"},
+		},
+		{
+			name:  "MSG-LEN with a letter",
+			input: "12a <13>message\n",
+			want:  []string{"error: malformed"},
+		},
+		{
+			name:  "MSG-LEN too long",
+			input: "12345678901 <13>message",
+			want:  []string{"error: malformed"},
+		},
+		{
+			name:  "MSG-LEN without space",
+			input: "5",
+			want:  []string{"error: malformed"},
+		},
+		{
+			name:  "MSG-LEN followed by LF",
+			input: "5\n<13>a",
+			want:  []string{"error: malformed"},
+		},
+		{
+			name:  "truncated octet-counted frame",
+			input: octetCounted("<13>ok") + "20 <13>short",
+			want:  []string{"<13>ok", "error: malformed"},
+		},
+		{
+			name:    "truncated oversized frame",
+			input:   "20 <13>short",
+			maxSize: 9,
+			want:    []string{"error: malformed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSize := tt.maxSize
+			if maxSize == 0 {
+				maxSize = 1024
+			}
+			got := readFrames(t, tt.input, maxSize)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("frames = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Syslog formats a message was parsed as
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatRFC3164 = "rfc3164"
+)
+
+// defaultPriority is assumed for RFC 3164 messages without PRI (user.notice, RFC 3164 4.3.3)
+const defaultPriority = 13
+
+// nilValue marks an absent RFC 5424 header field or structured data
+const nilValue = "-"
+
+// utf8BOM may start an RFC 5424 MSG to declare it UTF-8
+const utf8BOM = "\xEF\xBB\xBF"
+
+// ErrMalformedMessage is returned for messages that cannot be parsed
+var ErrMalformedMessage = errors.New("malformed syslog message")
+
+// facilityNames and severityNames are the RFC 5424 keywords, indexed by code
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+var severityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// Message is a parsed syslog message. Absent header fields are empty.
+type Message struct {
+	Format         string
+	Facility       int
+	Severity       int
+	Version        int        // 1 for RFC 5424, 0 for RFC 3164
+	Timestamp      *time.Time // nil if the message carries none
+	Hostname       string
+	AppName        string // RFC 3164 TAG
+	ProcID         string
+	MsgID          string
+	StructuredData map[string]map[string][]string // SD-ID -> PARAM-NAME -> values; a name may repeat
+	Message        string
+}
+
+// FacilityName returns the RFC 5424 keyword of the facility
+func (m *Message) FacilityName() string {
+	return facilityNames[m.Facility]
+}
+
+// SeverityName returns the RFC 5424 keyword of the severity
+func (m *Message) SeverityName() string {
+	return severityNames[m.Severity]
+}
+
+// Parse parses an RFC 5424 or RFC 3164 message. A VERSION after PRI selects RFC 5424; anything
+// else is parsed as RFC 3164, which is loose enough to accept any text. now supplies the year
+// and time zone of RFC 3164 timestamps, which have neither.
+func Parse(data []byte, now time.Time) (*Message, error) {
+	s := strings.TrimRight(string(data), "\r\n\x00")
+
+	priority, rest, hasPriority, err := parsePriority(s)
+	if err != nil {
+		return nil, err
+	}
+	if hasPriority {
+		if version, after, ok := parseVersion(rest); ok {
+			msg, err := parseRFC5424(after, now)
+			if err != nil {
+				return nil, err
+			}
+			msg.Version = version
+			msg.Facility, msg.Severity = priority/8, priority%8
+			return msg, nil
+		}
+	} else {
+		priority = defaultPriority
+	}
+
+	msg := parseRFC3164(rest, now)
+	msg.Facility, msg.Severity = priority/8, priority%8
+	return msg, nil
+}
+
+// parsePriority reads "<PRI>"; hasPriority is false if the message does not start with "<".
+// PRI is 1 to 3 digits without a sign or leading zero, up to 191.
+func parsePriority(s string) (priority int, rest string, hasPriority bool, err error) {
+	if !strings.HasPrefix(s, "<") {
+		return 0, s, false, nil
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return 0, "", false, fmt.Errorf("%w: invalid PRI", ErrMalformedMessage)
+	}
+	digits := s[1:end]
+	if !isDigits(digits) || (len(digits) > 1 && digits[0] == '0') {
+		return 0, "", false, fmt.Errorf("%w: invalid PRI '%s'", ErrMalformedMessage, digits)
+	}
+	priority, err = strconv.Atoi(digits)
+	if err != nil || priority > 191 {
+		return 0, "", false, fmt.Errorf("%w: invalid PRI '%s'", ErrMalformedMessage, digits)
+	}
+	return priority, s[end+1:], true, nil
+}
+
+// parseVersion reads the RFC 5424 VERSION and the space after it
+func parseVersion(s string) (version int, rest string, ok bool) {
+	end := strings.IndexByte(s, ' ')
+	if end < 1 || end > 3 || s[0] == '0' || !isDigits(s[:end]) {
+		return 0, "", false
+	}
+	version, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return 0, "", false
+	}
+	return version, s[end+1:], true
+}
+
+// isDigits reports whether s is made of ASCII digits only; strconv.Atoi also accepts a sign
+func isDigits(s string) bool {
+	return strings.TrimLeft(s, "0123456789") == ""
+}
+
+// parseRFC5424 parses the RFC 5424 header after VERSION:
+// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func parseRFC5424(s string, now time.Time) (*Message, error) {
+	msg := &Message{Format: FormatRFC5424}
+
+	fields := make([]string, 5)
+	for i := range fields {
+		var ok bool
+		fields[i], s, ok = strings.Cut(s, " ")
+		if !ok || fields[i] == "" {
+			return nil, fmt.Errorf("%w: incomplete RFC 5424 header", ErrMalformedMessage)
+		}
+		if fields[i] == nilValue {
+			fields[i] = ""
+		}
+	}
+	if fields[0] != "" {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid TIMESTAMP '%s'", ErrMalformedMessage, fields[0])
+		}
+		msg.Timestamp = &ts
+	}
+	msg.Hostname, msg.AppName, msg.ProcID, msg.MsgID = fields[1], fields[2], fields[3], fields[4]
+
+	if strings.HasPrefix(s, nilValue) {
+		s = s[len(nilValue):]
+	} else {
+		sd, rest, err := parseStructuredData(s)
+		if err != nil {
+			return nil, err
+		}
+		msg.StructuredData, s = sd, rest
+	}
+	switch {
+	case s == "":
+	case s[0] == ' ':
+		msg.Message = strings.TrimPrefix(s[1:], utf8BOM)
+	default:
+		return nil, fmt.Errorf("%w: expected a space after STRUCTURED-DATA", ErrMalformedMessage)
+	}
+	return msg, nil
+}
+
+// parseStructuredData parses one or more SD-ELEMENTs: [SD-ID *(SP PARAM-NAME="PARAM-VALUE")].
+// In values, '"', '\' and ']' are escaped with '\'.
+func parseStructuredData(s string) (map[string]map[string][]string, string, error) {
+	sd := make(map[string]map[string][]string)
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		end := strings.IndexAny(s, " ]")
+		if end < 1 {
+			return nil, "", fmt.Errorf("%w: invalid SD-ID", ErrMalformedMessage)
+		}
+		params := sd[s[:end]]
+		if params == nil {
+			params = make(map[string][]string)
+			sd[s[:end]] = params
+		}
+		s = s[end:]
+
+		for strings.HasPrefix(s, " ") {
+			name, rest, ok := strings.Cut(s[1:], `="`)
+			if !ok || name == "" || strings.ContainsAny(name, ` ]"`) {
+				return nil, "", fmt.Errorf("%w: invalid SD-PARAM", ErrMalformedMessage)
+			}
+			value, rest, err := parseParamValue(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			params[name] = append(params[name], value)
+			s = rest
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, "", fmt.Errorf("%w: unterminated SD-ELEMENT", ErrMalformedMessage)
+		}
+		s = s[1:]
+	}
+	if len(sd) == 0 {
+		return nil, "", fmt.Errorf("%w: invalid STRUCTURED-DATA", ErrMalformedMessage)
+	}
+	return sd, s, nil
+}
+
+// parseParamValue reads a PARAM-VALUE up to its closing quote and unescapes it.
+// A backslash before any other character is kept, as RFC 5424 requires.
+func parseParamValue(s string) (value, rest string, err error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return b.String(), s[i+1:], nil
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0:
+			b.WriteByte(s[i+1])
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("%w: unterminated PARAM-VALUE", ErrMalformedMessage)
+}
+
+// parseRFC3164 parses what follows PRI in a BSD syslog message: TIMESTAMP HOSTNAME TAG[PID]: MSG.
+// Senders vary widely, so nothing is rejected: without a recognizable TIMESTAMP the whole text
+// is the MSG, and without a TAG everything after the HOSTNAME is.
+func parseRFC3164(s string, now time.Time) *Message {
+	msg := &Message{Format: FormatRFC3164}
+
+	ts, rest, ok := parseRFC3164Timestamp(s, now)
+	if !ok {
+		msg.Message = s
+		return msg
+	}
+	msg.Timestamp = &ts
+	s = rest
+
+	// HOSTNAME is optional; a first word that looks like a TAG is not one
+	if word, after, _ := strings.Cut(s, " "); word != "" && !strings.ContainsAny(word, ":[") {
+		msg.Hostname, s = word, after
+	}
+
+	tagEnd := strings.IndexAny(s, ":[ ")
+	if tagEnd > 0 && tagEnd <= 48 && s[tagEnd] != ' ' {
+		tag, after := s[:tagEnd], s[tagEnd:]
+		if strings.HasPrefix(after, "[") {
+			if pid, afterPID, ok := strings.Cut(after[1:], "]"); ok && !strings.Contains(pid, " ") {
+				msg.ProcID, after = pid, afterPID
+			} else {
+				msg.Message = s
+				return msg
+			}
+		}
+		if strings.HasPrefix(after, ":") {
+			msg.AppName = tag
+			s = strings.TrimPrefix(after[1:], " ")
+		}
+	}
+	msg.Message = s
+	return msg
+}
+
+// parseRFC3164Timestamp reads "Mmm dd hh:mm:ss" in now's time zone, choosing the year that puts it
+// closest to now, or an RFC 3339 timestamp as sent by many modern BSD-format senders
+func parseRFC3164Timestamp(s string, now time.Time) (time.Time, string, bool) {
+	if len(s) >= len(time.Stamp) {
+		if ts, err := time.ParseInLocation(time.Stamp, s[:len(time.Stamp)], now.Location()); err == nil {
+			ts = time.Date(now.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, now.Location())
+			if ts.Sub(now) > 31*24*time.Hour {
+				ts = ts.AddDate(-1, 0, 0) // Sent in December, received in January
+			} else if now.Sub(ts) > 334*24*time.Hour {
+				ts = ts.AddDate(1, 0, 0) // Sender clock ahead across the new year
+			}
+			return ts, strings.TrimPrefix(s[len(time.Stamp):], " "), true
+		}
+	}
+	word, rest, _ := strings.Cut(s, " ")
+	if ts, err := time.Parse(time.RFC3339Nano, word); err == nil {
+		return ts, rest, true
+	}
+	return time.Time{}, "", false
+}
@@ -0,0 +1,164 @@
+package syslog
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+	at := func(year int, month time.Month, day, hour, min, sec, nsec int) *time.Time {
+		ts := time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+		return &ts
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		now   time.Time
+		want  *Message
+	}{
+		{
+			name: "RFC 5424 with structured data and BOM",
+			input: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 ` +
+				`[exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"] ` + utf8BOM + "An application event",
+			want: &Message{
+				Format: FormatRFC5424, Facility: 20, Severity: 5, Version: 1,
+				Timestamp: at(2003, time.October, 11, 22, 14, 15, 3000000),
+				Hostname:  "mymachine.example.com", AppName: "evntslog", MsgID: "ID47",
+				StructuredData: map[string]map[string][]string{
+					"exampleSDID@32473": {"iut": {"3"}, "eventSource": {"Application"}, "eventID": {"1011"}},
+				},
+				Message: "An application event",
+			},
+		},
+		{
+			name:  "RFC 5424 with nil values and no MSG",
+			input: "<0>1 - - - - - -\r\n",
+			want:  &Message{Format: FormatRFC5424, Version: 1},
+		},
+		{
+			name:  "RFC 5424 with several SD-ELEMENTs, repeated names and escapes",
+			input: `<191>1 - host app 42 - [a x="1" x="2"][b@1 v="q\"b\\s\]o\n"] msg`,
+			want: &Message{
+				Format: FormatRFC5424, Facility: 23, Severity: 7, Version: 1,
+				Hostname: "host", AppName: "app", ProcID: "42",
+				StructuredData: map[string]map[string][]string{
+					"a":   {"x": {"1", "2"}},
+					"b@1": {"v": {`q"b\s]o\n`}},
+				},
+				Message: "msg",
+			},
+		},
+		{
+			name:  "RFC 3164",
+			input: "<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8\n",
+			want: &Message{
+				Format: FormatRFC3164, Facility: 4, Severity: 2,
+				Timestamp: at(2024, time.October, 11, 22, 14, 15, 0),
+				Hostname:  "mymachine", AppName: "su",
+				Message: "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name:  "RFC 3164 with PID and a single-digit day",
+			input: "<38>Feb  5 17:32:18 host sshd[1234]: Accepted publickey",
+			want: &Message{
+				Format: FormatRFC3164, Facility: 4, Severity: 6,
+				Timestamp: at(2025, time.February, 5, 17, 32, 18, 0),
+				Hostname:  "host", AppName: "sshd", ProcID: "1234",
+				Message: "Accepted publickey",
+			},
+		},
+		{
+			name:  "RFC 3164 sent in December, received in January",
+			input: "<13>Dec 31 23:59:59 host app: bye",
+			now:   time.Date(2025, time.January, 1, 0, 0, 5, 0, time.UTC),
+			want: &Message{
+				Format: FormatRFC3164, Facility: 1, Severity: 5,
+				Timestamp: at(2024, time.December, 31, 23, 59, 59, 0),
+				Hostname:  "host", AppName: "app", Message: "bye",
+			},
+		},
+		{
+			name:  "RFC 3164 with an RFC 3339 timestamp and no hostname",
+			input: "<13>2025-06-15T11:59:00Z app: hello",
+			want: &Message{
+				Format: FormatRFC3164, Facility: 1, Severity: 5,
+				Timestamp: at(2025, time.June, 15, 11, 59, 0, 0),
+				AppName:   "app", Message: "hello",
+			},
+		},
+		{
+			name:  "RFC 3164 without timestamp",
+			input: "<13>just some text",
+			want:  &Message{Format: FormatRFC3164, Facility: 1, Severity: 5, Message: "just some text"},
+		},
+		{
+			name:  "no PRI defaults to user.notice",
+			input: "hello world",
+			want:  &Message{Format: FormatRFC3164, Facility: 1, Severity: 5, Message: "hello world"},
+		},
+		{
+			name:  "PRI 0",
+			input: "<0>kernel panic",
+			want:  &Message{Format: FormatRFC3164, Message: "kernel panic"},
+		},
+		{
+			name:  "signed VERSION is not RFC 5424",
+			input: "<13>+1 - - - - - - msg",
+			want:  &Message{Format: FormatRFC3164, Facility: 1, Severity: 5, Message: "+1 - - - - - - msg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parseNow := tt.now
+			if parseNow.IsZero() {
+				parseNow = now
+			}
+			got, err := Parse([]byte(tt.input), parseNow)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty PRI", "<>msg"},
+		{"unterminated PRI", "<13 msg"},
+		{"PRI with plus sign", "<+1>msg"},
+		{"PRI with minus sign", "<-0>msg"},
+		{"PRI with leading zero", "<01>msg"},
+		{"PRI 00", "<00>msg"},
+		{"PRI above 191", "<192>msg"},
+		{"PRI with four digits", "<1000>msg"},
+		{"PRI with a letter", "<1a>msg"},
+		{"RFC 5424 incomplete header", "<13>1 2003-10-11T22:14:15Z host app"},
+		{"RFC 5424 invalid timestamp", "<13>1 2003-10-11 host app - - - msg"},
+		{"RFC 5424 empty header field", "<13>1 -  app - - - msg"},
+		{"RFC 5424 unterminated SD-ELEMENT", `<13>1 - - - - - [id p="v" msg`},
+		{"RFC 5424 unterminated PARAM-VALUE", `<13>1 - - - - - [id p="v]`},
+		{"RFC 5424 SD-PARAM without value", `<13>1 - - - - - [id p] msg`},
+		{"RFC 5424 empty SD-ID", `<13>1 - - - - - [] msg`},
+		{"RFC 5424 no space after STRUCTURED-DATA", `<13>1 - - - - - [id]msg`},
+		{"RFC 5424 no space after nil STRUCTURED-DATA", `<13>1 - - - - - -msg`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Parse([]byte(tt.input), time.Now())
+			if !errors.Is(err, ErrMalformedMessage) {
+				t.Errorf("Parse = %+v, %v, want ErrMalformedMessage", msg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package syslog
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tlng/config"
+	core "tlng/ingestion/service/core"
+	"tlng/internal/tlsconfig"
+)
+
+// Event is the structured log submitted for every syslog message. The header and structured
+// data are kept next to the message, so they are hashed and attested with it.
+type Event struct {
+	Message        string                         `json:"message"`
+	Format         string                         `json:"syslog_format"` // rfc5424 or rfc3164
+	Facility       string                         `json:"facility"`
+	Severity       string                         `json:"severity"`
+	Timestamp      string                         `json:"timestamp,omitempty"`
+	Hostname       string                         `json:"hostname,omitempty"`
+	AppName        string                         `json:"app_name,omitempty"`
+	ProcID         string                         `json:"proc_id,omitempty"`
+	MsgID          string                         `json:"msg_id,omitempty"`
+	StructuredData map[string]map[string][]string `json:"structured_data,omitempty"`
+	Transport      string                         `json:"transport"`   // udp, tcp or tls
+	RemoteAddr     string                         `json:"remote_addr"` // Sender IP
+	ClientCert     string                         `json:"client_cert,omitempty"`
+}
+
+// Stats are the receiver counters
+type Stats struct {
+	Received  uint64 // Messages read from the listeners
+	Submitted uint64 // Messages accepted by the core service
+	Dropped   uint64 // Messages that were oversized, malformed, unmapped or rejected
+}
+
+// hostnamePattern is a glob of hostname_orgs
+type hostnamePattern struct {
+	pattern string
+	org     string
+}
+
+// sender identifies where a message came from
+type sender struct {
+	transport string
+	ip        string
+	certCN    string // Common Name of a verified client certificate, TLS only
+}
+
+// Server receives syslog messages over UDP, TCP and TLS and submits them to the core service
+type Server struct {
+	svc    *core.Service
+	cfg    config.SyslogConfig
+	logger *log.Logger
+
+	tlsConfig     *tls.Config
+	hostnameOrgs  map[string]string // Exact hostnames
+	hostnameGlobs []hostnamePattern // Longest pattern first
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	closers   []io.Closer           // Listeners and the UDP socket
+	conns     map[net.Conn]struct{} // Open TCP and TLS connections
+	received  atomic.Uint64
+	submitted atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// NewServer creates a syslog receiver; call Start to open the listeners
+func NewServer(svc *core.Service, cfg config.SyslogConfig, logger *log.Logger) (*Server, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		svc:          svc,
+		cfg:          cfg,
+		logger:       logger,
+		hostnameOrgs: make(map[string]string),
+		ctx:          ctx,
+		cancel:       cancel,
+		conns:        make(map[net.Conn]struct{}),
+	}
+
+	if cfg.TLSAddr != "" {
+		tlsConfig, err := tlsconfig.NewServer(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile, cfg.TLS.ClientAuth == config.GrpcClientAuthRequire)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("syslog TLS: %w", err)
+		}
+		s.tlsConfig = tlsConfig
+	}
+
+	for pattern, org := range cfg.HostnameOrgs {
+		if strings.ContainsAny(pattern, `*?[\`) {
+			s.hostnameGlobs = append(s.hostnameGlobs, hostnamePattern{pattern: strings.ToLower(pattern), org: org})
+		} else {
+			s.hostnameOrgs[strings.ToLower(pattern)] = org
+		}
+	}
+	sort.Slice(s.hostnameGlobs, func(i, j int) bool {
+		a, b := s.hostnameGlobs[i].pattern, s.hostnameGlobs[j].pattern
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+
+	return s, nil
+}
+
+// Start opens the configured listeners and serves them in the background
+func (s *Server) Start() error {
+	if s.cfg.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", s.cfg.UDPAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("unable to listen on syslog UDP %s: %w", s.cfg.UDPAddr, err)
+		}
+		s.track(conn)
+		s.wg.Add(1)
+		go s.serveUDP(conn)
+		s.logger.Printf("Syslog: Listening on UDP %s", s.cfg.UDPAddr)
+	}
+	if s.cfg.TCPAddr != "" {
+		lis, err := net.Listen("tcp", s.cfg.TCPAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("unable to listen on syslog TCP %s: %w", s.cfg.TCPAddr, err)
+		}
+		s.track(lis)
+		s.wg.Add(1)
+		go s.serveStream(lis, "tcp")
+		s.logger.Printf("Syslog: Listening on TCP %s", s.cfg.TCPAddr)
+	}
+	if s.cfg.TLSAddr != "" {
+		lis, err := tls.Listen("tcp", s.cfg.TLSAddr, s.tlsConfig)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("unable to listen on syslog TLS %s: %w", s.cfg.TLSAddr, err)
+		}
+		s.track(lis)
+		s.wg.Add(1)
+		go s.serveStream(lis, "tls")
+		s.logger.Printf("Syslog: Listening on TLS %s", s.cfg.TLSAddr)
+	}
+	return nil
+}
+
+// Close stops the listeners, closes open connections and waits for in-flight messages
+func (s *Server) Close() {
+	s.cancel()
+	s.mu.Lock()
+	for _, c := range s.closers {
+		c.Close()
+	}
+	s.closers = nil
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+
+	stats := s.Stats()
+	s.logger.Printf("Syslog: Stopped (received %d, submitted %d, dropped %d)", stats.Received, stats.Submitted, stats.Dropped)
+}
+
+// Stats returns the receiver counters
+func (s *Server) Stats() Stats {
+	return Stats{Received: s.received.Load(), Submitted: s.submitted.Load(), Dropped: s.dropped.Load()}
+}
+
+func (s *Server) track(c io.Closer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closers = append(s.closers, c)
+}
+
+// serveUDP reads one message per datagram. UDP has no backpressure, so messages the core
+// service cannot queue are dropped.
+func (s *Server) serveUDP(conn net.PacketConn) {
+	defer s.wg.Done()
+	buf := make([]byte, s.cfg.MaxMessageSize+1)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if s.ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+				s.logger.Printf("Syslog: UDP read failed: %v", err)
+				continue
+			}
+			return
+		}
+		s.received.Add(1)
+		if n > s.cfg.MaxMessageSize {
+			s.drop(hostOf(addr), fmt.Errorf("%w: datagram truncated at %d bytes", ErrFrameTooLarge, s.cfg.MaxMessageSize))
+			continue
+		}
+		s.handle(buf[:n], sender{transport: "udp", ip: hostOf(addr)}, false)
+	}
+}
+
+// serveStream accepts TCP or TLS connections
+func (s *Server) serveStream(lis net.Listener, transport string) {
+	defer s.wg.Done()
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if s.ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Printf("Syslog: %s accept failed: %v", strings.ToUpper(transport), err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		s.mu.Lock()
+		if s.ctx.Err() != nil {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serveConn(conn, transport)
+	}
+}
+
+// serveConn reads frames until the sender disconnects, stays idle or breaks the framing.
+// Messages are submitted one at a time, so a full queue slows the sender down.
+func (s *Server) serveConn(conn net.Conn, transport string) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	from := sender{transport: transport, ip: hostOf(conn.RemoteAddr())}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn.SetDeadline(time.Now().Add(s.cfg.IdleTimeout))
+		if err := tlsConn.HandshakeContext(s.ctx); err != nil {
+			s.logger.Printf("Syslog: TLS handshake with %s failed: %v", from.ip, err)
+			return
+		}
+		conn.SetDeadline(time.Time{})
+		if chains := tlsConn.ConnectionState().VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			from.certCN = chains[0][0].Subject.CommonName
+		}
+	}
+
+	frames := NewFrameReader(conn, s.cfg.MaxMessageSize)
+	for {
+		conn.SetReadDeadline(time.Now().Add(s.cfg.IdleTimeout))
+		frame, err := frames.Next()
+		if errors.Is(err, ErrFrameTooLarge) {
+			s.received.Add(1)
+			s.drop(from.ip, err)
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && s.ctx.Err() == nil {
+				s.logger.Printf("Syslog: Closing %s connection from %s: %v", strings.ToUpper(transport), from.ip, err)
+			}
+			return
+		}
+		s.received.Add(1)
+		s.handle(frame, from, true)
+	}
+}
+
+// handle parses a message, resolves its org and submits it. With waitForQueue, a full queue
+// is retried until it has room; otherwise the message is dropped.
+func (s *Server) handle(frame []byte, from sender, waitForQueue bool) {
+	msg, err := Parse(frame, time.Now())
+	if err != nil {
+		s.drop(from.ip, err)
+		return
+	}
+	orgID := s.resolveOrg(msg.Hostname, from)
+	if orgID == "" {
+		s.drop(from.ip, fmt.Errorf("no org mapped to hostname '%s' or client certificate '%s'", msg.Hostname, from.certCN))
+		return
+	}
+	event, err := json.Marshal(newEvent(msg, from))
+	if err != nil {
+		s.drop(from.ip, err)
+		return
+	}
+
+	for {
+		// SubmitLog rewrites its input, so every attempt gets a fresh one
+		_, err := s.svc.SubmitLog(s.ctx, &core.LogInput{
+			LogJSON:           event,
+			ClientSourceOrgID: orgID,
+			ClientTimestamp:   msg.Timestamp,
+			Category:          s.cfg.Category,
+		})
+		if err == nil {
+			s.submitted.Add(1)
+			return
+		}
+		if !waitForQueue || !errors.Is(err, core.ErrBufferFull) {
+			s.drop(from.ip, err)
+			return
+		}
+		select {
+		case <-time.After(s.svc.RetryAfter()):
+		case <-s.ctx.Done():
+			s.drop(from.ip, s.ctx.Err())
+			return
+		}
+	}
+}
+
+// resolveOrg maps a sender to its org: a verified client certificate first, then the HOSTNAME
+// (or the sender IP if the message has none), then default_org. "" means the sender is unmapped.
+func (s *Server) resolveOrg(hostname string, from sender) string {
+	if from.certCN != "" {
+		if org, ok := s.cfg.CertOrgs[from.certCN]; ok {
+			return org
+		}
+	}
+	if hostname == "" {
+		hostname = from.ip
+	}
+	hostname = strings.ToLower(hostname)
+	if org, ok := s.hostnameOrgs[hostname]; ok {
+		return org
+	}
+	for _, p := range s.hostnameGlobs {
+		if ok, _ := path.Match(p.pattern, hostname); ok {
+			return p.org
+		}
+	}
+	return s.cfg.DefaultOrg
+}
+
+func (s *Server) drop(from string, err error) {
+	s.dropped.Add(1)
+	s.logger.Printf("Syslog: Dropped message from %s: %v", from, err)
+}
+
+// newEvent converts a parsed message into the submitted event
+func newEvent(msg *Message, from sender) *Event {
+	event := &Event{
+		Message:        msg.Message,
+		Format:         msg.Format,
+		Facility:       msg.FacilityName(),
+		Severity:       msg.SeverityName(),
+		Hostname:       msg.Hostname,
+		AppName:        msg.AppName,
+		ProcID:         msg.ProcID,
+		MsgID:          msg.MsgID,
+		StructuredData: msg.StructuredData,
+		Transport:      from.transport,
+		RemoteAddr:     from.ip,
+		ClientCert:     from.certCN,
+	}
+	if msg.Timestamp != nil {
+		event.Timestamp = msg.Timestamp.Format(time.RFC3339Nano)
+	}
+	return event
+}
+
+// hostOf returns the IP of an address
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -6,6 +6,7 @@ local cjson = require "cjson"
 local _M = {}
 
 local CLOCK_SKEW_SECONDS = tonumber(os.getenv("API_KEY_CLOCK_SKEW")) or 10 -- default 10 seconds
+local GATEWAY_HMAC_SECRET = os.getenv("GATEWAY_HMAC_SECRET")
 local cjson_null = cjson.null
 
 local function calculate_timezone_offset()
@@ -151,6 +152,48 @@ local function check_auth_failure_limit(key)
     return true, nil
 end
 
+-- Identity headers passed to backend services, in signing order (internal/auth/gateway.go)
+local IDENTITY_HEADERS = { "X-Auth-Method", "X-API-Client-ID", "X-Client-Org-ID", "X-Member-ID", "X-Cert-Subject" }
+
+-- Set the identity headers for backend services and sign them with GATEWAY_HMAC_SECRET, so services
+-- can tell them from headers a client sent. identity maps header names to values; other identity
+-- headers are cleared. Signed string: v1, timestamp, method, URI and the identity headers, one per line.
+local function set_identity_headers(identity)
+    local timestamp = tostring(ngx.time())
+    local values = { "v1", timestamp, ngx.var.request_method or "", ngx.var.request_uri or "" }
+    for _, name in ipairs(IDENTITY_HEADERS) do
+        local value = identity[name]
+        if is_null(value) or value == "" then
+            ngx.req.clear_header(name)
+            value = ""
+        else
+            ngx.req.set_header(name, value)
+        end
+        values[#values + 1] = value
+    end
+
+    ngx.req.clear_header("X-Auth-Timestamp")
+    ngx.req.clear_header("X-Auth-Signature")
+    if not GATEWAY_HMAC_SECRET or GATEWAY_HMAC_SECRET == "" then
+        return
+    end
+
+    local hmac = require "resty.openssl.hmac"
+    local h, err = hmac.new(GATEWAY_HMAC_SECRET, "sha256")
+    if not h then
+        ngx.log(ngx.ERR, "Failed to create gateway HMAC: ", err)
+        return
+    end
+    local digest, final_err = h:final(table.concat(values, "\n"))
+    if not digest then
+        ngx.log(ngx.ERR, "Failed to sign identity headers: ", final_err)
+        return
+    end
+
+    ngx.req.set_header("X-Auth-Timestamp", timestamp)
+    ngx.req.set_header("X-Auth-Signature", require("resty.string").to_hex(digest))
+end
+
 _M.CLOCK_SKEW_SECONDS = CLOCK_SKEW_SECONDS
 _M.is_null = is_null
 _M.parse_iso8601_utc = parse_iso8601_utc
@@ -158,6 +201,7 @@ _M.validate_activation_window = validate_activation_window
 _M.ensure_permission = ensure_permission
 _M.write_audit_log = write_audit_log
 _M.check_auth_failure_limit = check_auth_failure_limit
+_M.set_identity_headers = set_identity_headers
 _M.MAX_AUTH_FAILURES = MAX_AUTH_FAILURES
 _M.AUTH_FAILURE_WINDOW = AUTH_FAILURE_WINDOW
 
@@ -363,11 +363,12 @@ local function authenticate()
         ngx.exit(403)
     end
 
-    -- Set headers for backend services (gRPC metadata)
-    ngx.req.set_header("X-API-Client-ID", client_info.client_id)
-    if client_info.org_id then
-        ngx.req.set_header("X-Client-Org-ID", client_info.org_id)
-    end
+    -- Set signed headers for backend services (gRPC metadata)
+    auth_common.set_identity_headers({
+        ["X-Auth-Method"] = "api-key",
+        ["X-API-Client-ID"] = client_info.client_id,
+        ["X-Client-Org-ID"] = client_info.org_id,
+    })
 
     local audit_msg = string.format("%s|%s|%s|200|%s|%s|GRPC_API_KEY|SUCCESS",
         client_ip or "-",
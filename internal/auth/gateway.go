@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers the ingress adds to prove it set the identity headers
+const (
+	HeaderAuthTimestamp = "X-Auth-Timestamp" // Unix seconds when the request was signed
+	HeaderAuthSignature = "X-Auth-Signature" // Hex HMAC-SHA256 over gatewaySigningString
+)
+
+// gatewaySignatureVersion prefixes the signed string so the format can change
+const gatewaySignatureVersion = "v1"
+
+// identityHeaders are the headers covered by the signature, in signing order
+var identityHeaders = []string{"X-Auth-Method", "X-API-Client-ID", "X-Client-Org-ID", "X-Member-ID", "X-Cert-Subject"}
+
+// ErrGatewaySignature is returned for requests whose identity headers were not signed by the ingress
+var ErrGatewaySignature = errors.New("invalid gateway signature")
+
+// GatewayVerifier checks the HMAC the ingress computes with a shared secret over the identity
+// headers, the request method and URI and a timestamp (ingress/nginx/lua/auth_common.lua).
+// Services that verify it no longer trust identity headers from anyone who can reach them.
+type GatewayVerifier struct {
+	secret  []byte
+	maxSkew time.Duration
+}
+
+// NewGatewayVerifier creates a verifier; signatures older or newer than maxSkew are rejected
+func NewGatewayVerifier(secret []byte, maxSkew time.Duration) *GatewayVerifier {
+	return &GatewayVerifier{secret: secret, maxSkew: maxSkew}
+}
+
+// Verify checks the signature of a request. header returns a header value by its canonical name,
+// so the same check serves HTTP headers and gRPC metadata.
+func (v *GatewayVerifier) Verify(method, requestURI string, header func(name string) string) error {
+	timestamp := header(HeaderAuthTimestamp)
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or malformed %s", ErrGatewaySignature, HeaderAuthTimestamp)
+	}
+	if skew := time.Since(time.Unix(signedAt, 0)); skew > v.maxSkew || skew < -v.maxSkew {
+		return fmt.Errorf("%w: signed %v ago, more than %v", ErrGatewaySignature, skew.Truncate(time.Second), v.maxSkew)
+	}
+
+	signature, err := hex.DecodeString(header(HeaderAuthSignature))
+	if err != nil || len(signature) != sha256.Size {
+		return fmt.Errorf("%w: missing or malformed %s", ErrGatewaySignature, HeaderAuthSignature)
+	}
+
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(gatewaySigningString(timestamp, method, requestURI, header)))
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return fmt.Errorf("%w: signature does not match", ErrGatewaySignature)
+	}
+	return nil
+}
+
+// VerifyRequest checks the signature of an HTTP request
+func (v *GatewayVerifier) VerifyRequest(r *http.Request) error {
+	return v.Verify(r.Method, r.RequestURI, r.Header.Get)
+}
+
+// gatewaySigningString joins the version, timestamp, method, URI and identity headers with newlines;
+// absent headers are empty lines
+func gatewaySigningString(timestamp, method, requestURI string, header func(name string) string) string {
+	values := []string{gatewaySignatureVersion, timestamp, method, requestURI}
+	for _, name := range identityHeaders {
+		values = append(values, header(name))
+	}
+	return strings.Join(values, "\n")
+}
+
+// RequireGateway is a middleware that rejects requests whose identity headers the ingress did not sign.
+// A nil verifier accepts every request, trusting the headers as before.
+func RequireGateway(v *GatewayVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if v == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := v.VerifyRequest(r); err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				http.Error(w, `{"error":"Unauthorized","message":"Gateway signature required"}`, http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var testGatewaySecret = []byte("0123456789abcdef0123456789abcdef")
+
+// signRequest sets the identity headers and signs them the way the ingress does
+func signRequest(r *http.Request, signedAt time.Time, identity map[string]string) {
+	for name, value := range identity {
+		r.Header.Set(name, value)
+	}
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	mac := hmac.New(sha256.New, testGatewaySecret)
+	mac.Write([]byte(gatewaySigningString(timestamp, r.Method, r.RequestURI, r.Header.Get)))
+	r.Header.Set(HeaderAuthTimestamp, timestamp)
+	r.Header.Set(HeaderAuthSignature, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestGatewayVerifierVerifyRequest(t *testing.T) {
+	identity := map[string]string{
+		"X-Auth-Method":   "api_key",
+		"X-API-Client-ID": "client-1",
+		"X-Client-Org-ID": "org1",
+	}
+	tests := []struct {
+		name    string
+		prepare func(r *http.Request)
+		wantErr bool
+	}{
+		{"valid", func(r *http.Request) { signRequest(r, time.Now(), identity) }, false},
+		{"within skew", func(r *http.Request) { signRequest(r, time.Now().Add(-20*time.Second), identity) }, false},
+		{"tampered org", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.Header.Set("X-Client-Org-ID", "org2")
+		}, true},
+		{"added member", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.Header.Set("X-Member-ID", "member-001")
+		}, true},
+		{"tampered uri", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.RequestURI = "/v1/logs/batch"
+		}, true},
+		{"tampered method", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.Method = http.MethodPut
+		}, true},
+		{"stale", func(r *http.Request) { signRequest(r, time.Now().Add(-time.Minute), identity) }, true},
+		{"future", func(r *http.Request) { signRequest(r, time.Now().Add(time.Minute), identity) }, true},
+		{"changed timestamp", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.Header.Set(HeaderAuthTimestamp, strconv.FormatInt(time.Now().Unix()-1, 10))
+		}, true},
+		{"missing signature", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.Header.Del(HeaderAuthSignature)
+		}, true},
+		{"malformed signature", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.Header.Set(HeaderAuthSignature, "not-hex")
+		}, true},
+		{"missing timestamp", func(r *http.Request) {
+			signRequest(r, time.Now(), identity)
+			r.Header.Del(HeaderAuthTimestamp)
+		}, true},
+		{"unsigned", func(r *http.Request) {
+			for name, value := range identity {
+				r.Header.Set(name, value)
+			}
+		}, true},
+	}
+	v := NewGatewayVerifier(testGatewaySecret, 30*time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/logs", nil)
+			tt.prepare(r)
+			err := v.VerifyRequest(r)
+			if tt.wantErr {
+				if !errors.Is(err, ErrGatewaySignature) {
+					t.Errorf("VerifyRequest = %v, want %v", err, ErrGatewaySignature)
+				}
+			} else if err != nil {
+				t.Errorf("VerifyRequest: %v", err)
+			}
+		})
+	}
+}
+
+func TestGatewayVerifierWrongSecret(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/v1/logs", nil)
+	signRequest(r, time.Now(), map[string]string{"X-Client-Org-ID": "org1"})
+	v := NewGatewayVerifier([]byte("another-secret-another-secret-00"), 30*time.Second)
+	if err := v.VerifyRequest(r); !errors.Is(err, ErrGatewaySignature) {
+		t.Errorf("VerifyRequest = %v, want %v", err, ErrGatewaySignature)
+	}
+}
+
+func TestRequireGateway(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	signed := httptest.NewRequest(http.MethodPost, "/v1/logs", nil)
+	signRequest(signed, time.Now(), map[string]string{"X-Client-Org-ID": "org1"})
+	unsigned := httptest.NewRequest(http.MethodPost, "/v1/logs", nil)
+	unsigned.Header.Set("X-Client-Org-ID", "org1")
+
+	tests := []struct {
+		name     string
+		verifier *GatewayVerifier
+		request  *http.Request
+		want     int
+	}{
+		{"signed", NewGatewayVerifier(testGatewaySecret, 30*time.Second), signed, http.StatusNoContent},
+		{"unsigned", NewGatewayVerifier(testGatewaySecret, 30*time.Second), unsigned, http.StatusUnauthorized},
+		{"nil verifier passes unsigned", nil, unsigned, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			RequireGateway(tt.verifier)(next).ServeHTTP(w, tt.request)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
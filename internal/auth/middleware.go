@@ -107,3 +107,23 @@ func RequireMTLS(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// RequireAdmin is a middleware that requires mTLS authentication by one of the admin members
+func RequireAdmin(memberIDs []string) func(http.Handler) http.Handler {
+	admins := make(map[string]bool, len(memberIDs))
+	for _, memberID := range memberIDs {
+		admins[memberID] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return RequireMTLS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authCtx := GetAuthContext(r.Context())
+			if authCtx == nil || !admins[authCtx.MemberID] {
+				w.Header().Set("Content-Type", "application/json")
+				http.Error(w, `{"error":"Forbidden","message":"Admin member required"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
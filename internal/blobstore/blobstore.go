@@ -0,0 +1,71 @@
+package blobstore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+
+	"tlng/config"
+	"tlng/internal/hashing"
+)
+
+var (
+	// ErrNotFound is returned when a locator points to a blob that does not exist
+	ErrNotFound = errors.New("blob not found")
+	// ErrUnknownLocator is returned for locators this store did not issue
+	ErrUnknownLocator = errors.New("locator does not belong to this blob store")
+)
+
+// orgSegmentPattern matches org IDs used verbatim as a key segment: safe in paths and in the
+// on-chain key=value record, and never "." or ".."
+var orgSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Store keeps log content off chain, addressed by the submitting org and the log hash.
+// Each org's content is a separate blob, so deleting one org's log leaves other orgs' logs with
+// the same content intact. Content of orgs in encryption scope is stored sealed (enc:v2).
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores an org's content under its log hash and returns the locator recorded on chain.
+	// Storing the same org and hash again is a no-op that returns the same locator.
+	Put(ctx context.Context, sourceOrgID, logHash string, content []byte) (string, error)
+
+	// Get returns the content a locator points to; callers verify it against the log hash
+	Get(ctx context.Context, locator string) ([]byte, error)
+
+	// Delete removes the blob Put stored for an org's log hash; a missing blob is not an error
+	Delete(ctx context.Context, sourceOrgID, logHash string) error
+}
+
+// New creates the blob store selected by the configuration
+func New(cfg config.BlobStoreConfig, logger *log.Logger) (Store, error) {
+	switch cfg.Type {
+	case config.BlobStoreTypeFilesystem:
+		return NewFilesystemStore(cfg.Filesystem, logger)
+	case config.BlobStoreTypeS3:
+		return NewS3Store(cfg.S3, logger)
+	default:
+		return nil, fmt.Errorf("unsupported blob store type '%s'", cfg.Type)
+	}
+}
+
+// blobKey is the relative key of an org's log hash: <org>/<algorithm>/<first two hex digits>/<hex digest>.
+// Other org IDs are written as "~" followed by their base64url encoding, and logs without an org as "_".
+// Untagged hashes are SHA-256, see hashing.Format.
+func blobKey(sourceOrgID, logHash string) (string, error) {
+	algorithm, digest, err := hashing.Parse(logHash, hashing.SHA256)
+	if err != nil {
+		return "", err
+	}
+	org := sourceOrgID
+	switch {
+	case org == "":
+		org = "_"
+	case !orgSegmentPattern.MatchString(org):
+		org = "~" + base64.RawURLEncoding.EncodeToString([]byte(sourceOrgID))
+	}
+	return path.Join(org, string(algorithm), digest[:2], digest), nil
+}
@@ -0,0 +1,100 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tlng/config"
+)
+
+// filesystemLocatorPrefix marks locators of the filesystem store; the rest is the key relative to the root
+const filesystemLocatorPrefix = "file:"
+
+// FilesystemStore is a Store backed by a directory, e.g. a shared volume mounted
+// by both the ingestion and the query service
+type FilesystemStore struct {
+	dir    string
+	logger *log.Logger
+}
+
+// NewFilesystemStore creates the root directory if needed
+func NewFilesystemStore(cfg config.FilesystemBlobStoreConfig, logger *log.Logger) (*FilesystemStore, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob store directory '%s': %w", cfg.Dir, err)
+	}
+	logger.Printf("Blob store: filesystem at %s", cfg.Dir)
+	return &FilesystemStore{dir: cfg.Dir, logger: logger}, nil
+}
+
+// Put writes content to a temporary file and renames it into place, so readers never see a partial blob
+func (s *FilesystemStore) Put(ctx context.Context, sourceOrgID, logHash string, content []byte) (string, error) {
+	key, err := blobKey(sourceOrgID, logHash)
+	if err != nil {
+		return "", err
+	}
+	locator := filesystemLocatorPrefix + key
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+
+	// Content-addressed: an existing blob already holds this content
+	if _, err := os.Stat(target); err == nil {
+		return locator, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".blob-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to sync blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("failed to store blob: %w", err)
+	}
+	return locator, nil
+}
+
+// Get reads the blob of a locator issued by Put
+func (s *FilesystemStore) Get(ctx context.Context, locator string) ([]byte, error) {
+	key, ok := strings.CutPrefix(locator, filesystemLocatorPrefix)
+	if !ok || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownLocator, locator)
+	}
+
+	content, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: '%s'", ErrNotFound, locator)
+		}
+		return nil, fmt.Errorf("failed to read blob '%s': %w", locator, err)
+	}
+	return content, nil
+}
+
+// Delete removes the blob file of an org's log hash
+func (s *FilesystemStore) Delete(ctx context.Context, sourceOrgID, logHash string) error {
+	key, err := blobKey(sourceOrgID, logHash)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob '%s': %w", filesystemLocatorPrefix+key, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"tlng/config"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3LocatorScheme prefixes locators of the S3 store: s3://<bucket>/<prefix>/<key>
+const s3LocatorScheme = "s3://"
+
+// S3Store is a Store backed by a bucket of an S3-compatible object store
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+	logger *log.Logger
+}
+
+// NewS3Store creates a client for the configured endpoint. Without static keys the credentials
+// are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY. The bucket must already exist.
+func NewS3Store(cfg config.S3BlobStoreConfig, logger *log.Logger) (*S3Store, error) {
+	creds := credentials.NewEnvAWS()
+	if cfg.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client for '%s': %w", cfg.Endpoint, err)
+	}
+	logger.Printf("Blob store: s3 bucket %s at %s (prefix '%s')", cfg.Bucket, cfg.Endpoint, cfg.Prefix)
+	return &S3Store{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix, logger: logger}, nil
+}
+
+// Put uploads content unless an object already exists under its key
+func (s *S3Store) Put(ctx context.Context, sourceOrgID, logHash string, content []byte) (string, error) {
+	key, err := blobKey(sourceOrgID, logHash)
+	if err != nil {
+		return "", err
+	}
+	objectName := path.Join(s.prefix, key)
+	locator := s3LocatorScheme + s.bucket + "/" + objectName
+
+	// Content-addressed: an existing object already holds this content
+	if _, err := s.client.StatObject(ctx, s.bucket, objectName, minio.StatObjectOptions{}); err == nil {
+		return locator, nil
+	} else if minio.ToErrorResponse(err).Code != minio.NoSuchKey {
+		return "", fmt.Errorf("failed to check blob '%s': %w", locator, err)
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, objectName, bytes.NewReader(content), int64(len(content)),
+		minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload blob '%s': %w", locator, err)
+	}
+	return locator, nil
+}
+
+// Get downloads the object of a locator in the configured bucket
+func (s *S3Store) Get(ctx context.Context, locator string) ([]byte, error) {
+	objectName, ok := strings.CutPrefix(locator, s3LocatorScheme+s.bucket+"/")
+	if !ok || objectName == "" {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownLocator, locator)
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download blob '%s': %w", locator, err)
+	}
+	defer object.Close()
+
+	// GetObject is lazy; a missing object surfaces on the first read
+	content, err := io.ReadAll(object)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, fmt.Errorf("%w: '%s'", ErrNotFound, locator)
+		}
+		return nil, fmt.Errorf("failed to download blob '%s': %w", locator, err)
+	}
+	return content, nil
+}
+
+// Delete removes the object of an org's log hash; S3 reports success for a missing object
+func (s *S3Store) Delete(ctx context.Context, sourceOrgID, logHash string) error {
+	key, err := blobKey(sourceOrgID, logHash)
+	if err != nil {
+		return err
+	}
+	objectName := path.Join(s.prefix, key)
+	if err := s.client.RemoveObject(ctx, s.bucket, objectName, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete blob '%s': %w", s3LocatorScheme+s.bucket+"/"+objectName, err)
+	}
+	return nil
+}
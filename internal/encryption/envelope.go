@@ -0,0 +1,153 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// envelopePrefix marks content sealed directly with an org data key: "enc:v1:<key_id>:<base64url(nonce || ciphertext)>".
+// recordEnvelopePrefix marks content sealed with a per-record key kept off chain: "enc:v2:<base64url(nonce || ciphertext)>".
+// The encoding avoids '&', '=' and '+' so envelopes survive the contract's key=value storage format.
+const (
+	envelopePrefix       = "enc:v1:"
+	recordEnvelopePrefix = "enc:v2:"
+)
+
+// ErrInvalidEnvelope is returned for content that is not a well-formed envelope
+var ErrInvalidEnvelope = errors.New("invalid encrypted content envelope")
+
+// ErrDecryptionFailed is returned when an envelope does not open with the given key
+var ErrDecryptionFailed = errors.New("failed to decrypt log content")
+
+// IsEnvelope reports whether on-chain content is encrypted
+func IsEnvelope(content string) bool {
+	return strings.HasPrefix(content, envelopePrefix) || IsRecordEnvelope(content)
+}
+
+// IsRecordEnvelope reports whether on-chain content is sealed with a per-record key, which can be crypto-shredded
+func IsRecordEnvelope(content string) bool {
+	return strings.HasPrefix(content, recordEnvelopePrefix)
+}
+
+// EnvelopeKeyID returns the ID of the data key that sealed an envelope
+func EnvelopeKeyID(envelope string) (string, error) {
+	keyID, _, err := splitEnvelope(envelope)
+	return keyID, err
+}
+
+// Seal encrypts log content with an org data key.
+// The org and log hash are authenticated with the content, so an envelope cannot be moved to another record.
+func Seal(key *DataKey, orgID, logHash, plaintext string) (string, error) {
+	sealed, err := seal(key.Key, []byte(plaintext), additionalData(orgID, logHash))
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + key.ID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// SealRecord encrypts log content with a per-record key (see RecordKey)
+func SealRecord(recordKey []byte, orgID, logHash, plaintext string) (string, error) {
+	sealed, err := seal(recordKey, []byte(plaintext), additionalData(orgID, logHash))
+	if err != nil {
+		return "", err
+	}
+	return recordEnvelopePrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// OpenRecord decrypts an envelope produced by SealRecord for the same org and log hash
+func OpenRecord(recordKey []byte, orgID, logHash, envelope string) (string, error) {
+	if !IsRecordEnvelope(envelope) {
+		return "", ErrInvalidEnvelope
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(envelope, recordEnvelopePrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	plaintext, err := open(recordKey, sealed, additionalData(orgID, logHash))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// Open decrypts an envelope produced by Seal for the same org and log hash
+func Open(key *DataKey, orgID, logHash, envelope string) (string, error) {
+	keyID, sealed, err := splitEnvelope(envelope)
+	if err != nil {
+		return "", err
+	}
+	if keyID != key.ID {
+		return "", fmt.Errorf("%w: envelope key '%s' does not match key '%s'", ErrDecryptionFailed, keyID, key.ID)
+	}
+
+	plaintext, err := open(key.Key, sealed, additionalData(orgID, logHash))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func splitEnvelope(envelope string) (string, []byte, error) {
+	if !strings.HasPrefix(envelope, envelopePrefix) {
+		return "", nil, ErrInvalidEnvelope
+	}
+	keyID, encoded, found := strings.Cut(strings.TrimPrefix(envelope, envelopePrefix), ":")
+	if !found || keyID == "" {
+		return "", nil, fmt.Errorf("%w: missing key id", ErrInvalidEnvelope)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	return keyID, sealed, nil
+}
+
+// seal encrypts with AES-256-GCM and returns nonce || ciphertext
+func seal(key, plaintext, additional []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+// open reverses seal
+func open(key, sealed, additional []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: ciphertext too short", ErrInvalidEnvelope)
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additional)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+	return plaintext, nil
+}
+
+func additionalData(orgID, logHash string) []byte {
+	return []byte("tlng-log-content|" + orgID + "|" + logHash)
+}
+
+// newAEAD returns AES-256-GCM for a 32-byte key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("data key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,166 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testLogHash = "40dc7a0be4aaab2b8cd7982104bb5f029da283766451f1a8de41f1458da8a80c"
+
+var (
+	testDataKey   = &DataKey{ID: "org1-k1", Key: bytes.Repeat([]byte{0x11}, KeySize)}
+	testRecordKey = bytes.Repeat([]byte{0x22}, KeySize)
+)
+
+// envelopeCodec seals and opens content with one of the envelope versions
+type envelopeCodec struct {
+	name string
+	seal func(orgID, logHash, plaintext string) (string, error)
+	open func(orgID, logHash, envelope string) (string, error)
+}
+
+var envelopeCodecs = []envelopeCodec{
+	{
+		name: "v1",
+		seal: func(orgID, logHash, plaintext string) (string, error) {
+			return Seal(testDataKey, orgID, logHash, plaintext)
+		},
+		open: func(orgID, logHash, envelope string) (string, error) {
+			return Open(testDataKey, orgID, logHash, envelope)
+		},
+	},
+	{
+		name: "v2",
+		seal: func(orgID, logHash, plaintext string) (string, error) {
+			return SealRecord(testRecordKey, orgID, logHash, plaintext)
+		},
+		open: func(orgID, logHash, envelope string) (string, error) {
+			return OpenRecord(testRecordKey, orgID, logHash, envelope)
+		},
+	},
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	for _, codec := range envelopeCodecs {
+		for _, plaintext := range []string{"", "user alice logged in", `{"q":"a=1&b=2","ts":"2024-01-01T00:00:00+08:00"}`, "€ 95%"} {
+			envelope, err := codec.seal("org1", testLogHash, plaintext)
+			if err != nil {
+				t.Fatalf("%s: seal: %v", codec.name, err)
+			}
+			if !IsEnvelope(envelope) {
+				t.Errorf("%s: IsEnvelope(%q) = false", codec.name, envelope)
+			}
+			if got := IsRecordEnvelope(envelope); got != (codec.name == "v2") {
+				t.Errorf("%s: IsRecordEnvelope(%q) = %v", codec.name, envelope, got)
+			}
+			// The envelope survives the contract's key=value record unescaped
+			if strings.ContainsAny(envelope, "&=+%/ ") {
+				t.Errorf("%s: envelope %q contains characters of the key=value format", codec.name, envelope)
+			}
+			got, err := codec.open("org1", testLogHash, envelope)
+			if err != nil {
+				t.Fatalf("%s: open: %v", codec.name, err)
+			}
+			if got != plaintext {
+				t.Errorf("%s: open = %q, want %q", codec.name, got, plaintext)
+			}
+		}
+	}
+}
+
+func TestEnvelopeKeyID(t *testing.T) {
+	envelope, err := Seal(testDataKey, "org1", testLogHash, "content")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !strings.HasPrefix(envelope, "enc:v1:org1-k1:") {
+		t.Errorf("envelope %q does not start with enc:v1:org1-k1:", envelope)
+	}
+	if keyID, err := EnvelopeKeyID(envelope); err != nil || keyID != "org1-k1" {
+		t.Errorf("EnvelopeKeyID = %q, %v, want org1-k1", keyID, err)
+	}
+
+	other := &DataKey{ID: "org1-k2", Key: testDataKey.Key}
+	if _, err := Open(other, "org1", testLogHash, envelope); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Open with key org1-k2 = %v, want %v", err, ErrDecryptionFailed)
+	}
+}
+
+func TestEnvelopeOpenFails(t *testing.T) {
+	tamper := func(envelope string) string {
+		i := strings.LastIndex(envelope, ":") + 1
+		sealed, _ := base64.RawURLEncoding.DecodeString(envelope[i:])
+		sealed[len(sealed)-1] ^= 0x01
+		return envelope[:i] + base64.RawURLEncoding.EncodeToString(sealed)
+	}
+	tests := []struct {
+		name    string
+		orgID   string
+		logHash string
+		modify  func(envelope string) string
+		wantErr error
+	}{
+		{"wrong org", "org2", testLogHash, nil, ErrDecryptionFailed},
+		{"wrong hash", "org1", "sm3:" + testLogHash, nil, ErrDecryptionFailed},
+		{"tampered", "org1", testLogHash, tamper, ErrDecryptionFailed},
+		{"truncated", "org1", testLogHash, func(envelope string) string { return envelope[:len(envelope)-4] }, ErrDecryptionFailed},
+		{"shorter than nonce", "org1", testLogHash, func(envelope string) string {
+			return envelope[:strings.LastIndex(envelope, ":")+1] + "AAAA"
+		}, ErrInvalidEnvelope},
+		{"not base64url", "org1", testLogHash, func(envelope string) string { return envelope + "+/" }, ErrInvalidEnvelope},
+		{"plaintext", "org1", testLogHash, func(string) string { return "user alice logged in" }, ErrInvalidEnvelope},
+	}
+	for _, codec := range envelopeCodecs {
+		for _, tt := range tests {
+			t.Run(codec.name+"/"+tt.name, func(t *testing.T) {
+				envelope, err := codec.seal("org1", testLogHash, "user alice logged in")
+				if err != nil {
+					t.Fatalf("seal: %v", err)
+				}
+				if tt.modify != nil {
+					envelope = tt.modify(envelope)
+				}
+				if _, err := codec.open(tt.orgID, tt.logHash, envelope); !errors.Is(err, tt.wantErr) {
+					t.Errorf("open = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestEnvelopeVersionsDoNotMix(t *testing.T) {
+	v1, _ := Seal(testDataKey, "org1", testLogHash, "content")
+	v2, _ := SealRecord(testRecordKey, "org1", testLogHash, "content")
+	if _, err := OpenRecord(testRecordKey, "org1", testLogHash, v1); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("OpenRecord(v1) = %v, want %v", err, ErrInvalidEnvelope)
+	}
+	if _, err := Open(testDataKey, "org1", testLogHash, v2); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("Open(v2) = %v, want %v", err, ErrInvalidEnvelope)
+	}
+	if _, err := EnvelopeKeyID("enc:v1:"); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("EnvelopeKeyID without key id = %v, want %v", err, ErrInvalidEnvelope)
+	}
+}
+
+func TestIsEnvelope(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"", false},
+		{"user alice logged in", false},
+		{`{"msg":"enc:v1:k:abc"}`, false},
+		{"enc:v3:abc", false},
+		{"ENC:v1:k:abc", false},
+		{"enc:v1:k:abc", true},
+		{"enc:v2:abc", true},
+	}
+	for _, tt := range tests {
+		if got := IsEnvelope(tt.content); got != tt.want {
+			t.Errorf("IsEnvelope(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"tlng/config"
+)
+
+const (
+	// lockRetryInterval and lockTimeout bound the wait for another process updating the keystore
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 5 * time.Second
+	// staleLockAge is when a lock file left behind by a crashed process is removed
+	staleLockAge = 30 * time.Second
+)
+
+// keystoreFile is the on-disk format; data keys are wrapped with the master key
+type keystoreFile struct {
+	Orgs map[string]*orgKeys `json:"orgs"`
+}
+
+type orgKeys struct {
+	CurrentKeyID string            `json:"current_key_id"`
+	Keys         map[string]string `json:"keys"` // key_id -> base64(nonce || wrapped key)
+}
+
+// FileKeystore is a KeyProvider backed by a JSON file.
+// Every process using the same file sees the same keys; new keys are written under a lock file.
+type FileKeystore struct {
+	cfg       config.FileKeystoreConfig
+	masterKey []byte
+	logger    *log.Logger
+
+	mu   sync.RWMutex
+	file *keystoreFile
+}
+
+// NewFileKeystore loads the master key and the keystore; a missing keystore file is treated as empty
+func NewFileKeystore(cfg config.FileKeystoreConfig, logger *log.Logger) (*FileKeystore, error) {
+	masterKey, err := loadMasterKey(cfg.MasterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &FileKeystore{
+		cfg:       cfg,
+		masterKey: masterKey,
+		logger:    logger,
+	}
+	if ks.file, err = ks.read(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// CurrentKey returns the org's current data key, creating one if auto_generate is set
+func (ks *FileKeystore) CurrentKey(ctx context.Context, orgID string) (*DataKey, error) {
+	key, err := ks.lookup(orgID, "")
+	if errors.Is(err, ErrKeyNotFound) {
+		// Another process may have created the key since we last read the file
+		if err = ks.reload(); err != nil {
+			return nil, err
+		}
+		key, err = ks.lookup(orgID, "")
+	}
+	if errors.Is(err, ErrKeyNotFound) && ks.cfg.AutoGenerate {
+		return ks.generateKey(orgID, false)
+	}
+	return key, err
+}
+
+// Key returns a specific data key version of an org
+func (ks *FileKeystore) Key(ctx context.Context, orgID, keyID string) (*DataKey, error) {
+	key, err := ks.lookup(orgID, keyID)
+	if errors.Is(err, ErrKeyNotFound) {
+		if err = ks.reload(); err != nil {
+			return nil, err
+		}
+		key, err = ks.lookup(orgID, keyID)
+	}
+	return key, err
+}
+
+// GenerateKey creates a new data key and makes it the org's current key.
+// Earlier keys are kept so that existing content can still be decrypted.
+func (ks *FileKeystore) GenerateKey(orgID string) (*DataKey, error) {
+	return ks.generateKey(orgID, true)
+}
+
+// generateKey adds a data key for an org. Unless rotate is set, an existing current key
+// (e.g. created concurrently by another worker or process) is returned instead.
+func (ks *FileKeystore) generateKey(orgID string, rotate bool) (*DataKey, error) {
+	if orgID == "" {
+		return nil, fmt.Errorf("org id is required")
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	unlock, err := ks.lockFile()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// 1. Start from the file on disk so keys added by other processes are kept
+	file, err := ks.read()
+	if err != nil {
+		return nil, err
+	}
+	org := file.Orgs[orgID]
+	if org != nil && org.CurrentKeyID != "" && !rotate {
+		ks.file = file
+		return ks.unwrap(orgID, org.CurrentKeyID, org.Keys[org.CurrentKeyID])
+	}
+
+	// 2. Create and wrap the new key
+	key := &DataKey{Key: make([]byte, KeySize)}
+	if _, err := rand.Read(key.Key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate key id: %w", err)
+	}
+	key.ID = hex.EncodeToString(id)
+
+	wrapped, err := ks.wrap(orgID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if org == nil {
+		org = &orgKeys{Keys: make(map[string]string)}
+		file.Orgs[orgID] = org
+	}
+	org.Keys[key.ID] = wrapped
+	org.CurrentKeyID = key.ID
+
+	// 3. Write atomically
+	if err := ks.write(file); err != nil {
+		return nil, err
+	}
+	ks.file = file
+
+	ks.logger.Printf("FileKeystore: Generated data key %s for org %s", key.ID, orgID)
+	return key, nil
+}
+
+// lookup unwraps a key from the loaded file; an empty keyID selects the current key
+func (ks *FileKeystore) lookup(orgID, keyID string) (*DataKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	org := ks.file.Orgs[orgID]
+	if org == nil {
+		return nil, fmt.Errorf("%w: org '%s'", ErrKeyNotFound, orgID)
+	}
+	if keyID == "" {
+		keyID = org.CurrentKeyID
+	}
+	wrapped, ok := org.Keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: org '%s' key '%s'", ErrKeyNotFound, orgID, keyID)
+	}
+	return ks.unwrap(orgID, keyID, wrapped)
+}
+
+func (ks *FileKeystore) reload() error {
+	file, err := ks.read()
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	ks.file = file
+	ks.mu.Unlock()
+	return nil
+}
+
+func (ks *FileKeystore) read() (*keystoreFile, error) {
+	file := &keystoreFile{Orgs: make(map[string]*orgKeys)}
+
+	data, err := os.ReadFile(ks.cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore '%s': %w", ks.cfg.Path, err)
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore '%s': %w", ks.cfg.Path, err)
+	}
+	if file.Orgs == nil {
+		file.Orgs = make(map[string]*orgKeys)
+	}
+	return file, nil
+}
+
+func (ks *FileKeystore) write(file *keystoreFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(ks.cfg.Path), filepath.Base(ks.cfg.Path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create keystore temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync keystore: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close keystore: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ks.cfg.Path); err != nil {
+		return fmt.Errorf("failed to replace keystore: %w", err)
+	}
+	return nil
+}
+
+// lockFile serializes keystore updates across processes with an exclusive lock file
+func (ks *FileKeystore) lockFile() (func(), error) {
+	lockPath := ks.cfg.Path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create keystore lock: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			ks.logger.Printf("FileKeystore: Removing stale lock %s", lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for keystore lock %s", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func (ks *FileKeystore) wrap(orgID string, key *DataKey) (string, error) {
+	aead, err := newAEAD(ks.masterKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	wrapped := aead.Seal(nonce, nonce, key.Key, wrapAdditionalData(orgID, key.ID))
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+func (ks *FileKeystore) unwrap(orgID, keyID, wrapped string) (*DataKey, error) {
+	sealed, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped key '%s' for org '%s': %w", keyID, orgID, err)
+	}
+	aead, err := newAEAD(ks.masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("invalid wrapped key '%s' for org '%s': too short", keyID, orgID)
+	}
+	key, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], wrapAdditionalData(orgID, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key '%s' for org '%s' (wrong master key?): %w", keyID, orgID, err)
+	}
+	return &DataKey{ID: keyID, Key: key}, nil
+}
+
+func wrapAdditionalData(orgID, keyID string) []byte {
+	return []byte("tlng-data-key|" + orgID + "|" + keyID)
+}
+
+func loadMasterKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key file '%s': %w", path, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("master key file '%s' is not base64: %w", path, err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
@@ -0,0 +1,140 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+
+	"tlng/config"
+)
+
+// KeySize is the length of data keys and master keys (AES-256)
+const KeySize = 32
+
+// ErrKeyNotFound is returned when an org has no data key, or not the requested version
+var ErrKeyNotFound = errors.New("data key not found")
+
+// DataKey is one version of an org's content encryption key
+type DataKey struct {
+	ID  string
+	Key []byte
+}
+
+// KeyProvider supplies per-org data keys.
+// Implementations must be safe for concurrent use.
+type KeyProvider interface {
+	// CurrentKey returns the key used to encrypt new content for an org
+	CurrentKey(ctx context.Context, orgID string) (*DataKey, error)
+
+	// Key returns a specific key version of an org, for decryption
+	Key(ctx context.Context, orgID, keyID string) (*DataKey, error)
+}
+
+// NewKeyProvider creates the key provider selected by the configuration
+func NewKeyProvider(cfg config.KeyProviderConfig, logger *log.Logger) (KeyProvider, error) {
+	switch cfg.Type {
+	case config.KeyProviderTypeFile:
+		keystore, err := NewFileKeystore(cfg.File, logger)
+		if err != nil {
+			return nil, err
+		}
+		return keystore, nil
+	default:
+		return nil, fmt.Errorf("unsupported key provider type '%s'", cfg.Type)
+	}
+}
+
+// Encryptor seals the on-chain content of the orgs in scope
+type Encryptor struct {
+	keys KeyProvider
+	orgs map[string]bool // empty means all orgs
+}
+
+// NewEncryptor creates an Encryptor; orgs limits encryption to those orgs, or covers all orgs if empty
+func NewEncryptor(keys KeyProvider, orgs []string) *Encryptor {
+	scope := make(map[string]bool, len(orgs))
+	for _, orgID := range orgs {
+		scope[orgID] = true
+	}
+	return &Encryptor{keys: keys, orgs: scope}
+}
+
+// Applies reports whether content of an org must be encrypted
+func (e *Encryptor) Applies(orgID string) bool {
+	return len(e.orgs) == 0 || e.orgs[orgID]
+}
+
+// NewRecordKey creates a random key for one record, wrapped with the org's current data key
+func (e *Encryptor) NewRecordKey(ctx context.Context, orgID, logHash string) (*RecordKey, error) {
+	orgKey, err := e.keys.CurrentKey(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key for org '%s': %w", orgID, err)
+	}
+
+	recordKey := make([]byte, KeySize)
+	if _, err := rand.Read(recordKey); err != nil {
+		return nil, fmt.Errorf("failed to generate record key: %w", err)
+	}
+	wrapped, err := seal(orgKey.Key, recordKey, recordKeyAdditionalData(orgID, logHash))
+	if err != nil {
+		return nil, err
+	}
+	return &RecordKey{OrgKeyID: orgKey.ID, WrappedKey: base64.StdEncoding.EncodeToString(wrapped)}, nil
+}
+
+// EncryptRecord seals content with a record key created by NewRecordKey
+func (e *Encryptor) EncryptRecord(ctx context.Context, recordKey *RecordKey, orgID, logHash, content string) (string, error) {
+	key, err := unwrapRecordKey(ctx, e.keys, recordKey, orgID, logHash)
+	if err != nil {
+		return "", err
+	}
+	return SealRecord(key, orgID, logHash, content)
+}
+
+// RecordKey is the per-record content key wrapped with an org data key. It is kept off chain,
+// so destroying it (crypto-shredding) makes the on-chain content of that record unrecoverable.
+type RecordKey struct {
+	OrgKeyID   string // Org data key version that wraps the record key
+	WrappedKey string // base64(nonce || wrapped key)
+}
+
+// DecryptRecord opens an envelope produced by EncryptRecord
+func DecryptRecord(ctx context.Context, keys KeyProvider, recordKey *RecordKey, orgID, logHash, envelope string) (string, error) {
+	key, err := unwrapRecordKey(ctx, keys, recordKey, orgID, logHash)
+	if err != nil {
+		return "", err
+	}
+	return OpenRecord(key, orgID, logHash, envelope)
+}
+
+func unwrapRecordKey(ctx context.Context, keys KeyProvider, recordKey *RecordKey, orgID, logHash string) ([]byte, error) {
+	orgKey, err := keys.Key(ctx, orgID, recordKey.OrgKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key '%s' for org '%s': %w", recordKey.OrgKeyID, orgID, err)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(recordKey.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wrapped record key for log_hash %s: %w", logHash, err)
+	}
+	return open(orgKey.Key, wrapped, recordKeyAdditionalData(orgID, logHash))
+}
+
+func recordKeyAdditionalData(orgID, logHash string) []byte {
+	return []byte("tlng-record-key|" + orgID + "|" + logHash)
+}
+
+// Decrypt opens an envelope sealed directly with an org data key, using the key version it names
+func Decrypt(ctx context.Context, keys KeyProvider, orgID, logHash, envelope string) (string, error) {
+	keyID, err := EnvelopeKeyID(envelope)
+	if err != nil {
+		return "", err
+	}
+	key, err := keys.Key(ctx, orgID, keyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get data key '%s' for org '%s': %w", keyID, orgID, err)
+	}
+	return Open(key, orgID, logHash, envelope)
+}
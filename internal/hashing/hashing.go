@@ -0,0 +1,139 @@
+package hashing
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// Algorithm names a digest algorithm; it is also the tag in algorithm-tagged hashes
+type Algorithm string
+
+const (
+	SHA256  Algorithm = "sha256"
+	SHA3256 Algorithm = "sha3-256"
+	SM3     Algorithm = "sm3"
+
+	// Default is used when neither the request nor the org configuration selects an algorithm
+	Default = SHA256
+)
+
+// ErrUnsupportedAlgorithm is returned for algorithm names that are not registered
+var ErrUnsupportedAlgorithm = errors.New("unsupported hash algorithm")
+
+// ErrInvalidHash is returned for hashes that are not hex digests of a registered algorithm
+var ErrInvalidHash = errors.New("invalid log hash")
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[Algorithm]func() hash.Hash{
+		SHA256:  sha256.New,
+		SHA3256: func() hash.Hash { return sha3.New256() },
+		SM3:     sm3.New,
+	}
+)
+
+// Register adds or replaces a digest algorithm. Names must be lowercase and must not contain ':'.
+func Register(algorithm Algorithm, newHash func() hash.Hash) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[algorithm] = newHash
+}
+
+// Algorithms returns the registered algorithm names in sorted order
+func Algorithms() []Algorithm {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	algorithms := make([]Algorithm, 0, len(registry))
+	for algorithm := range registry {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Slice(algorithms, func(i, j int) bool { return algorithms[i] < algorithms[j] })
+	return algorithms
+}
+
+// ParseAlgorithm converts a configured or client-supplied algorithm name; an empty name is an error
+func ParseAlgorithm(s string) (Algorithm, error) {
+	algorithm := Algorithm(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := lookup(algorithm); !ok {
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, s)
+	}
+	return algorithm, nil
+}
+
+// Sum computes the digest of data and returns it in tagged form (see Format)
+func Sum(algorithm Algorithm, data []byte) (string, error) {
+	newHash, ok := lookup(algorithm)
+	if !ok {
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, algorithm)
+	}
+	h := newHash()
+	h.Write(data)
+	return Format(algorithm, hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// Format builds the stored form of a digest: "<algorithm>:<hex>", except for SHA-256,
+// which stays bare hex so that hashes recorded before algorithms were selectable keep matching
+func Format(algorithm Algorithm, digest string) string {
+	if algorithm == SHA256 {
+		return digest
+	}
+	return string(algorithm) + ":" + digest
+}
+
+// Parse splits a hash in tagged form into its algorithm and lowercase hex digest.
+// Untagged hashes are taken to be in the untagged algorithm; "sha256:<hex>" is accepted as well.
+func Parse(logHash string, untagged Algorithm) (Algorithm, string, error) {
+	algorithm, digest := untagged, logHash
+	if tag, rest, found := strings.Cut(logHash, ":"); found {
+		var err error
+		if algorithm, err = ParseAlgorithm(tag); err != nil {
+			return "", "", err
+		}
+		digest = rest
+	}
+
+	newHash, ok := lookup(algorithm)
+	if !ok {
+		return "", "", fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, algorithm)
+	}
+	raw, err := hex.DecodeString(digest)
+	if err != nil || len(raw) != newHash().Size() {
+		return "", "", fmt.Errorf("%w: '%s' is not a %s hex digest", ErrInvalidHash, logHash, algorithm)
+	}
+	return algorithm, strings.ToLower(digest), nil
+}
+
+// Normalize returns the stored form of a client-supplied hash (see Parse and Format)
+func Normalize(logHash string, untagged Algorithm) (string, error) {
+	algorithm, digest, err := Parse(logHash, untagged)
+	if err != nil {
+		return "", err
+	}
+	return Format(algorithm, digest), nil
+}
+
+// Tag returns the algorithm tag of a hash, or "" if it is untagged
+func Tag(logHash string) string {
+	tag, _, found := strings.Cut(logHash, ":")
+	if !found {
+		return ""
+	}
+	return tag
+}
+
+func lookup(algorithm Algorithm) (func() hash.Hash, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	newHash, ok := registry[algorithm]
+	return newHash, ok
+}
@@ -0,0 +1,104 @@
+package hashing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Digests of "abc" from the algorithm specifications
+const (
+	sha256ABC  = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	sha3256ABC = "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"
+	sm3ABC     = "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"
+)
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		algorithm Algorithm
+		want      string
+	}{
+		{SHA256, sha256ABC},
+		{SHA3256, "sha3-256:" + sha3256ABC},
+		{SM3, "sm3:" + sm3ABC},
+	}
+	for _, tt := range tests {
+		got, err := Sum(tt.algorithm, []byte("abc"))
+		if err != nil || got != tt.want {
+			t.Errorf("Sum(%s) = %s, %v, want %s", tt.algorithm, got, err, tt.want)
+		}
+	}
+	if _, err := Sum("md5", []byte("abc")); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("Sum(md5) = %v, want %v", err, ErrUnsupportedAlgorithm)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		logHash    string
+		untagged   Algorithm
+		algorithm  Algorithm
+		digest     string
+		normalized string
+		wantErr    error
+	}{
+		{"bare hex", sha256ABC, SHA256, SHA256, sha256ABC, sha256ABC, nil},
+		{"bare uppercase hex", strings.ToUpper(sha256ABC), SHA256, SHA256, sha256ABC, sha256ABC, nil},
+		{"sha256 tag", "sha256:" + sha256ABC, SHA256, SHA256, sha256ABC, sha256ABC, nil},
+		{"sm3 tag", "sm3:" + sm3ABC, SHA256, SM3, sm3ABC, "sm3:" + sm3ABC, nil},
+		{"uppercase tag", "SM3:" + strings.ToUpper(sm3ABC), SHA256, SM3, sm3ABC, "sm3:" + sm3ABC, nil},
+		{"sha3-256 tag", "sha3-256:" + sha3256ABC, SHA256, SHA3256, sha3256ABC, "sha3-256:" + sha3256ABC, nil},
+		{"bare hex in untagged sm3", sm3ABC, SM3, SM3, sm3ABC, "sm3:" + sm3ABC, nil},
+		{"unknown tag", "md5:" + sha256ABC, SHA256, "", "", "", ErrUnsupportedAlgorithm},
+		{"empty tag", ":" + sha256ABC, SHA256, "", "", "", ErrUnsupportedAlgorithm},
+		{"short digest", sha256ABC[:62], SHA256, "", "", "", ErrInvalidHash},
+		{"long digest", sha256ABC + "00", SHA256, "", "", "", ErrInvalidHash},
+		{"not hex", strings.Repeat("zz", 32), SHA256, "", "", "", ErrInvalidHash},
+		{"empty", "", SHA256, "", "", "", ErrInvalidHash},
+		{"tag without digest", "sm3:", SHA256, "", "", "", ErrInvalidHash},
+		{"two tags", "sm3:sm3:" + sm3ABC, SHA256, "", "", "", ErrInvalidHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm, digest, err := Parse(tt.logHash, tt.untagged)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Parse = %v, want %v", err, tt.wantErr)
+				}
+				if _, err := Normalize(tt.logHash, tt.untagged); !errors.Is(err, tt.wantErr) {
+					t.Errorf("Normalize = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || algorithm != tt.algorithm || digest != tt.digest {
+				t.Errorf("Parse = %s, %s, %v, want %s, %s", algorithm, digest, err, tt.algorithm, tt.digest)
+			}
+			if normalized, err := Normalize(tt.logHash, tt.untagged); err != nil || normalized != tt.normalized {
+				t.Errorf("Normalize = %s, %v, want %s", normalized, err, tt.normalized)
+			}
+		})
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	for input, want := range map[string]Algorithm{"sha256": SHA256, " SHA3-256 ": SHA3256, "Sm3": SM3} {
+		if got, err := ParseAlgorithm(input); err != nil || got != want {
+			t.Errorf("ParseAlgorithm(%q) = %s, %v, want %s", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "sha-256", "sha3"} {
+		if _, err := ParseAlgorithm(input); !errors.Is(err, ErrUnsupportedAlgorithm) {
+			t.Errorf("ParseAlgorithm(%q) = %v, want %v", input, err, ErrUnsupportedAlgorithm)
+		}
+	}
+}
+
+func TestTag(t *testing.T) {
+	tests := map[string]string{sha256ABC: "", "sm3:" + sm3ABC: "sm3", "sha3-256:" + sha3256ABC: "sha3-256"}
+	for logHash, want := range tests {
+		if got := Tag(logHash); got != want {
+			t.Errorf("Tag(%s) = %q, want %q", logHash, got, want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Package jcs implements the JSON Canonicalization Scheme (RFC 8785).
+// Two JSON texts that differ only in member order, whitespace, string escapes or
+// number notation canonicalize to the same bytes, so they also hash the same.
+package jcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidJSON is returned for input that is not a single I-JSON value (RFC 7493):
+// malformed JSON, invalid UTF-8, escaped unpaired surrogates, duplicate member names or numbers
+// outside the IEEE 754 double range
+var ErrInvalidJSON = errors.New("invalid JSON")
+
+// Canonicalize returns the canonical form of the JSON value in data:
+// no insignificant whitespace, object members sorted by the UTF-16 code units of their names,
+// strings with minimal escaping and numbers serialized like ECMAScript's Number.prototype.toString.
+func Canonicalize(data []byte) ([]byte, error) {
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: not valid UTF-8", ErrInvalidJSON)
+	}
+	if err := checkSurrogates(data); err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	if err := writeValue(&buf, dec); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w: unexpected data after the top-level value", ErrInvalidJSON)
+	}
+	return buf.Bytes(), nil
+}
+
+// checkSurrogates rejects \u escapes of unpaired UTF-16 surrogates in strings. encoding/json
+// decodes them to U+FFFD, so "\ud800" would otherwise canonicalize and hash like "\ufffd".
+// Malformed escapes are left to the decoder.
+func checkSurrogates(data []byte) error {
+	inString := false
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			inString = !inString
+		case '\\':
+			if !inString {
+				continue
+			}
+			r, ok := escapedRune(data, i)
+			switch {
+			case !ok || !utf16.IsSurrogate(r):
+				i++ // Skip the escaped character, which may be '"'
+			case r >= 0xdc00:
+				return fmt.Errorf("%w: unpaired surrogate \\u%04x", ErrInvalidJSON, r)
+			default:
+				low, ok := escapedRune(data, i+6)
+				if !ok || low < 0xdc00 || low > 0xdfff {
+					return fmt.Errorf("%w: unpaired surrogate \\u%04x", ErrInvalidJSON, r)
+				}
+				i += 11 // Both escapes
+			}
+		}
+	}
+	return nil
+}
+
+// escapedRune decodes the \uXXXX escape starting at data[i]
+func escapedRune(data []byte, i int) (rune, bool) {
+	if i+6 > len(data) || data[i] != '\\' || data[i+1] != 'u' {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(string(data[i+2:i+6]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(r), true
+}
+
+// member is an object member with its value already canonicalized
+type member struct {
+	name  string
+	value []byte
+}
+
+// writeValue reads the next value from dec and writes its canonical form to buf
+func writeValue(buf *bytes.Buffer, dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return fmt.Errorf("%w: unexpected end of input", ErrInvalidJSON)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			return writeObject(buf, dec)
+		}
+		return writeArray(buf, dec) // The decoder never returns a closing delimiter here
+	case string:
+		writeString(buf, v)
+	case json.Number:
+		return writeNumber(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	}
+	return nil
+}
+
+// writeObject writes the members of an object whose '{' was already read, sorted by name
+func writeObject(buf *bytes.Buffer, dec *json.Decoder) error {
+	var members []member
+	seen := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+		name := tok.(string) // The decoder only returns strings in member name position
+		if seen[name] {
+			return fmt.Errorf("%w: duplicate member name '%s'", ErrInvalidJSON, name)
+		}
+		seen[name] = true
+
+		var value bytes.Buffer
+		if err := writeValue(&value, dec); err != nil {
+			return err
+		}
+		members = append(members, member{name: name, value: value.Bytes()})
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	sort.Slice(members, func(i, j int) bool { return lessUTF16(members[i].name, members[j].name) })
+
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, m.name)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeArray writes the elements of an array whose '[' was already read, in order
+func writeArray(buf *bytes.Buffer, dec *json.Decoder) error {
+	buf.WriteByte('[')
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeValue(buf, dec); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+// writeString writes s quoted, escaping only '"', '\\' and control characters (RFC 8785 section 3.2.2.2)
+func writeString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[r>>4])
+				buf.WriteByte(hex[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// writeNumber writes n as the shortest decimal that round-trips to the same
+// IEEE 754 double, in ECMAScript notation (RFC 8785 section 3.2.2.3)
+func writeNumber(buf *bytes.Buffer, n json.Number) error {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return fmt.Errorf("%w: number %s is outside the IEEE 754 double range", ErrInvalidJSON, n)
+	}
+	buf.WriteString(formatNumber(f))
+	return nil
+}
+
+// formatNumber implements ECMAScript Number::toString for finite values
+func formatNumber(f float64) string {
+	if f == 0 {
+		return "0" // Also for -0
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// Shortest round-trip digits: "d.ddde±x" gives the digits and the decimal exponent
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	k, n := len(digits), e+1 // value = 0.digits × 10^n
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+
+	expSign := "+"
+	if n-1 < 0 {
+		expSign = "-"
+	}
+	exponent := "e" + expSign + strconv.Itoa(abs(n-1))
+	if k == 1 {
+		return sign + digits + exponent
+	}
+	return sign + digits[:1] + "." + digits[1:] + exponent
+}
+
+// lessUTF16 orders member names by their UTF-16 code units, as RFC 8785 section 3.2.3 requires
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
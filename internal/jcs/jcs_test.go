@@ -0,0 +1,152 @@
+package jcs
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+// TestFormatNumber checks the number serialization samples of RFC 8785 Appendix B
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for _, tt := range tests {
+		f := math.Float64frombits(tt.bits)
+		if got := formatNumber(f); got != tt.want {
+			t.Errorf("formatNumber(%016x) = %s, want %s", tt.bits, got, tt.want)
+		}
+		// The same number written in Go's notation canonicalizes to the same text
+		got, err := Canonicalize([]byte(strconv.FormatFloat(f, 'g', -1, 64)))
+		if err != nil {
+			t.Errorf("Canonicalize(%016x): %v", tt.bits, err)
+		} else if string(got) != tt.want {
+			t.Errorf("Canonicalize(%016x) = %s, want %s", tt.bits, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			// RFC 8785 section 3.2.2
+			name: "values",
+			input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],` +
+				`"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 section 3.2.3: members are sorted by their UTF-16 code units
+			name: "utf-16 sort",
+			input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			want: `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control",` +
+				`"` + "\u00f6" + `":"Latin Small Letter O With Diaeresis","` + "\u20ac" + `":"Euro Sign",` +
+				`"` + "\U0001f600" + `":"Emoji: Grinning Face","` + "\ufb33" + `":"Hebrew Letter Dalet With Dagesh"}`,
+		},
+		{
+			name:  "nested",
+			input: ` { "b" : [ {"z":1,"a":{"y":[],"x":{}}} ], "a" : "é" } `,
+			want:  `{"a":"é","b":[{"a":{"x":{},"y":[]},"z":1}]}`,
+		},
+		{
+			name:  "surrogate pair",
+			input: `"😀 😀"`,
+			want:  `"😀 😀"`,
+		},
+		{
+			name:  "escaped backslash before u",
+			input: `"\\ud800"`,
+			want:  `"\\ud800"`,
+		},
+		{
+			name:  "escaped quote",
+			input: `["\"", "𐀀"]`,
+			want:  `["\"","𐀀"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Canonicalize: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Canonicalize = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ``},
+		{"malformed", `{"a":}`},
+		{"trailing data", `{} {}`},
+		{"invalid utf-8", "\"\xff\""},
+		{"duplicate member", `{"a":1,"a":2}`},
+		{"duplicate member after unescaping", `{"a":1,"\u0061":2}`},
+		{"number out of range", `1e400`},
+		{"lone high surrogate", `"\ud800"`},
+		{"lone low surrogate", `"\udc00"`},
+		{"high surrogate at end of input", `"\ud83d`},
+		{"high surrogate before text", `"\ud83dx"`},
+		{"high surrogate before other escape", `"\ud83dA"`},
+		{"two high surrogates", `"\ud83d\ud83d"`},
+		{"reversed pair", `"\ude00\ud83d"`},
+		{"lone surrogate in member name", `{"\uDFFF":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize([]byte(tt.input))
+			if !errors.Is(err, ErrInvalidJSON) {
+				t.Errorf("Canonicalize = %s, %v, want ErrInvalidJSON", got, err)
+			}
+		})
+	}
+}
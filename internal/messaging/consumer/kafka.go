@@ -22,6 +22,22 @@ type KafkaConsumer struct {
 
 // NewKafkaConsumer creates a new KafkaConsumer instance
 func NewKafkaConsumer(cfg config.KafkaConsumerConfig, logger *log.Logger) (*KafkaConsumer, error) {
+	r, err := NewReader(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Printf("Kafka consumer created, connected to Brokers: %v, Topic: %s, GroupID: %s", cfg.Brokers, cfg.Topic, cfg.GroupID)
+
+	return &KafkaConsumer{
+		reader: r,
+		logger: logger,
+	}, nil
+}
+
+// NewReader creates a consumer group reader for a topic. Offsets are only committed
+// through CommitMessages, so callers decide when a message is done.
+func NewReader(cfg config.KafkaConsumerConfig, logger *log.Logger) (*kafka.Reader, error) {
 	if len(cfg.Brokers) == 0 || cfg.Topic == "" || cfg.GroupID == "" {
 		return nil, errors.New("incomplete kafka configuration: brokers, topic, group_id are all required")
 	}
@@ -71,14 +87,7 @@ func NewKafkaConsumer(cfg config.KafkaConsumerConfig, logger *log.Logger) (*Kafk
 		readerConfig.StartOffset = kafka.FirstOffset
 	}
 
-	r := kafka.NewReader(readerConfig)
-
-	logger.Printf("Kafka consumer created, connected to Brokers: %v, Topic: %s, GroupID: %s", cfg.Brokers, cfg.Topic, cfg.GroupID)
-
-	return &KafkaConsumer{
-		reader: r,
-		logger: logger,
-	}, nil
+	return kafka.NewReader(readerConfig), nil
 }
 
 // Consume implements the Consumer interface by reading messages from Kafka
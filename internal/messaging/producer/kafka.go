@@ -13,11 +13,18 @@ import (
 	"tlng/internal/models"
 )
 
+// Partition key settings for KafkaProducerConfig.PartitionKey
+const (
+	PartitionKeyRequestID   = "request_id"
+	PartitionKeySourceOrgID = "source_org_id"
+)
+
 // KafkaProducer implements the Producer interface
 type KafkaProducer struct {
-	writer *kafka.Writer
-	logger *log.Logger
-	topic  string
+	writer       *kafka.Writer
+	logger       *log.Logger
+	topic        string
+	partitionKey string
 }
 
 // NewKafkaProducer creates a new KafkaProducer
@@ -72,11 +79,22 @@ func NewKafkaProducer(cfg config.KafkaProducerConfig, logger *log.Logger) (*Kafk
 		readTimeout = 5 * time.Second
 	}
 
+	// Keyed by org, messages of one org share a partition and keep their order
+	var balancer kafka.Balancer
+	switch cfg.PartitionKey {
+	case "", PartitionKeyRequestID:
+		balancer = &kafka.LeastBytes{}
+	case PartitionKeySourceOrgID:
+		balancer = &kafka.Hash{}
+	default:
+		return nil, fmt.Errorf("unsupported kafka producer partition_key '%s'", cfg.PartitionKey)
+	}
+
 	// Configure Kafka Writer
 	w := &kafka.Writer{
 		Addr:     kafka.TCP(cfg.Brokers...),
 		Topic:    cfg.Topic,
-		Balancer: &kafka.LeastBytes{},
+		Balancer: balancer,
 
 		BatchSize:    batchSize,
 		BatchTimeout: batchTimeout,
@@ -99,9 +117,10 @@ func NewKafkaProducer(cfg config.KafkaProducerConfig, logger *log.Logger) (*Kafk
 	logger.Printf("Kafka producer created, connected to Brokers: %v, Topic: %s", cfg.Brokers, cfg.Topic)
 
 	return &KafkaProducer{
-		writer: w,
-		logger: logger,
-		topic:  cfg.Topic,
+		writer:       w,
+		logger:       logger,
+		topic:        cfg.Topic,
+		partitionKey: cfg.PartitionKey,
 	}, nil
 }
 
@@ -113,8 +132,8 @@ func (p *KafkaProducer) Publish(ctx context.Context, msg *models.LogMessage) err
 	}
 
 	kafkaMsg := kafka.Message{
-		// Key can be used for partitioning strategy, using RequestID here
-		Key:   []byte(msg.RequestID),
+		// Key can be used for partitioning strategy, see messageKey
+		Key:   p.messageKey(msg),
 		Value: msgBytes,
 	}
 
@@ -144,7 +163,7 @@ func (p *KafkaProducer) PublishBatch(ctx context.Context, msgs []*models.LogMess
 		}
 
 		kafkaMsgs[i] = kafka.Message{
-			Key:   []byte(msg.RequestID),
+			Key:   p.messageKey(msg),
 			Value: msgBytes,
 		}
 	}
@@ -160,6 +179,14 @@ func (p *KafkaProducer) PublishBatch(ctx context.Context, msgs []*models.LogMess
 	return nil
 }
 
+// messageKey returns the Kafka message key for the configured partition key
+func (p *KafkaProducer) messageKey(msg *models.LogMessage) []byte {
+	if p.partitionKey == PartitionKeySourceOrgID {
+		return []byte(msg.SourceOrgID)
+	}
+	return []byte(msg.RequestID)
+}
+
 // Close closes the producer
 func (p *KafkaProducer) Close() error {
 	p.logger.Println("Closing Kafka producer (and flushing buffer)...")
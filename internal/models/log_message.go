@@ -1,5 +1,17 @@
 package models
 
+// Attestation modes for LogMessage.AttestationMode
+const (
+	AttestationModeFull     = "full"      // Hash, metadata and content are stored on chain
+	AttestationModeHashOnly = "hash_only" // Only hash and metadata are stored on chain; content stays off chain
+)
+
+// Content formats for LogMessage.ContentFormat
+const (
+	ContentFormatText = "text" // LogContent is an opaque string
+	ContentFormatJSON = "json" // LogContent is a JSON value in RFC 8785 canonical form
+)
+
 // LogMessage defines the message structure for log submissions
 // Used across ingestion, processing, and messaging layers
 type LogMessage struct {
@@ -8,4 +20,27 @@ type LogMessage struct {
 	LogHash           string `json:"LogHash"`
 	SourceOrgID       string `json:"SourceOrgID"`
 	ReceivedTimestamp string `json:"ReceivedTimestamp"` // Use string for easy JSON serialization
+
+	// Original event time reported by the client (RFC3339Nano), empty if none was sent
+	ClientTimestamp        string `json:"ClientTimestamp,omitempty"`
+	ClientTimestampFlagged bool   `json:"ClientTimestampFlagged,omitempty"` // Outside the allowed clock skew
+
+	// Empty means AttestationModeFull; with AttestationModeHashOnly LogContent is empty
+	AttestationMode string `json:"AttestationMode,omitempty"`
+
+	// Empty means ContentFormatText; kept for hash-only messages although LogContent is empty
+	ContentFormat string `json:"ContentFormat,omitempty"`
+
+	// Schema category of a structured log and the version it was validated against; empty and 0 if none
+	Category      string `json:"Category,omitempty"`
+	SchemaVersion int    `json:"SchemaVersion,omitempty"`
+
+	// Blob store locator of content above the offload threshold; LogContent is empty when set
+	ContentLocator string `json:"ContentLocator,omitempty"`
+
+	// Client signature over LogHash (unpadded base64url) and the registered key it verified with; empty if unsigned
+	Signature               string `json:"Signature,omitempty"`
+	SignatureKeyID          string `json:"SignatureKeyID,omitempty"`
+	SignatureAlgorithm      string `json:"SignatureAlgorithm,omitempty"`
+	SignatureKeyFingerprint string `json:"SignatureKeyFingerprint,omitempty"`
 }
\ No newline at end of file
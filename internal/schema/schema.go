@@ -0,0 +1,142 @@
+// Package schema compiles the JSON Schemas orgs register for their structured log categories
+// and validates canonical log JSON against them.
+// Schemas are self-contained: references to anything other than the built-in meta-schemas are refused,
+// so compiling a schema never reaches the network or the local filesystem.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// MaxFieldErrors bounds the field errors reported for one log
+const MaxFieldErrors = 20
+
+var (
+	// ErrInvalidCategory is returned for category names that do not match the category pattern
+	ErrInvalidCategory = errors.New("invalid category")
+
+	// ErrInvalidSchema is returned for documents that are not a valid, self-contained JSON Schema
+	ErrInvalidSchema = errors.New("invalid schema")
+
+	// ErrValidation is matched by every *ValidationError
+	ErrValidation = errors.New("log does not match its category schema")
+)
+
+// categoryPattern keeps category names usable in URLs and in the on-chain storage value
+var categoryPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// FieldError is one failed constraint; Field is a JSON Pointer into the log ("" for the whole log)
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the fields of a log that violate its category schema
+type ValidationError struct {
+	Category string
+	Version  int
+	Fields   []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		field := f.Field
+		if field == "" {
+			field = "(root)"
+		}
+		msgs[i] = field + ": " + f.Message
+	}
+	return fmt.Sprintf("%v (category %s, version %d): %s", ErrValidation, e.Category, e.Version, strings.Join(msgs, "; "))
+}
+
+// Is reports whether target is ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Schema is a compiled category schema
+type Schema struct {
+	Category string
+	Version  int
+	compiled *jsonschema.Schema
+}
+
+// ValidateCategory checks a category name: lowercase letters, digits, '_', '.' and '-', at most 64 characters
+func ValidateCategory(category string) error {
+	if !categoryPattern.MatchString(category) {
+		return fmt.Errorf("%w: '%s' (expected %s)", ErrInvalidCategory, category, categoryPattern.String())
+	}
+	return nil
+}
+
+// Compile compiles the schema document registered for an org's category.
+// Documents without "$schema" are treated as draft 2020-12.
+func Compile(orgID, category string, version int, document []byte) (*Schema, error) {
+	if err := ValidateCategory(category); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("tlng://schemas/%s/%s/%d.json", orgID, category, version)
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external reference %s is not allowed", s)
+	}
+	if err := compiler.AddResource(url, bytes.NewReader(document)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	return &Schema{Category: category, Version: version, compiled: compiled}, nil
+}
+
+// Validate checks a log against the schema. It returns a *ValidationError listing the
+// failed fields, or an error if logJSON is not JSON.
+func (s *Schema) Validate(logJSON []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(logJSON))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("failed to parse log JSON: %w", err)
+	}
+
+	err := s.compiled.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return fmt.Errorf("failed to validate log: %w", err)
+	}
+
+	result := &ValidationError{Category: s.Category, Version: s.Version}
+	collectFieldErrors(ve, result)
+	return result
+}
+
+// collectFieldErrors adds the leaves of a validation error tree; inner nodes only say
+// that a subschema failed and carry no information of their own
+func collectFieldErrors(ve *jsonschema.ValidationError, result *ValidationError) {
+	if len(result.Fields) >= MaxFieldErrors {
+		return
+	}
+	if len(ve.Causes) == 0 {
+		result.Fields = append(result.Fields, FieldError{Field: ve.InstanceLocation, Message: ve.Message})
+		return
+	}
+	for _, cause := range ve.Causes {
+		collectFieldErrors(cause, result)
+	}
+}
@@ -0,0 +1,127 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm2"
+	gmx509 "github.com/tjfoc/gmsm/x509"
+)
+
+// Algorithm names a client signature algorithm
+type Algorithm string
+
+// Supported signature algorithms. The signed message is the log hash as returned by the
+// ingestion service (bare hex for SHA-256, "<algorithm>:<hex>" otherwise), as UTF-8 bytes.
+const (
+	Ed25519   Algorithm = "ed25519"    // RFC 8032 signature over the message
+	ECDSAP256 Algorithm = "ecdsa-p256" // ASN.1 DER signature over SHA-256 of the message
+	SM2       Algorithm = "sm2"        // GB/T 32918 ASN.1 DER signature with the default user ID (SM3 digest)
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signature algorithm")
+	ErrInvalidPublicKey     = errors.New("invalid public key")
+	ErrInvalidKeyID         = errors.New("invalid key_id")
+	ErrInvalidSignature     = errors.New("invalid signature")
+)
+
+// keyIDPattern keeps key IDs usable as a path segment and in the on-chain key=value record
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// PublicKey is a parsed client public key
+type PublicKey struct {
+	Algorithm Algorithm
+	// Fingerprint is the hex SHA-256 of the DER SubjectPublicKeyInfo; it is recorded on chain
+	// so a third party can match a signature to the key the org publishes
+	Fingerprint string
+	key         interface{}
+}
+
+// ParseAlgorithm converts a client-supplied algorithm name
+func ParseAlgorithm(s string) (Algorithm, error) {
+	switch algorithm := Algorithm(strings.ToLower(strings.TrimSpace(s))); algorithm {
+	case Ed25519, ECDSAP256, SM2:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("%w: '%s' (expected %s, %s or %s)", ErrUnsupportedAlgorithm, s, Ed25519, ECDSAP256, SM2)
+	}
+}
+
+// ValidateKeyID checks a client-chosen key ID
+func ValidateKeyID(keyID string) error {
+	if !keyIDPattern.MatchString(keyID) {
+		return fmt.Errorf("%w: '%s' must match %s", ErrInvalidKeyID, keyID, keyIDPattern)
+	}
+	return nil
+}
+
+// ParsePublicKey parses a PEM "PUBLIC KEY" block (SubjectPublicKeyInfo) and checks it is a key of algorithm
+func ParsePublicKey(algorithm Algorithm, pemData []byte) (*PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%w: expected a PEM PUBLIC KEY block", ErrInvalidPublicKey)
+	}
+
+	var key interface{}
+	switch algorithm {
+	case Ed25519, ECDSAP256:
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+		}
+		switch k := parsed.(type) {
+		case ed25519.PublicKey:
+			if algorithm == Ed25519 {
+				key = k
+			}
+		case *ecdsa.PublicKey:
+			if algorithm == ECDSAP256 && k.Curve == elliptic.P256() {
+				key = k
+			}
+		}
+	case SM2:
+		parsed, err := gmx509.ParseSm2PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+		}
+		if parsed.X != nil && parsed.Curve.IsOnCurve(parsed.X, parsed.Y) {
+			key = parsed
+		}
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: not a %s key", ErrInvalidPublicKey, algorithm)
+	}
+
+	digest := sha256.Sum256(block.Bytes)
+	return &PublicKey{Algorithm: algorithm, Fingerprint: hex.EncodeToString(digest[:]), key: key}, nil
+}
+
+// Verify checks a signature over message
+func (k *PublicKey) Verify(message, signature []byte) error {
+	var ok bool
+	switch key := k.key.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, message, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		ok = ecdsa.VerifyASN1(key, digest[:], signature)
+	case *sm2.PublicKey:
+		ok = key.Verify(message, signature)
+	}
+	if !ok {
+		return fmt.Errorf("%w: signature does not verify with %s key %s", ErrInvalidSignature, k.Algorithm, k.Fingerprint)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/tjfoc/gmsm/sm2"
+	gmx509 "github.com/tjfoc/gmsm/x509"
+)
+
+const testMessage = "sm3:66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0"
+
+// testSigner is a client key pair of one algorithm
+type testSigner struct {
+	algorithm Algorithm
+	pem       []byte
+	sign      func(message []byte) []byte
+}
+
+func pemPublicKey(t *testing.T, der []byte, err error) []byte {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func newTestSigners(t *testing.T) []testSigner {
+	t.Helper()
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	smPrivate, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edDER, edErr := x509.MarshalPKIXPublicKey(edPublic)
+	ecDER, ecErr := x509.MarshalPKIXPublicKey(&ecPrivate.PublicKey)
+	smDER, smErr := gmx509.MarshalSm2PublicKey(&smPrivate.PublicKey)
+	return []testSigner{
+		{Ed25519, pemPublicKey(t, edDER, edErr), func(message []byte) []byte {
+			return ed25519.Sign(edPrivate, message)
+		}},
+		{ECDSAP256, pemPublicKey(t, ecDER, ecErr), func(message []byte) []byte {
+			digest := sha256.Sum256(message)
+			signature, err := ecdsa.SignASN1(rand.Reader, ecPrivate, digest[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signature
+		}},
+		{SM2, pemPublicKey(t, smDER, smErr), func(message []byte) []byte {
+			signature, err := smPrivate.Sign(rand.Reader, message, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signature
+		}},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for _, signer := range newTestSigners(t) {
+		t.Run(string(signer.algorithm), func(t *testing.T) {
+			key, err := ParsePublicKey(signer.algorithm, signer.pem)
+			if err != nil {
+				t.Fatalf("ParsePublicKey: %v", err)
+			}
+			if len(key.Fingerprint) != 64 {
+				t.Errorf("Fingerprint = %q, want hex SHA-256", key.Fingerprint)
+			}
+
+			signature := signer.sign([]byte(testMessage))
+			if err := key.Verify([]byte(testMessage), signature); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+			if err := key.Verify([]byte(testMessage[4:]), signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify of another message = %v, want %v", err, ErrInvalidSignature)
+			}
+			tampered := append([]byte(nil), signature...)
+			tampered[len(tampered)/2] ^= 0x01
+			if err := key.Verify([]byte(testMessage), tampered); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify of a tampered signature = %v, want %v", err, ErrInvalidSignature)
+			}
+			if err := key.Verify([]byte(testMessage), nil); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify of an empty signature = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestVerifyOtherSignersKey(t *testing.T) {
+	signers := newTestSigners(t)
+	other := newTestSigners(t)
+	for i, signer := range signers {
+		key, err := ParsePublicKey(signer.algorithm, other[i].pem)
+		if err != nil {
+			t.Fatalf("ParsePublicKey(%s): %v", signer.algorithm, err)
+		}
+		if err := key.Verify([]byte(testMessage), signer.sign([]byte(testMessage))); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Verify with another key = %v, want %v", signer.algorithm, err, ErrInvalidSignature)
+		}
+	}
+}
+
+func TestParsePublicKeyMismatch(t *testing.T) {
+	signers := newTestSigners(t)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384DER, p384Err := x509.MarshalPKIXPublicKey(&p384.PublicKey)
+
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+		pem       []byte
+		wantErr   error
+	}{
+		{"ed25519 as ecdsa", ECDSAP256, signers[0].pem, ErrInvalidPublicKey},
+		{"ecdsa as ed25519", Ed25519, signers[1].pem, ErrInvalidPublicKey},
+		{"ecdsa as sm2", SM2, signers[1].pem, ErrInvalidPublicKey},
+		{"sm2 as ecdsa", ECDSAP256, signers[2].pem, ErrInvalidPublicKey},
+		{"p384", ECDSAP256, pemPublicKey(t, p384DER, p384Err), ErrInvalidPublicKey},
+		{"not pem", Ed25519, []byte("not a key"), ErrInvalidPublicKey},
+		{"private key block", Ed25519, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}}), ErrInvalidPublicKey},
+		{"unknown algorithm", Algorithm("rsa"), signers[0].pem, ErrUnsupportedAlgorithm},
+	}
+	for _, tt := range tests {
+		if _, err := ParsePublicKey(tt.algorithm, tt.pem); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: ParsePublicKey = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Algorithm
+		wantErr bool
+	}{
+		{"ed25519", Ed25519, false},
+		{" ECDSA-P256 ", ECDSAP256, false},
+		{"SM2", SM2, false},
+		{"ecdsa", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAlgorithm(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAlgorithm(%q) = %q, %v", tt.input, got, err)
+		}
+		if err != nil && !errors.Is(err, ErrUnsupportedAlgorithm) {
+			t.Errorf("ParseAlgorithm(%q) = %v, want %v", tt.input, err, ErrUnsupportedAlgorithm)
+		}
+	}
+}
+
+func TestValidateKeyID(t *testing.T) {
+	valid := []string{"k1", "org1.signing-2025_01", "A"}
+	invalid := []string{"", "-k1", ".", "..", "k/1", "k:1", "k&v=2", "k 1", string(make([]byte, 65))}
+	for _, keyID := range valid {
+		if err := ValidateKeyID(keyID); err != nil {
+			t.Errorf("ValidateKeyID(%q): %v", keyID, err)
+		}
+	}
+	for _, keyID := range invalid {
+		if err := ValidateKeyID(keyID); !errors.Is(err, ErrInvalidKeyID) {
+			t.Errorf("ValidateKeyID(%q) = %v, want %v", keyID, err, ErrInvalidKeyID)
+		}
+	}
+}
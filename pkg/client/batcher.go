@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BatcherConfig defines how a Batcher groups entries
+type BatcherConfig struct {
+	MaxEntries int           // Entries per SubmitBatch call, at most the service's max_batch_entries; default 100
+	MaxDelay   time.Duration // Longest an entry waits for its batch to fill; default 1s
+
+	// OnResult is called with the outcome of every entry, from the batcher's goroutine, so it
+	// should not block. Entries carry their idempotency key for resubmission.
+	OnResult func(entry Entry, receipt *Receipt, err error)
+}
+
+// BatcherStats are the batcher counters
+type BatcherStats struct {
+	Submitted uint64 // Entries accepted by the service
+	Failed    uint64 // Entries rejected, or failed after the retries
+	Batches   uint64 // SubmitBatch calls
+}
+
+// Batcher collects entries from any number of goroutines and submits them with SubmitBatch
+// once MaxEntries are waiting or the oldest has waited MaxDelay
+type Batcher struct {
+	client  *Client
+	cfg     BatcherConfig
+	entries chan Entry
+
+	mu     sync.RWMutex // Guards closed against Add sending on a closed channel
+	closed bool
+
+	wg        sync.WaitGroup
+	submitted atomic.Uint64
+	failed    atomic.Uint64
+	batches   atomic.Uint64
+}
+
+// NewBatcher starts a batcher; Close submits what is left and stops it
+func (c *Client) NewBatcher(cfg BatcherConfig) *Batcher {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 100
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = time.Second
+	}
+
+	b := &Batcher{
+		client:  c,
+		cfg:     cfg,
+		entries: make(chan Entry, cfg.MaxEntries),
+	}
+	b.wg.Add(1)
+	go b.run()
+	return b
+}
+
+// Add queues an entry, waiting while a full batch is being submitted. Its idempotency key is
+// assigned here if it has none.
+func (b *Batcher) Add(ctx context.Context, entry Entry) error {
+	if _, err := entry.Content.Canonical(); err != nil {
+		return err
+	}
+	if entry.IdempotencyKey == "" {
+		entry.IdempotencyKey = uuid.NewString()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrBatcherClosed
+	}
+	select {
+	case b.entries <- entry:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close submits the entries still queued and waits for their results
+func (b *Batcher) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.entries)
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+// Stats returns the batcher counters
+func (b *Batcher) Stats() BatcherStats {
+	return BatcherStats{
+		Submitted: b.submitted.Load(),
+		Failed:    b.failed.Load(),
+		Batches:   b.batches.Load(),
+	}
+}
+
+func (b *Batcher) run() {
+	defer b.wg.Done()
+
+	timer := time.NewTimer(b.cfg.MaxDelay)
+	timer.Stop()
+	var batch []Entry
+	for {
+		select {
+		case entry, ok := <-b.entries:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(b.cfg.MaxDelay)
+			}
+			batch = append(batch, entry)
+			if len(batch) < b.cfg.MaxEntries {
+				continue
+			}
+			timer.Stop()
+		case <-timer.C:
+		}
+		b.flush(batch)
+		batch = nil
+	}
+}
+
+// flush submits a batch and reports the outcome of each entry
+func (b *Batcher) flush(batch []Entry) {
+	if len(batch) == 0 {
+		return
+	}
+	b.batches.Add(1)
+
+	results, err := b.client.SubmitBatch(context.Background(), batch)
+	for i, entry := range batch {
+		var receipt *Receipt
+		entryErr := err
+		if err == nil {
+			receipt, entryErr = results[i].Receipt, results[i].Err
+		}
+		if entryErr != nil {
+			b.failed.Add(1)
+		} else {
+			b.submitted.Add(1)
+		}
+		if b.cfg.OnResult != nil {
+			b.cfg.OnResult(entry, receipt, entryErr)
+		}
+	}
+}
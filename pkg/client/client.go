@@ -0,0 +1,236 @@
+// Package client is the Go SDK of the log attestation service. It submits logs over the HTTP or
+// gRPC ingestion API, queries their status and audits them on chain through the query API.
+// Submissions carry idempotency keys and are retried on transient failures, and every log hash
+// the services return is checked against the hash computed locally.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tlng/config"
+	"tlng/internal/hashing"
+	"tlng/internal/tlsconfig"
+	pb "tlng/proto/logingestion"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Retry delays of failed calls; a longer Retry-After of the service is honored
+const (
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
+// maxErrorBody bounds the error response read into APIError.Message
+const maxErrorBody = 64 * 1024
+
+// Config defines how a client reaches and authenticates to the services
+type Config struct {
+	BaseURL       string                 `yaml:"base_url"`       // HTTP API, e.g. https://logchain.example.com for the nginx ingress
+	GRPCAddress   string                 `yaml:"grpc_address"`   // host:port of the gRPC ingestion API; logs are submitted over HTTP if empty
+	APIKey        string                 `yaml:"api_key"`        // Sent as X-API-Key and x-api-key metadata
+	TLS           config.ClientTLSConfig `yaml:"tls"`            // CA of the services, client certificate for mTLS (audit API)
+	OrgID         string                 `yaml:"org_id"`         // client_source_org_id of submissions; empty uses the authenticated org
+	HashAlgorithm string                 `yaml:"hash_algorithm"` // Hash algorithm of submissions and content queries; empty uses the org's
+	Timeout       time.Duration          `yaml:"timeout"`        // Deadline of each attempt, including the wait for the ack level; default 30s
+	MaxRetries    int                    `yaml:"max_retries"`    // Attempts after the first; default 3, negative disables retries
+}
+
+// Client calls the ingestion and query APIs. It is safe for concurrent use.
+type Client struct {
+	cfg        Config
+	baseURL    string
+	httpClient *http.Client
+	conn       *grpc.ClientConn // nil without GRPCAddress
+	grpc       pb.LogIngestionClient
+}
+
+// New creates a client; connections are established on first use
+func New(cfg Config) (*Client, error) {
+	// 1. Defaults and validation
+	if cfg.BaseURL == "" && cfg.GRPCAddress == "" {
+		return nil, errors.New("base_url or grpc_address is required")
+	}
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		return nil, errors.New("tls: cert_file and key_file must be set together")
+	}
+	if cfg.HashAlgorithm != "" {
+		if _, err := hashing.ParseAlgorithm(cfg.HashAlgorithm); err != nil {
+			return nil, fmt.Errorf("hash_algorithm: %w", err)
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+
+	c := &Client{
+		cfg:     cfg,
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+	}
+
+	// 2. TLS towards both APIs
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	creds := insecure.NewCredentials()
+	if cfg.TLS.Enabled {
+		tlsConfig, err := tlsconfig.NewClient(cfg.TLS.CAFile, cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ServerName)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	c.httpClient = &http.Client{Transport: transport}
+
+	// 3. gRPC ingestion API
+	if cfg.GRPCAddress != "" {
+		conn, err := grpc.NewClient(cfg.GRPCAddress, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC client for '%s': %w", cfg.GRPCAddress, err)
+		}
+		c.conn = conn
+		c.grpc = pb.NewLogIngestionClient(conn)
+	}
+
+	return c, nil
+}
+
+// Close releases the connections of the client
+func (c *Client) Close() error {
+	c.httpClient.CloseIdleConnections()
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// retry runs call until it succeeds, fails with an error that is not transient, or the retries
+// are used up. Delays double from 0.5s up to 30s, or follow the Retry-After of the service.
+func (c *Client) retry(ctx context.Context, call func(ctx context.Context) error) error {
+	delay := minRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(ctx, call)
+		if err == nil || !retryable(err) || attempt >= c.cfg.MaxRetries {
+			return err
+		}
+		if err := sleep(ctx, max(delay, retryAfter(err))); err != nil {
+			return err
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// attempt runs call with the deadline of one attempt. An attempt that times out while the
+// caller's context is still live is reported as ErrUnavailable, so it is retried.
+func (c *Client) attempt(ctx context.Context, call func(ctx context.Context) error) error {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	err := call(attemptCtx)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var apiErr *APIError
+	if err != nil && !errors.As(err, &apiErr) && !errors.Is(err, ErrInvalid) && !errors.Is(err, ErrHashMismatch) {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return err
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// doJSON sends one HTTP request with a JSON body, if any, and decodes a JSON response into out.
+// Error responses are returned as *APIError.
+func (c *Client) doJSON(ctx context.Context, method, path string, header http.Header, body, out any) error {
+	if c.baseURL == "" {
+		return fmt.Errorf("%w: base_url is not configured", ErrInvalid)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("%w: failed to encode request: %v", ErrInvalid, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("X-API-Key", c.cfg.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return httpError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// httpError builds the APIError of an error response. Services answer with
+// {"error": "..."}; the ingress may answer with {"message": "..."} or plain text.
+func httpError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var body struct {
+		Error       string       `json:"error"`
+		Message     string       `json:"message"`
+		FieldErrors []FieldError `json:"field_errors"`
+	}
+	switch {
+	case json.Unmarshal(data, &body) == nil && body.Error != "":
+		apiErr.Message = body.Error
+		apiErr.FieldErrors = body.FieldErrors
+	case body.Message != "":
+		apiErr.Message = body.Message
+	case len(bytes.TrimSpace(data)) > 0 && !bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")):
+		apiErr.Message = string(bytes.TrimSpace(data))
+	default:
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
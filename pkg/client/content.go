@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"tlng/internal/hashing"
+	"tlng/internal/jcs"
+)
+
+// Content is a log as it is submitted: a plain text line, or a structured JSON document
+type Content struct {
+	Text string
+	JSON json.RawMessage // Structured log instead of Text, in any serialization
+}
+
+// Text returns the content of a plain text log
+func Text(text string) Content {
+	return Content{Text: text}
+}
+
+// JSON returns the content of a structured log
+func JSON(document []byte) Content {
+	return Content{JSON: document}
+}
+
+// Canonical returns the bytes the service hashes: Text as is, or JSON in its RFC 8785
+// canonical form, so any serialization of the same document has the same hash
+func (c Content) Canonical() ([]byte, error) {
+	if len(c.JSON) == 0 {
+		if c.Text == "" {
+			return nil, fmt.Errorf("%w: log content is empty", ErrInvalid)
+		}
+		return []byte(c.Text), nil
+	}
+	if c.Text != "" {
+		return nil, fmt.Errorf("%w: text and JSON content are mutually exclusive", ErrInvalid)
+	}
+
+	canonical, err := jcs.Canonicalize(c.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("%w: log_json: %v", ErrInvalid, err)
+	}
+	return canonical, nil
+}
+
+// Hash returns the log hash the service computes for the content with algorithm (sha256,
+// sha3-256 or sm3; empty is sha256). SHA-256 hashes are bare hex, others are "<algorithm>:<hex>".
+func (c Content) Hash(algorithm string) (string, error) {
+	alg := hashing.Default
+	if algorithm != "" {
+		var err error
+		if alg, err = hashing.ParseAlgorithm(algorithm); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+
+	canonical, err := c.Canonical()
+	if err != nil {
+		return "", err
+	}
+	return hashing.Sum(alg, canonical)
+}
+
+// check verifies that logHash, as returned by a service, is the hash of the content in the
+// algorithm it is tagged with
+func (c Content) check(logHash string) error {
+	algorithm, _, err := hashing.Parse(logHash, hashing.SHA256)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrHashMismatch, err)
+	}
+	computed, err := c.Hash(string(algorithm))
+	if err != nil {
+		return err
+	}
+	if computed != logHash {
+		return fmt.Errorf("%w: service '%s', computed '%s'", ErrHashMismatch, logHash, computed)
+	}
+	return nil
+}
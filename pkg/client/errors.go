@@ -0,0 +1,112 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Error kinds. Errors of failed calls wrap one of them, or are the caller's context error, so
+// callers can branch with errors.Is whichever API the call went to.
+var (
+	ErrInvalid         = errors.New("invalid request")                     // HTTP 400, 413; gRPC INVALID_ARGUMENT
+	ErrUnauthenticated = errors.New("not authenticated")                   // HTTP 401; gRPC UNAUTHENTICATED
+	ErrPermission      = errors.New("permission denied")                   // HTTP 403; gRPC PERMISSION_DENIED
+	ErrNotFound        = errors.New("not found")                           // HTTP 404; gRPC NOT_FOUND
+	ErrConflict        = errors.New("idempotency key already used")        // HTTP 409; gRPC ALREADY_EXISTS
+	ErrThrottled       = errors.New("throttled")                           // HTTP 429; gRPC RESOURCE_EXHAUSTED
+	ErrUnavailable     = errors.New("service unavailable")                 // HTTP 502, 503, 504; gRPC UNAVAILABLE, DEADLINE_EXCEEDED; connection failures
+	ErrServer          = errors.New("server error")                        // HTTP 500 and other codes; gRPC INTERNAL and others
+	ErrHashMismatch    = errors.New("log hash does not match the content") // The service returned a hash other than the one computed locally
+)
+
+// ErrBatcherClosed is returned by Batcher.Add after Close
+var ErrBatcherClosed = errors.New("batcher is closed")
+
+// FieldError is a field of a structured log that violates its category schema
+type FieldError struct {
+	Field   string `json:"field"` // JSON Pointer into the log, "" for the whole log
+	Message string `json:"message"`
+}
+
+// APIError is an error response of a service, or the error of a batch entry
+type APIError struct {
+	StatusCode  int           // HTTP status; 0 for gRPC calls
+	Code        codes.Code    // gRPC status; codes.OK for HTTP calls
+	Message     string        // Error message of the service
+	FieldErrors []FieldError  // Schema violations of a structured log
+	RetryAfter  time.Duration // Wait the service asked for before a retry
+}
+
+func (e *APIError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("gRPC %s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the error kind, ErrInvalid to ErrServer
+func (e *APIError) Unwrap() error {
+	if e.StatusCode != 0 {
+		return httpKind(e.StatusCode)
+	}
+	return grpcKind(e.Code)
+}
+
+func httpKind(statusCode int) error {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return ErrInvalid
+	case http.StatusUnauthorized:
+		return ErrUnauthenticated
+	case http.StatusForbidden:
+		return ErrPermission
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrThrottled
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ErrUnavailable
+	}
+	return ErrServer
+}
+
+func grpcKind(code codes.Code) error {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return ErrInvalid
+	case codes.Unauthenticated:
+		return ErrUnauthenticated
+	case codes.PermissionDenied:
+		return ErrPermission
+	case codes.NotFound:
+		return ErrNotFound
+	case codes.AlreadyExists:
+		return ErrConflict
+	case codes.ResourceExhausted:
+		return ErrThrottled
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return ErrUnavailable
+	}
+	return ErrServer
+}
+
+// retryable reports whether a call that failed with err may be sent again. Submissions carry
+// their idempotency key, so only failures the request itself caused are final.
+func retryable(err error) bool {
+	return errors.Is(err, ErrThrottled) || errors.Is(err, ErrUnavailable) || errors.Is(err, ErrServer)
+}
+
+// retryAfter returns the wait a throttled service asked for, or zero
+func retryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
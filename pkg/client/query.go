@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// LogStatus is the state DB record of a log, returned by the query API to its org
+type LogStatus struct {
+	RequestID              string     `json:"request_id"`
+	LogHash                string     `json:"log_hash"`
+	SourceOrgID            string     `json:"source_org_id"`
+	Status                 string     `json:"status"`
+	ReceivedTimestamp      time.Time  `json:"received_timestamp"`
+	ClientTimestamp        *time.Time `json:"client_timestamp,omitempty"`
+	ClientTimestampFlagged bool       `json:"client_timestamp_flagged,omitempty"`
+	ProcessingStartedAt    *time.Time `json:"processing_started_at,omitempty"`
+	ProcessingFinishedAt   *time.Time `json:"processing_finished_at,omitempty"`
+	TxHash                 string     `json:"tx_hash,omitempty"`
+	BlockHeight            int64      `json:"block_height,omitempty"`
+	BlockTimestamp         *time.Time `json:"block_timestamp,omitempty"`
+	ErrorMessage           string     `json:"error_message,omitempty"`
+	AttestationMode        string     `json:"attestation_mode,omitempty"`
+	ContentFormat          string     `json:"content_format,omitempty"`
+	Category               string     `json:"category,omitempty"`
+	SchemaVersion          int        `json:"schema_version,omitempty"`
+	Erased                 bool       `json:"erased,omitempty"`
+	ErasedAt               *time.Time `json:"erased_at,omitempty"`
+}
+
+// OnChainLog is the on-chain record of a log, returned by the audit API to consortium members
+type OnChainLog struct {
+	Source           string         `json:"source"`
+	LogHash          string         `json:"log_hash"`
+	LogContent       string         `json:"log_content,omitempty"` // Omitted for hash-only records and for encrypted content the caller may not read
+	ContentEncrypted bool           `json:"content_encrypted,omitempty"`
+	SenderOrgID      string         `json:"sender_org_id"`
+	Timestamp        string         `json:"timestamp"`
+	ClientTimestamp  string         `json:"client_timestamp,omitempty"`
+	AttestationMode  string         `json:"attestation_mode"`
+	ContentFormat    string         `json:"content_format,omitempty"` // "json" content is in RFC 8785 canonical form
+	Category         string         `json:"category,omitempty"`
+	SchemaVersion    int            `json:"schema_version,omitempty"`
+	ContentLocator   string         `json:"content_locator,omitempty"`
+	Signature        *SignatureInfo `json:"signature,omitempty"`
+	Erased           bool           `json:"erased,omitempty"`
+	ErasedAt         *time.Time     `json:"erased_at,omitempty"`
+	ErasureTxHash    string         `json:"erasure_tx_hash,omitempty"`
+}
+
+// SignatureInfo is the client signature of an on-chain record
+type SignatureInfo struct {
+	SignerOrgID    string     `json:"signer_org_id"`
+	KeyID          string     `json:"key_id"`
+	Algorithm      string     `json:"algorithm"`
+	KeyFingerprint string     `json:"key_fingerprint"`
+	Signature      string     `json:"signature"` // Base64
+	SignedMessage  string     `json:"signed_message"`
+	PublicKey      string     `json:"public_key,omitempty"` // PEM
+	KeyRevokedAt   *time.Time `json:"key_revoked_at,omitempty"`
+	Verified       *bool      `json:"verified,omitempty"`
+}
+
+// TxAudit is the on-chain record of every log anchored in a transaction
+type TxAudit struct {
+	TxHash         string        `json:"tx_hash"`
+	BlockHeight    int64         `json:"block_height,omitempty"`
+	BlockTimestamp *time.Time    `json:"block_timestamp,omitempty"`
+	Logs           []*OnChainLog `json:"logs"` // In the order the logs were received
+}
+
+// ContentVerification is the result of checking content against the chain; it never contains
+// the on-chain content
+type ContentVerification struct {
+	Source          string     `json:"source"`
+	Verified        bool       `json:"verified"`
+	LogHash         string     `json:"log_hash"`
+	HashAlgorithm   string     `json:"hash_algorithm"`
+	SenderOrgID     string     `json:"sender_org_id"`
+	Timestamp       string     `json:"timestamp"`
+	ClientTimestamp string     `json:"client_timestamp,omitempty"`
+	AttestationMode string     `json:"attestation_mode"`
+	ContentFormat   string     `json:"content_format"`
+	Category        string     `json:"category,omitempty"`
+	SchemaVersion   int        `json:"schema_version,omitempty"`
+	Erased          bool       `json:"erased,omitempty"`
+	ErasedAt        *time.Time `json:"erased_at,omitempty"`
+}
+
+// Verification is the result of Verify
+type Verification struct {
+	Verified bool                 `json:"verified"` // Recorded, anchored, and the chain agrees with the content
+	LogHash  string               `json:"log_hash"`
+	Status   *LogStatus           `json:"status"`             // State DB record
+	OnChain  *ContentVerification `json:"on_chain,omitempty"` // On-chain check, once the log is COMPLETED
+}
+
+// contentRequest is the body of the content query and verify APIs
+type contentRequest struct {
+	LogContent    string          `json:"log_content,omitempty"`
+	LogJSON       json.RawMessage `json:"log_json,omitempty"`
+	LogHash       string          `json:"log_hash,omitempty"`
+	HashAlgorithm string          `json:"hash_algorithm,omitempty"`
+}
+
+// Status returns the record of a submission by its request_id (GET /v1/query/status/{request_id},
+// API key)
+func (c *Client) Status(ctx context.Context, requestID string) (*LogStatus, error) {
+	if requestID == "" {
+		return nil, fmt.Errorf("%w: request_id is required", ErrInvalid)
+	}
+
+	var status LogStatus
+	err := c.retry(ctx, func(ctx context.Context) error {
+		return c.doJSON(ctx, http.MethodGet, "/v1/query/status/"+url.PathEscape(requestID), nil, nil, &status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// QueryByContent returns the record of a log of the caller's org by its content
+// (POST /v1/query_by_content, API key). The content is hashed with HashAlgorithm, or with every
+// algorithm starting with the org's if it is empty.
+func (c *Client) QueryByContent(ctx context.Context, content Content) (*LogStatus, error) {
+	if _, err := content.Canonical(); err != nil {
+		return nil, err
+	}
+	request := &contentRequest{LogContent: content.Text, LogJSON: content.JSON, HashAlgorithm: c.cfg.HashAlgorithm}
+
+	var status LogStatus
+	err := c.retry(ctx, func(ctx context.Context) error {
+		return c.doJSON(ctx, http.MethodPost, "/v1/query_by_content", nil, request, &status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := content.check(status.LogHash); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// AuditLog returns the on-chain record of a log hash (GET /v1/audit/log/{log_hash}, mTLS)
+func (c *Client) AuditLog(ctx context.Context, logHash string) (*OnChainLog, error) {
+	if logHash == "" {
+		return nil, fmt.Errorf("%w: log_hash is required", ErrInvalid)
+	}
+
+	var record OnChainLog
+	err := c.retry(ctx, func(ctx context.Context) error {
+		return c.doJSON(ctx, http.MethodGet, "/v1/audit/log/"+url.PathEscape(logHash), nil, nil, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// AuditTx returns the on-chain records of the logs anchored in a transaction
+// (GET /log/by_tx/{tx_hash}, mTLS)
+func (c *Client) AuditTx(ctx context.Context, txHash string) (*TxAudit, error) {
+	if txHash == "" {
+		return nil, fmt.Errorf("%w: tx_hash is required", ErrInvalid)
+	}
+
+	var audit TxAudit
+	err := c.retry(ctx, func(ctx context.Context) error {
+		return c.doJSON(ctx, http.MethodGet, "/log/by_tx/"+url.PathEscape(txHash), nil, nil, &audit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// AuditVerify checks content against the chain without revealing on-chain content
+// (POST /v1/audit/verify, mTLS). With logHash, only that record is checked.
+func (c *Client) AuditVerify(ctx context.Context, content Content, logHash string) (*ContentVerification, error) {
+	if _, err := content.Canonical(); err != nil {
+		return nil, err
+	}
+	request := &contentRequest{LogContent: content.Text, LogJSON: content.JSON, LogHash: logHash}
+	if logHash == "" {
+		request.HashAlgorithm = c.cfg.HashAlgorithm
+	}
+
+	var verification ContentVerification
+	err := c.retry(ctx, func(ctx context.Context) error {
+		return c.doJSON(ctx, http.MethodPost, "/v1/audit/verify", nil, request, &verification)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// Verify checks a log end to end: its state DB record is looked up by content, its hash is
+// recomputed locally, and once it is COMPLETED the chain is asked to confirm the content under
+// that hash. It needs an API key for the query API and a client certificate for the audit API.
+// A log that is not anchored yet is returned with Verified false and no OnChain result.
+func (c *Client) Verify(ctx context.Context, content Content) (*Verification, error) {
+	// 1. State DB record; QueryByContent checks its hash
+	status, err := c.QueryByContent(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	verification := &Verification{LogHash: status.LogHash, Status: status}
+	if status.Status != StatusCompleted {
+		return verification, nil
+	}
+
+	// 2. On-chain record under the same hash
+	onChain, err := c.AuditVerify(ctx, content, status.LogHash)
+	if err != nil {
+		return nil, err
+	}
+	verification.OnChain = onChain
+	verification.Verified = onChain.Verified && onChain.LogHash == status.LogHash
+	return verification, nil
+}
@@ -0,0 +1,428 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	pb "tlng/proto/logingestion"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Ack levels of a submission
+const (
+	AckAccepted = "accepted" // Handed to the batch processor
+	AckDurable  = "durable"  // In the state DB and Kafka, or the state DB's outbox if the service uses one
+	AckAttested = "attested" // Anchored on chain, or the ack timeout passed
+)
+
+// Submission and query statuses
+const (
+	StatusAccepted   = "ACCEPTED"
+	StatusDurable    = "DURABLE"
+	StatusReceived   = "RECEIVED"
+	StatusProcessing = "PROCESSING"
+	StatusCompleted  = "COMPLETED" // Anchored on chain
+	StatusFailed     = "FAILED"
+)
+
+// Entry is a log to submit
+type Entry struct {
+	Content         Content
+	ClientTimestamp time.Time     // When the log was produced; omitted if zero
+	AckLevel        string        // accepted (default), durable or attested
+	AckTimeout      time.Duration // Longest wait for attested; the service default if zero
+	AttestationMode string        // full or hash_only; the org's mode if empty
+	Category        string        // Schema category of a structured log
+	Signature       []byte        // Client signature over the log hash, see Content.Hash
+	SignatureKeyID  string        // Registered key the signature verifies with
+
+	// IdempotencyKey deduplicates retries and resubmissions. A random key is assigned if it is
+	// empty; set it to a key derived from the log's origin to deduplicate across restarts.
+	IdempotencyKey string
+}
+
+// Receipt is the result of an accepted submission
+type Receipt struct {
+	RequestID               string    `json:"request_id"`
+	LogHash                 string    `json:"server_log_hash"`
+	ReceivedAt              time.Time `json:"server_received_timestamp"`
+	Status                  string    `json:"status"`
+	IdempotencyKey          string    `json:"idempotency_key,omitempty"`
+	IdempotentReplay        bool      `json:"idempotent_replay,omitempty"` // An earlier submission with the same key
+	ClientTimestampFlagged  bool      `json:"client_timestamp_flagged,omitempty"`
+	AttestationMode         string    `json:"attestation_mode,omitempty"`
+	ContentFormat           string    `json:"content_format,omitempty"`
+	Category                string    `json:"category,omitempty"`
+	SchemaVersion           int       `json:"schema_version,omitempty"`
+	ContentLocator          string    `json:"content_locator,omitempty"`
+	SignatureKeyID          string    `json:"signature_key_id,omitempty"`
+	SignatureAlgorithm      string    `json:"signature_algorithm,omitempty"`
+	SignatureKeyFingerprint string    `json:"signature_key_fingerprint,omitempty"`
+	TxHash                  string    `json:"tx_hash,omitempty"`      // COMPLETED only
+	BlockHeight             int64     `json:"block_height,omitempty"` // COMPLETED only
+	ErrorMessage            string    `json:"error_message,omitempty"`
+}
+
+// BatchResult is the outcome of one entry of a batch: a receipt or an error. Both are set if
+// the receipt's hash is not the entry's (ErrHashMismatch).
+type BatchResult struct {
+	Receipt *Receipt
+	Err     error
+}
+
+// submission is an entry prepared for sending: its key is fixed, and its hash computed if the
+// client selects the algorithm
+type submission struct {
+	entry   *Entry
+	key     string
+	logHash string
+}
+
+// Submit submits one log over gRPC if GRPCAddress is set, over HTTP otherwise. Transient
+// failures are retried under the same idempotency key, so the log is recorded once.
+func (c *Client) Submit(ctx context.Context, entry Entry) (*Receipt, error) {
+	sub, err := c.prepare(&entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt *Receipt
+	err = c.retry(ctx, func(ctx context.Context) error {
+		var err error
+		if c.grpc != nil {
+			receipt, err = c.submitGRPC(ctx, sub)
+		} else {
+			receipt, err = c.submitHTTP(ctx, sub)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	receipt.IdempotencyKey = sub.key
+	if err := entry.Content.check(receipt.LogHash); err != nil {
+		return receipt, err
+	}
+	return receipt, nil
+}
+
+// SubmitBatch submits logs in one call (at most the service's max_batch_entries) and returns
+// a result per entry, in order. Entries that fail transiently are sent again under their keys.
+// Entries without an idempotency key are given one in place, so a batch can be resubmitted
+// safely after an error. An error is returned only if no attempt reached the service.
+func (c *Client) SubmitBatch(ctx context.Context, entries []Entry) ([]BatchResult, error) {
+	// 1. Prepare every entry; invalid ones fail locally
+	results := make([]BatchResult, len(entries))
+	var pending []int
+	subs := make([]*submission, len(entries))
+	for i := range entries {
+		sub, err := c.prepare(&entries[i])
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		subs[i] = sub
+		pending = append(pending, i)
+	}
+
+	// 2. Send the pending entries until none is left to retry
+	delay := minRetryDelay
+	reached := false
+	for attempt := 0; len(pending) > 0; attempt++ {
+		batch := make([]*submission, len(pending))
+		for j, i := range pending {
+			batch[j] = subs[i]
+		}
+
+		var outcomes []BatchResult
+		err := c.attempt(ctx, func(ctx context.Context) error {
+			var err error
+			if c.grpc != nil {
+				outcomes, err = c.submitBatchGRPC(ctx, batch)
+			} else {
+				outcomes, err = c.submitBatchHTTP(ctx, batch)
+			}
+			return err
+		})
+
+		var retry []int
+		if err != nil {
+			if !reached && (!retryable(err) || attempt >= c.cfg.MaxRetries) {
+				return nil, err
+			}
+			for _, i := range pending {
+				results[i].Err = err
+			}
+			if retryable(err) {
+				retry = pending
+			}
+		} else {
+			reached = true
+			for j, i := range pending {
+				results[i] = outcomes[j]
+				if outcomes[j].Err != nil && retryable(outcomes[j].Err) {
+					retry = append(retry, i)
+				}
+			}
+		}
+		if len(retry) == 0 || attempt >= c.cfg.MaxRetries {
+			break
+		}
+
+		wait := delay
+		for _, i := range retry {
+			wait = max(wait, retryAfter(results[i].Err))
+		}
+		if err := sleep(ctx, wait); err != nil {
+			for _, i := range retry {
+				results[i].Err = err
+			}
+			break
+		}
+		delay = min(delay*2, maxRetryDelay)
+		pending = retry
+	}
+
+	// 3. Check the hashes of the accepted entries
+	for i, result := range results {
+		if result.Receipt == nil {
+			continue
+		}
+		result.Receipt.IdempotencyKey = subs[i].key
+		if err := entries[i].Content.check(result.Receipt.LogHash); err != nil {
+			results[i].Err = err
+		}
+	}
+	return results, nil
+}
+
+// prepare assigns the idempotency key of an entry and, if the client selects the hash
+// algorithm, computes the hash the service must agree with
+func (c *Client) prepare(entry *Entry) (*submission, error) {
+	if entry.IdempotencyKey == "" {
+		entry.IdempotencyKey = uuid.NewString()
+	}
+	sub := &submission{entry: entry, key: entry.IdempotencyKey}
+
+	if _, err := entry.Content.Canonical(); err != nil {
+		return nil, err
+	}
+	if c.cfg.HashAlgorithm != "" {
+		logHash, err := entry.Content.Hash(c.cfg.HashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		sub.logHash = logHash
+	}
+	return sub, nil
+}
+
+// logPayload is the JSON form of a submission
+type logPayload struct {
+	LogContent        string          `json:"log_content,omitempty"`
+	LogJSON           json.RawMessage `json:"log_json,omitempty"`
+	ClientLogHash     string          `json:"client_log_hash,omitempty"`
+	ClientSourceOrgID string          `json:"client_source_org_id,omitempty"`
+	ClientTimestamp   string          `json:"client_timestamp,omitempty"`
+	AckLevel          string          `json:"ack_level,omitempty"`
+	AckTimeoutMs      int64           `json:"ack_timeout_ms,omitempty"`
+	IdempotencyKey    string          `json:"idempotency_key,omitempty"`
+	HashAlgorithm     string          `json:"hash_algorithm,omitempty"`
+	AttestationMode   string          `json:"attestation_mode,omitempty"`
+	Category          string          `json:"category,omitempty"`
+	Signature         []byte          `json:"signature,omitempty"`
+	SignatureKeyID    string          `json:"signature_key_id,omitempty"`
+}
+
+func (c *Client) httpPayload(sub *submission) *logPayload {
+	entry := sub.entry
+	payload := &logPayload{
+		LogContent:        entry.Content.Text,
+		LogJSON:           entry.Content.JSON,
+		ClientLogHash:     sub.logHash,
+		ClientSourceOrgID: c.cfg.OrgID,
+		AckLevel:          entry.AckLevel,
+		AckTimeoutMs:      entry.AckTimeout.Milliseconds(),
+		HashAlgorithm:     c.cfg.HashAlgorithm,
+		AttestationMode:   entry.AttestationMode,
+		Category:          entry.Category,
+		Signature:         entry.Signature,
+		SignatureKeyID:    entry.SignatureKeyID,
+	}
+	if !entry.ClientTimestamp.IsZero() {
+		payload.ClientTimestamp = entry.ClientTimestamp.Format(time.RFC3339Nano)
+	}
+	return payload
+}
+
+// submitHTTP sends POST /v1/logs; the idempotency key goes in the Idempotency-Key header
+func (c *Client) submitHTTP(ctx context.Context, sub *submission) (*Receipt, error) {
+	header := http.Header{"Idempotency-Key": {sub.key}}
+	var receipt Receipt
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/logs", header, c.httpPayload(sub), &receipt); err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// submitBatchHTTP sends POST /v1/logs/batch
+func (c *Client) submitBatchHTTP(ctx context.Context, batch []*submission) ([]BatchResult, error) {
+	request := struct {
+		Entries []*logPayload `json:"entries"`
+	}{Entries: make([]*logPayload, len(batch))}
+	for i, sub := range batch {
+		request.Entries[i] = c.httpPayload(sub)
+		request.Entries[i].IdempotencyKey = sub.key
+	}
+
+	var response struct {
+		Results []struct {
+			Receipt
+			Index       int          `json:"index"`
+			Error       string       `json:"error"`
+			StatusCode  int          `json:"status_code"`
+			FieldErrors []FieldError `json:"field_errors"`
+		} `json:"results"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/logs/batch", nil, request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(batch) {
+		return nil, fmt.Errorf("%d results for %d entries", len(response.Results), len(batch))
+	}
+
+	results := make([]BatchResult, len(batch))
+	for _, result := range response.Results {
+		if result.Index < 0 || result.Index >= len(batch) {
+			return nil, fmt.Errorf("result for entry %d of %d", result.Index, len(batch))
+		}
+		if result.Error != "" {
+			statusCode := result.StatusCode
+			if statusCode == 0 { // Services that predate status_code
+				statusCode = http.StatusInternalServerError
+			}
+			results[result.Index].Err = &APIError{StatusCode: statusCode, Message: result.Error, FieldErrors: result.FieldErrors}
+			continue
+		}
+		receipt := result.Receipt
+		results[result.Index].Receipt = &receipt
+	}
+	return results, nil
+}
+
+func (c *Client) grpcRequest(sub *submission) *pb.SubmitLogRequest {
+	entry := sub.entry
+	request := &pb.SubmitLogRequest{
+		LogContent:        entry.Content.Text,
+		LogJson:           string(entry.Content.JSON),
+		ClientLogHash:     sub.logHash,
+		ClientSourceOrgId: c.cfg.OrgID,
+		AckLevel:          entry.AckLevel,
+		AckTimeoutMs:      uint32(entry.AckTimeout.Milliseconds()),
+		IdempotencyKey:    sub.key,
+		HashAlgorithm:     c.cfg.HashAlgorithm,
+		AttestationMode:   entry.AttestationMode,
+		Category:          entry.Category,
+		Signature:         entry.Signature,
+		SignatureKeyId:    entry.SignatureKeyID,
+	}
+	if !entry.ClientTimestamp.IsZero() {
+		request.ClientTimestamp = timestamppb.New(entry.ClientTimestamp)
+	}
+	return request
+}
+
+// grpcContext adds the API key to the metadata of a call
+func (c *Client) grpcContext(ctx context.Context) context.Context {
+	if c.cfg.APIKey != "" {
+		return metadata.AppendToOutgoingContext(ctx, "x-api-key", c.cfg.APIKey)
+	}
+	return ctx
+}
+
+// submitGRPC calls LogIngestion.SubmitLog
+func (c *Client) submitGRPC(ctx context.Context, sub *submission) (*Receipt, error) {
+	response, err := c.grpc.SubmitLog(c.grpcContext(ctx), c.grpcRequest(sub))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcReceipt(response), nil
+}
+
+// submitBatchGRPC calls LogIngestion.SubmitLogsBatch
+func (c *Client) submitBatchGRPC(ctx context.Context, batch []*submission) ([]BatchResult, error) {
+	request := &pb.SubmitLogsBatchRequest{Entries: make([]*pb.SubmitLogRequest, len(batch))}
+	for i, sub := range batch {
+		request.Entries[i] = c.grpcRequest(sub)
+	}
+
+	response, err := c.grpc.SubmitLogsBatch(c.grpcContext(ctx), request)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if len(response.GetResults()) != len(batch) {
+		return nil, fmt.Errorf("%d results for %d entries", len(response.GetResults()), len(batch))
+	}
+
+	results := make([]BatchResult, len(batch))
+	for _, result := range response.GetResults() {
+		index := int(result.GetIndex())
+		if index < 0 || index >= len(batch) {
+			return nil, fmt.Errorf("result for entry %d of %d", index, len(batch))
+		}
+		if result.GetError() != "" {
+			code := codes.Code(result.GetCode())
+			if code == codes.OK { // Services that predate code
+				code = codes.Internal
+			}
+			results[index].Err = &APIError{Code: code, Message: result.GetError()}
+			continue
+		}
+		results[index].Receipt = grpcReceipt(result.GetResponse())
+	}
+	return results, nil
+}
+
+// grpcError converts the status of a failed call to an APIError; connection failures are
+// reported as UNAVAILABLE by gRPC
+func grpcError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || errors.Is(err, context.Canceled) {
+		return err
+	}
+	return &APIError{Code: st.Code(), Message: st.Message()}
+}
+
+func grpcReceipt(response *pb.SubmitLogResponse) *Receipt {
+	receipt := &Receipt{
+		RequestID:               response.GetRequestId(),
+		LogHash:                 response.GetServerLogHash(),
+		Status:                  response.GetStatus(),
+		IdempotentReplay:        response.GetIdempotentReplay(),
+		ClientTimestampFlagged:  response.GetClientTimestampFlagged(),
+		AttestationMode:         response.GetAttestationMode(),
+		ContentFormat:           response.GetContentFormat(),
+		Category:                response.GetCategory(),
+		SchemaVersion:           int(response.GetSchemaVersion()),
+		ContentLocator:          response.GetContentLocator(),
+		SignatureKeyID:          response.GetSignatureKeyId(),
+		SignatureAlgorithm:      response.GetSignatureAlgorithm(),
+		SignatureKeyFingerprint: response.GetSignatureKeyFingerprint(),
+		TxHash:                  response.GetTxHash(),
+		BlockHeight:             response.GetBlockHeight(),
+		ErrorMessage:            response.GetErrorMessage(),
+	}
+	if response.GetServerReceivedTimestamp() != nil {
+		receipt.ReceivedAt = response.GetServerReceivedTimestamp().AsTime()
+	}
+	return receipt
+}
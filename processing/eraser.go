@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	blockchain "tlng/blockchain/client"
+	"tlng/blockchain/types"
+	"tlng/config"
+	"tlng/internal/blobstore"
+	"tlng/storage/store"
+)
+
+// Eraser applies org retention policies and anchors erasure tombstones.
+// Record keys are destroyed in the state DB first; the tombstone follows on chain,
+// so an erasure takes effect even while the chain is unavailable.
+type Eraser struct {
+	checkInterval    time.Duration // Parsed from erasureConfig.CheckInterval
+	batchSize        int
+	logger           *log.Logger
+	store            store.Store
+	blockchainClient blockchain.BlockchainClient
+	blobs            blobstore.Store // Deletes offloaded content of expired logs; nil when the blob store is disabled
+}
+
+// NewEraser creates a new Eraser instance; blobs may be nil
+func NewEraser(cfg config.ErasureConfig, logger *log.Logger, s store.Store, bc blockchain.BlockchainClient, blobs blobstore.Store) *Eraser {
+	checkInterval, err := time.ParseDuration(cfg.CheckInterval)
+	if err != nil {
+		logger.Printf("Warning: Invalid erasure check_interval '%s', using default 1m", cfg.CheckInterval)
+		checkInterval = time.Minute
+	}
+
+	return &Eraser{
+		checkInterval:    checkInterval,
+		batchSize:        cfg.BatchSize,
+		logger:           logger,
+		store:            s,
+		blockchainClient: bc,
+		blobs:            blobs,
+	}
+}
+
+// Run erases expired content and anchors pending tombstones until ctx is cancelled
+func (e *Eraser) Run(ctx context.Context) {
+	e.logger.Printf("Eraser: Started (batch size %d, check interval %s)", e.batchSize, e.checkInterval)
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		e.runOnce(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			e.logger.Println("Eraser: Stopped")
+			return
+		}
+	}
+}
+
+func (e *Eraser) runOnce(ctx context.Context) {
+	// 1. Shred record keys past their org's retention, a batch at a time
+	for ctx.Err() == nil {
+		erased, err := e.store.EraseExpiredContent(ctx, time.Now(), e.batchSize)
+		if err != nil {
+			e.logger.Printf("Eraser: Retention expiry failed: %v", err)
+			break
+		}
+		if len(erased) > 0 {
+			e.logger.Printf("Eraser: Erased %d records past their retention policy", len(erased))
+		}
+		e.deleteBlobs(ctx, erased)
+		if len(erased) < e.batchSize {
+			break
+		}
+	}
+
+	// 2. Anchor tombstones of erasures not on chain yet
+	anchored, err := e.store.AnchorPendingErasures(ctx, e.batchSize, func(erasure *store.Erasure) (string, uint64, error) {
+		return e.anchor(ctx, erasure)
+	})
+	if err != nil {
+		e.logger.Printf("Eraser: Anchoring tombstones failed: %v", err)
+		return
+	}
+	if anchored > 0 {
+		e.logger.Printf("Eraser: Anchored %d tombstones", anchored)
+	}
+}
+
+// deleteBlobs removes the offloaded content of erased logs. Their record keys are already destroyed,
+// so a blob left behind by a failed delete is unreadable; logs that were not offloaded have no blob.
+func (e *Eraser) deleteBlobs(ctx context.Context, erased []store.ContentKeyID) {
+	if e.blobs == nil {
+		return
+	}
+	for _, id := range erased {
+		if err := e.blobs.Delete(ctx, id.SourceOrgID, id.LogHash); err != nil {
+			e.logger.Printf("Eraser: Failed to delete off-chain content of log_hash %s (org %s): %v", id.LogHash, id.SourceOrgID, err)
+		}
+	}
+}
+
+// anchor submits the tombstone of one erasure. Only the trigger goes on chain as the reason:
+// the free-text reason stays in the state DB, since it may itself contain personal data.
+func (e *Eraser) anchor(ctx context.Context, erasure *store.Erasure) (string, uint64, error) {
+	proof, err := e.blockchainClient.SubmitTombstone(ctx, types.Tombstone{
+		LogHash:     erasure.LogHash,
+		SenderOrgID: erasure.SourceOrgID,
+		ErasedAt:    erasure.ErasedAt.UTC().Format(time.RFC3339Nano),
+		Reason:      erasure.Trigger,
+	})
+	if err != nil {
+		e.logger.Printf("Eraser: Tombstone for log_hash %s failed: %v", erasure.LogHash, err)
+		return "", 0, err
+	}
+	return proof.TransactionID, proof.BlockHeight, nil
+}
@@ -13,6 +13,7 @@ import (
 	blockchain "tlng/blockchain/client"
 	"tlng/blockchain/types"
 	"tlng/config"
+	"tlng/internal/encryption"
 	"tlng/internal/messaging/consumer"
 	"tlng/internal/models"
 	"tlng/storage/store"
@@ -30,10 +31,12 @@ type Worker struct {
 	store            store.Store
 	consumer         consumer.Consumer
 	blockchainClient blockchain.BlockchainClient // Interface for blockchain client
+	encryptor        *encryption.Encryptor       // Seals on-chain content; nil when encryption is disabled
 }
 
 // New creates a new Worker instance
-func New(cfg config.WorkerConfig, maxTaskRetries int, logger *log.Logger, s store.Store, c consumer.Consumer, bc blockchain.BlockchainClient) *Worker {
+// enc may be nil, in which case content goes on chain in plaintext
+func New(cfg config.WorkerConfig, maxTaskRetries int, logger *log.Logger, s store.Store, c consumer.Consumer, bc blockchain.BlockchainClient, enc *encryption.Encryptor) *Worker {
 	// Add default safeguards if needed, though config should handle it
 	if cfg.BatchSize <= 0 {
 		cfg.BatchSize = 100
@@ -68,6 +71,7 @@ func New(cfg config.WorkerConfig, maxTaskRetries int, logger *log.Logger, s stor
 		store:                s,
 		consumer:             c,
 		blockchainClient:     bc,
+		encryptor:            enc,
 	}
 }
 
@@ -226,12 +230,31 @@ func (w *Worker) handleBatch(ctx context.Context, batch []*models.LogMessage) er
 		case store.StatusProcessing:
 			msg := msgMap[reqID]     // Get corresponding original message
 			validTasks[reqID] = task // Add to processing list
-			validEntries = append(validEntries, types.LogEntry{
-				LogHash:     msg.LogHash,
-				LogContent:  msg.LogContent,
-				SenderOrgID: msg.SourceOrgID,
-				Timestamp:   msg.ReceivedTimestamp,
-			})
+			entry := types.LogEntry{
+				LogHash:         msg.LogHash,
+				LogContent:      msg.LogContent,
+				SenderOrgID:     msg.SourceOrgID,
+				Timestamp:       msg.ReceivedTimestamp,
+				ClientTimestamp: msg.ClientTimestamp,
+				Category:        msg.Category,
+				SchemaVersion:   msg.SchemaVersion,
+				ContentLocator:  msg.ContentLocator,
+
+				Signature:               msg.Signature,
+				SignatureKeyID:          msg.SignatureKeyID,
+				SignatureAlgorithm:      msg.SignatureAlgorithm,
+				SignatureKeyFingerprint: msg.SignatureKeyFingerprint,
+			}
+			if msg.ContentFormat == models.ContentFormatJSON {
+				entry.ContentFormat = types.ContentFormatJSON
+			}
+			// Never put hash-only content, or content erased before it was anchored, on chain
+			if msg.AttestationMode == models.AttestationModeHashOnly || task.ErasedAt != nil {
+				entry.LogContent = ""
+				entry.ContentLocator = ""
+				entry.AttestationMode = types.AttestationModeHashOnly
+			}
+			validEntries = append(validEntries, entry)
 		case store.StatusFailed:
 			// Tasks with max retries exceeded are already marked as FAILED by the database
 			// No further action needed - they will be acknowledged and dropped from processing
@@ -243,6 +266,21 @@ func (w *Worker) handleBatch(ctx context.Context, batch []*models.LogMessage) er
 		return nil // Ack Kafka messages
 	}
 
+	// Never fall back to plaintext: retry the whole batch if any content could not be sealed
+	if w.encryptor != nil {
+		if err := w.sealContent(ctx, validEntries); err != nil {
+			w.logger.Printf("Encryption error: %v", err)
+			ids := make([]string, 0, len(validTasks))
+			for reqID := range validTasks {
+				ids = append(ids, reqID)
+			}
+			if markErr := w.store.MarkBatchForRetry(ctx, ids, err.Error()); markErr != nil {
+				w.logger.Printf("CRITICAL: MarkBatchForRetry failed: %v", markErr)
+			}
+			return fmt.Errorf("content encryption failed: %w", err) // Trigger Nack
+		}
+	}
+
 	// --- 2. Call blockchain client ---
 	invokeCtx, cancel := context.WithTimeout(ctx, w.blockchainTimeout)
 	defer cancel()
@@ -276,6 +314,12 @@ func (w *Worker) handleBatch(ctx context.Context, batch []*models.LogMessage) er
 	var completions []store.CompletionRecord
 	var failures []store.FailureRecord
 
+	var blockTimestamp *time.Time
+	if batchProof.BlockTimestamp > 0 {
+		t := time.Unix(batchProof.BlockTimestamp, 0)
+		blockTimestamp = &t
+	}
+
 	for reqID, task := range validTasks {
 		statusInfo, found := resultsMap[task.LogHash]
 		if !found {
@@ -294,6 +338,7 @@ func (w *Worker) handleBatch(ctx context.Context, batch []*models.LogMessage) er
 				TxHash:         batchProof.TransactionID,
 				LogHashOnChain: statusInfo.LogHash,
 				BlockHeight:    batchProof.BlockHeight,
+				BlockTimestamp: blockTimestamp,
 			})
 		default:
 			errMsg := fmt.Sprintf("Contract failed: %s - %s", statusInfo.Status, statusInfo.Message)
@@ -334,3 +379,73 @@ func (w *Worker) handleBatch(ctx context.Context, batch []*models.LogMessage) er
 
 	return nil // Transaction succeeded, Ack Kafka messages
 }
+
+// sealContent encrypts the content of entries in encryption scope in place, each with its own record key.
+// The log hash stays the plaintext hash, so it still commits to the original content.
+// Content offloaded to the blob store has nothing to seal on chain; the ingestion service sealed its blob.
+// Record keys are stored before the content goes on chain: a retried log reuses its stored key,
+// and a log whose key was erased meanwhile goes on chain hash-only.
+func (w *Worker) sealContent(ctx context.Context, entries []types.LogEntry) error {
+	inScope := func(entry *types.LogEntry) bool {
+		return entry.AttestationMode != types.AttestationModeHashOnly && entry.ContentLocator == "" && w.encryptor.Applies(entry.SenderOrgID)
+	}
+
+	// 1. Create a record key for every entry in scope
+	var candidates []*store.ContentKey
+	var ids []store.ContentKeyID
+	for i := range entries {
+		entry := &entries[i]
+		if !inScope(entry) {
+			continue
+		}
+		recordKey, err := w.encryptor.NewRecordKey(ctx, entry.SenderOrgID, entry.LogHash)
+		if err != nil {
+			return err
+		}
+		candidate := &store.ContentKey{
+			LogHash:     entry.LogHash,
+			SourceOrgID: entry.SenderOrgID,
+			OrgKeyID:    recordKey.OrgKeyID,
+			WrappedKey:  &recordKey.WrappedKey,
+		}
+		candidates = append(candidates, candidate)
+		ids = append(ids, candidate.ID())
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// 2. Store them; keys stored by an earlier attempt win
+	if err := w.store.InsertContentKeys(ctx, candidates); err != nil {
+		return err
+	}
+	storedKeys, err := w.store.GetContentKeys(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// 3. Seal with the stored keys
+	for i := range entries {
+		entry := &entries[i]
+		if !inScope(entry) {
+			continue
+		}
+		storedKey, ok := storedKeys[store.ContentKeyID{SourceOrgID: entry.SenderOrgID, LogHash: entry.LogHash}]
+		if !ok {
+			return fmt.Errorf("record key for log_hash %s of org %s was not stored", entry.LogHash, entry.SenderOrgID)
+		}
+		// Erased before it was anchored
+		if storedKey.WrappedKey == nil {
+			entry.LogContent = ""
+			entry.AttestationMode = types.AttestationModeHashOnly
+			continue
+		}
+		recordKey := &encryption.RecordKey{OrgKeyID: storedKey.OrgKeyID, WrappedKey: *storedKey.WrappedKey}
+		sealed, err := w.encryptor.EncryptRecord(ctx, recordKey, entry.SenderOrgID, entry.LogHash, entry.LogContent)
+		if err != nil {
+			return err
+		}
+		entry.LogContent = sealed
+	}
+	return nil
+}
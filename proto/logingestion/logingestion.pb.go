@@ -25,17 +25,52 @@ const (
 // Request message for submitting a log
 type SubmitLogRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Log content in raw format (required)
+	// Log content in raw format (required unless log_json is set)
 	LogContent string `protobuf:"bytes,1,opt,name=log_content,json=logContent,proto3" json:"log_content,omitempty"`
-	// (Optional) Client-specified log hash, server will validate if provided
+	// (Optional) Client-specified log hash, server will validate if provided.
+	// Either bare hex in the selected algorithm or tagged, e.g. "sm3:<hex>"
 	ClientLogHash string `protobuf:"bytes,2,opt,name=client_log_hash,json=clientLogHash,proto3" json:"client_log_hash,omitempty"`
 	// (Optional) Client-specified source organization ID, server will validate
 	// permission if provided
 	ClientSourceOrgId string `protobuf:"bytes,3,opt,name=client_source_org_id,json=clientSourceOrgId,proto3" json:"client_source_org_id,omitempty"`
-	// (Optional) Client-specified original timestamp
+	// (Optional) Client-specified original event time, stored with the log and
+	// on chain; checked against the server's allowed clock skew
 	ClientTimestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=client_timestamp,json=clientTimestamp,proto3" json:"client_timestamp,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// (Optional) Acknowledgement level: "accepted" (default), "durable" or
+	// "attested"
+	AckLevel string `protobuf:"bytes,5,opt,name=ack_level,json=ackLevel,proto3" json:"ack_level,omitempty"`
+	// (Optional) Maximum time to wait for "attested", in milliseconds; capped by
+	// the server and by the call deadline
+	AckTimeoutMs uint32 `protobuf:"varint,6,opt,name=ack_timeout_ms,json=ackTimeoutMs,proto3" json:"ack_timeout_ms,omitempty"`
+	// (Optional) Client-chosen key, unique per organization, that makes retries
+	// safe: a retry with the same key and content returns the original
+	// request_id, a retry with different content is rejected
+	IdempotencyKey string `protobuf:"bytes,7,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// (Optional) Digest algorithm: "sha256", "sha3-256" or "sm3"; defaults to
+	// the algorithm configured for the organization
+	HashAlgorithm string `protobuf:"bytes,8,opt,name=hash_algorithm,json=hashAlgorithm,proto3" json:"hash_algorithm,omitempty"`
+	// (Optional) "full" puts the log content on chain, "hash_only" only the hash
+	// and metadata; defaults to the mode configured for the organization
+	AttestationMode string `protobuf:"bytes,9,opt,name=attestation_mode,json=attestationMode,proto3" json:"attestation_mode,omitempty"`
+	// (Optional) Structured log as JSON text, instead of log_content. It is
+	// canonicalized with RFC 8785 (JCS) before hashing, so key order and
+	// whitespace do not change the hash; client_log_hash must be the hash of
+	// the canonical form
+	LogJson string `protobuf:"bytes,10,opt,name=log_json,json=logJson,proto3" json:"log_json,omitempty"`
+	// (Optional) Schema category of a log_json submission; the log is validated
+	// against the latest JSON Schema the organization registered for it and
+	// rejected with INVALID_ARGUMENT listing the failed fields
+	Category string `protobuf:"bytes,11,opt,name=category,proto3" json:"category,omitempty"`
+	// (Optional) Client signature over the server log hash as UTF-8 text (bare
+	// hex for SHA-256, "<algorithm>:<hex>" otherwise), made with the key
+	// signature_key_id the organization registered: Ed25519, ECDSA P-256 (DER,
+	// over SHA-256 of the hash) or SM2 (DER, default user ID). It is verified on
+	// submission and anchored on chain
+	Signature []byte `protobuf:"bytes,12,opt,name=signature,proto3" json:"signature,omitempty"`
+	// (Required with signature) ID of the registered public key
+	SignatureKeyId string `protobuf:"bytes,13,opt,name=signature_key_id,json=signatureKeyId,proto3" json:"signature_key_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SubmitLogRequest) Reset() {
@@ -96,19 +131,114 @@ func (x *SubmitLogRequest) GetClientTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *SubmitLogRequest) GetAckLevel() string {
+	if x != nil {
+		return x.AckLevel
+	}
+	return ""
+}
+
+func (x *SubmitLogRequest) GetAckTimeoutMs() uint32 {
+	if x != nil {
+		return x.AckTimeoutMs
+	}
+	return 0
+}
+
+func (x *SubmitLogRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *SubmitLogRequest) GetHashAlgorithm() string {
+	if x != nil {
+		return x.HashAlgorithm
+	}
+	return ""
+}
+
+func (x *SubmitLogRequest) GetAttestationMode() string {
+	if x != nil {
+		return x.AttestationMode
+	}
+	return ""
+}
+
+func (x *SubmitLogRequest) GetLogJson() string {
+	if x != nil {
+		return x.LogJson
+	}
+	return ""
+}
+
+func (x *SubmitLogRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *SubmitLogRequest) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *SubmitLogRequest) GetSignatureKeyId() string {
+	if x != nil {
+		return x.SignatureKeyId
+	}
+	return ""
+}
+
 // Response message for log submission
 type SubmitLogResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Server-generated unique request ID (UUID)
 	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	// Server-computed or validated log hash (SHA256)
+	// Server-computed or validated log hash; bare hex for SHA-256, tagged with
+	// the algorithm otherwise, e.g. "sm3:<hex>"
 	ServerLogHash string `protobuf:"bytes,2,opt,name=server_log_hash,json=serverLogHash,proto3" json:"server_log_hash,omitempty"`
 	// Server-recorded received timestamp
 	ServerReceivedTimestamp *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=server_received_timestamp,json=serverReceivedTimestamp,proto3" json:"server_received_timestamp,omitempty"`
-	// (Optional) Status information, e.g., "ACCEPTED"
-	Status        string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// (Optional) Status information: "ACCEPTED", "DURABLE", or for "attested"
+	// the state DB status, e.g., "COMPLETED"
+	Status string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// (Optional) Transaction hash, set when status is "COMPLETED"
+	TxHash string `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	// (Optional) Block height, set when status is "COMPLETED"
+	BlockHeight int64 `protobuf:"varint,6,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	// (Optional) Engine error message, set when status is "FAILED"
+	ErrorMessage string `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// True when the response belongs to an earlier submission with the same
+	// idempotency_key
+	IdempotentReplay bool `protobuf:"varint,8,opt,name=idempotent_replay,json=idempotentReplay,proto3" json:"idempotent_replay,omitempty"`
+	// True when client_timestamp was outside the allowed clock skew and the
+	// server is configured to flag rather than reject
+	ClientTimestampFlagged bool `protobuf:"varint,9,opt,name=client_timestamp_flagged,json=clientTimestampFlagged,proto3" json:"client_timestamp_flagged,omitempty"`
+	// Attestation mode applied to the log: "full" or "hash_only"
+	AttestationMode string `protobuf:"bytes,10,opt,name=attestation_mode,json=attestationMode,proto3" json:"attestation_mode,omitempty"`
+	// Content format of the log: "json" for log_json submissions, "text"
+	// otherwise
+	ContentFormat string `protobuf:"bytes,11,opt,name=content_format,json=contentFormat,proto3" json:"content_format,omitempty"`
+	// Schema category declared by the submission, empty if none
+	Category string `protobuf:"bytes,12,opt,name=category,proto3" json:"category,omitempty"`
+	// Version of the category schema the log was validated against, 0 if no
+	// category was declared
+	SchemaVersion int32 `protobuf:"varint,13,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// Blob store locator of content larger than the offload threshold, which is
+	// stored off chain by its hash; empty if the content goes on chain
+	ContentLocator string `protobuf:"bytes,14,opt,name=content_locator,json=contentLocator,proto3" json:"content_locator,omitempty"`
+	// Key the submission was signed with, its algorithm and the hex SHA-256
+	// fingerprint of its public key; empty for unsigned submissions
+	SignatureKeyId          string `protobuf:"bytes,15,opt,name=signature_key_id,json=signatureKeyId,proto3" json:"signature_key_id,omitempty"`
+	SignatureAlgorithm      string `protobuf:"bytes,16,opt,name=signature_algorithm,json=signatureAlgorithm,proto3" json:"signature_algorithm,omitempty"`
+	SignatureKeyFingerprint string `protobuf:"bytes,17,opt,name=signature_key_fingerprint,json=signatureKeyFingerprint,proto3" json:"signature_key_fingerprint,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *SubmitLogResponse) Reset() {
@@ -169,25 +299,397 @@ func (x *SubmitLogResponse) GetStatus() string {
 	return ""
 }
 
+func (x *SubmitLogResponse) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetBlockHeight() int64 {
+	if x != nil {
+		return x.BlockHeight
+	}
+	return 0
+}
+
+func (x *SubmitLogResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetIdempotentReplay() bool {
+	if x != nil {
+		return x.IdempotentReplay
+	}
+	return false
+}
+
+func (x *SubmitLogResponse) GetClientTimestampFlagged() bool {
+	if x != nil {
+		return x.ClientTimestampFlagged
+	}
+	return false
+}
+
+func (x *SubmitLogResponse) GetAttestationMode() string {
+	if x != nil {
+		return x.AttestationMode
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetContentFormat() string {
+	if x != nil {
+		return x.ContentFormat
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *SubmitLogResponse) GetContentLocator() string {
+	if x != nil {
+		return x.ContentLocator
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetSignatureKeyId() string {
+	if x != nil {
+		return x.SignatureKeyId
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetSignatureAlgorithm() string {
+	if x != nil {
+		return x.SignatureAlgorithm
+	}
+	return ""
+}
+
+func (x *SubmitLogResponse) GetSignatureKeyFingerprint() string {
+	if x != nil {
+		return x.SignatureKeyFingerprint
+	}
+	return ""
+}
+
+// Request message for submitting several logs in one call
+type SubmitLogsBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Log entries, up to the server's max_batch_entries (required)
+	Entries       []*SubmitLogRequest `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitLogsBatchRequest) Reset() {
+	*x = SubmitLogsBatchRequest{}
+	mi := &file_proto_logingestion_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitLogsBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitLogsBatchRequest) ProtoMessage() {}
+
+func (x *SubmitLogsBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logingestion_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitLogsBatchRequest.ProtoReflect.Descriptor instead.
+func (*SubmitLogsBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_logingestion_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SubmitLogsBatchRequest) GetEntries() []*SubmitLogRequest {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// Per-entry outcome of a batch submission
+type SubmitLogResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the entry in SubmitLogsBatchRequest.entries
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Set when the entry was accepted
+	Response *SubmitLogResponse `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	// Set when the entry was rejected, e.g., on a hash mismatch
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// gRPC status code of error: INVALID_ARGUMENT (3) and ALREADY_EXISTS (6)
+	// are final, UNAVAILABLE (14) and RESOURCE_EXHAUSTED (8) may be retried
+	// with the same idempotency_key
+	Code          uint32 `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitLogResult) Reset() {
+	*x = SubmitLogResult{}
+	mi := &file_proto_logingestion_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitLogResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitLogResult) ProtoMessage() {}
+
+func (x *SubmitLogResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logingestion_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitLogResult.ProtoReflect.Descriptor instead.
+func (*SubmitLogResult) Descriptor() ([]byte, []int) {
+	return file_proto_logingestion_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SubmitLogResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SubmitLogResult) GetResponse() *SubmitLogResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *SubmitLogResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SubmitLogResult) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+// Response message for batch log submission
+type SubmitLogsBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per request entry, in request order
+	Results       []*SubmitLogResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitLogsBatchResponse) Reset() {
+	*x = SubmitLogsBatchResponse{}
+	mi := &file_proto_logingestion_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitLogsBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitLogsBatchResponse) ProtoMessage() {}
+
+func (x *SubmitLogsBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logingestion_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitLogsBatchResponse.ProtoReflect.Descriptor instead.
+func (*SubmitLogsBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_logingestion_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SubmitLogsBatchResponse) GetResults() []*SubmitLogResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// Acknowledgement for one entry of SubmitLogStream; acknowledgements may
+// arrive out of order
+type SubmitLogStreamAck struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Zero-based position of the acknowledged entry in the request stream
+	Sequence uint64 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Set when the entry was accepted
+	Response *SubmitLogResponse `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	// Set when the entry was rejected or could not be persisted
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// gRPC status code of error, as in SubmitLogResult
+	Code          uint32 `protobuf:"varint,4,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitLogStreamAck) Reset() {
+	*x = SubmitLogStreamAck{}
+	mi := &file_proto_logingestion_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitLogStreamAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitLogStreamAck) ProtoMessage() {}
+
+func (x *SubmitLogStreamAck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_logingestion_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitLogStreamAck.ProtoReflect.Descriptor instead.
+func (*SubmitLogStreamAck) Descriptor() ([]byte, []int) {
+	return file_proto_logingestion_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SubmitLogStreamAck) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *SubmitLogStreamAck) GetResponse() *SubmitLogResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *SubmitLogStreamAck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SubmitLogStreamAck) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
 var File_proto_logingestion_proto protoreflect.FileDescriptor
 
 const file_proto_logingestion_proto_rawDesc = "" +
 	"\n" +
-	"\x18proto/logingestion.proto\x12\flogingestion\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd3\x01\n" +
+	"\x18proto/logingestion.proto\x12\flogingestion\x1a\x1fgoogle/protobuf/timestamp.proto\"\x90\x04\n" +
 	"\x10SubmitLogRequest\x12\x1f\n" +
 	"\vlog_content\x18\x01 \x01(\tR\n" +
 	"logContent\x12&\n" +
 	"\x0fclient_log_hash\x18\x02 \x01(\tR\rclientLogHash\x12/\n" +
 	"\x14client_source_org_id\x18\x03 \x01(\tR\x11clientSourceOrgId\x12E\n" +
-	"\x10client_timestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x0fclientTimestamp\"\xca\x01\n" +
+	"\x10client_timestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x0fclientTimestamp\x12\x1b\n" +
+	"\tack_level\x18\x05 \x01(\tR\backLevel\x12$\n" +
+	"\x0eack_timeout_ms\x18\x06 \x01(\rR\fackTimeoutMs\x12'\n" +
+	"\x0fidempotency_key\x18\a \x01(\tR\x0eidempotencyKey\x12%\n" +
+	"\x0ehash_algorithm\x18\b \x01(\tR\rhashAlgorithm\x12)\n" +
+	"\x10attestation_mode\x18\t \x01(\tR\x0fattestationMode\x12\x19\n" +
+	"\blog_json\x18\n" +
+	" \x01(\tR\alogJson\x12\x1a\n" +
+	"\bcategory\x18\v \x01(\tR\bcategory\x12\x1c\n" +
+	"\tsignature\x18\f \x01(\fR\tsignature\x12(\n" +
+	"\x10signature_key_id\x18\r \x01(\tR\x0esignatureKeyId\"\xe7\x05\n" +
 	"\x11SubmitLogResponse\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12&\n" +
 	"\x0fserver_log_hash\x18\x02 \x01(\tR\rserverLogHash\x12V\n" +
 	"\x19server_received_timestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x17serverReceivedTimestamp\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status2\\\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x17\n" +
+	"\atx_hash\x18\x05 \x01(\tR\x06txHash\x12!\n" +
+	"\fblock_height\x18\x06 \x01(\x03R\vblockHeight\x12#\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x12+\n" +
+	"\x11idempotent_replay\x18\b \x01(\bR\x10idempotentReplay\x128\n" +
+	"\x18client_timestamp_flagged\x18\t \x01(\bR\x16clientTimestampFlagged\x12)\n" +
+	"\x10attestation_mode\x18\n" +
+	" \x01(\tR\x0fattestationMode\x12%\n" +
+	"\x0econtent_format\x18\v \x01(\tR\rcontentFormat\x12\x1a\n" +
+	"\bcategory\x18\f \x01(\tR\bcategory\x12%\n" +
+	"\x0eschema_version\x18\r \x01(\x05R\rschemaVersion\x12'\n" +
+	"\x0fcontent_locator\x18\x0e \x01(\tR\x0econtentLocator\x12(\n" +
+	"\x10signature_key_id\x18\x0f \x01(\tR\x0esignatureKeyId\x12/\n" +
+	"\x13signature_algorithm\x18\x10 \x01(\tR\x12signatureAlgorithm\x12:\n" +
+	"\x19signature_key_fingerprint\x18\x11 \x01(\tR\x17signatureKeyFingerprint\"R\n" +
+	"\x16SubmitLogsBatchRequest\x128\n" +
+	"\aentries\x18\x01 \x03(\v2\x1e.logingestion.SubmitLogRequestR\aentries\"\x8e\x01\n" +
+	"\x0fSubmitLogResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12;\n" +
+	"\bresponse\x18\x02 \x01(\v2\x1f.logingestion.SubmitLogResponseR\bresponse\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\rR\x04code\"R\n" +
+	"\x17SubmitLogsBatchResponse\x127\n" +
+	"\aresults\x18\x01 \x03(\v2\x1d.logingestion.SubmitLogResultR\aresults\"\x97\x01\n" +
+	"\x12SubmitLogStreamAck\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12;\n" +
+	"\bresponse\x18\x02 \x01(\v2\x1f.logingestion.SubmitLogResponseR\bresponse\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\rR\x04code2\x95\x02\n" +
 	"\fLogIngestion\x12L\n" +
-	"\tSubmitLog\x12\x1e.logingestion.SubmitLogRequest\x1a\x1f.logingestion.SubmitLogResponseB\x19Z\x17tlng/proto/logingestionb\x06proto3"
+	"\tSubmitLog\x12\x1e.logingestion.SubmitLogRequest\x1a\x1f.logingestion.SubmitLogResponse\x12^\n" +
+	"\x0fSubmitLogsBatch\x12$.logingestion.SubmitLogsBatchRequest\x1a%.logingestion.SubmitLogsBatchResponse\x12W\n" +
+	"\x0fSubmitLogStream\x12\x1e.logingestion.SubmitLogRequest\x1a .logingestion.SubmitLogStreamAck(\x010\x01B\x19Z\x17tlng/proto/logingestionb\x06proto3"
 
 var (
 	file_proto_logingestion_proto_rawDescOnce sync.Once
@@ -201,22 +703,34 @@ func file_proto_logingestion_proto_rawDescGZIP() []byte {
 	return file_proto_logingestion_proto_rawDescData
 }
 
-var file_proto_logingestion_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_logingestion_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_proto_logingestion_proto_goTypes = []any{
-	(*SubmitLogRequest)(nil),      // 0: logingestion.SubmitLogRequest
-	(*SubmitLogResponse)(nil),     // 1: logingestion.SubmitLogResponse
-	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+	(*SubmitLogRequest)(nil),        // 0: logingestion.SubmitLogRequest
+	(*SubmitLogResponse)(nil),       // 1: logingestion.SubmitLogResponse
+	(*SubmitLogsBatchRequest)(nil),  // 2: logingestion.SubmitLogsBatchRequest
+	(*SubmitLogResult)(nil),         // 3: logingestion.SubmitLogResult
+	(*SubmitLogsBatchResponse)(nil), // 4: logingestion.SubmitLogsBatchResponse
+	(*SubmitLogStreamAck)(nil),      // 5: logingestion.SubmitLogStreamAck
+	(*timestamppb.Timestamp)(nil),   // 6: google.protobuf.Timestamp
 }
 var file_proto_logingestion_proto_depIdxs = []int32{
-	2, // 0: logingestion.SubmitLogRequest.client_timestamp:type_name -> google.protobuf.Timestamp
-	2, // 1: logingestion.SubmitLogResponse.server_received_timestamp:type_name -> google.protobuf.Timestamp
-	0, // 2: logingestion.LogIngestion.SubmitLog:input_type -> logingestion.SubmitLogRequest
-	1, // 3: logingestion.LogIngestion.SubmitLog:output_type -> logingestion.SubmitLogResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	6, // 0: logingestion.SubmitLogRequest.client_timestamp:type_name -> google.protobuf.Timestamp
+	6, // 1: logingestion.SubmitLogResponse.server_received_timestamp:type_name -> google.protobuf.Timestamp
+	0, // 2: logingestion.SubmitLogsBatchRequest.entries:type_name -> logingestion.SubmitLogRequest
+	1, // 3: logingestion.SubmitLogResult.response:type_name -> logingestion.SubmitLogResponse
+	3, // 4: logingestion.SubmitLogsBatchResponse.results:type_name -> logingestion.SubmitLogResult
+	1, // 5: logingestion.SubmitLogStreamAck.response:type_name -> logingestion.SubmitLogResponse
+	0, // 6: logingestion.LogIngestion.SubmitLog:input_type -> logingestion.SubmitLogRequest
+	2, // 7: logingestion.LogIngestion.SubmitLogsBatch:input_type -> logingestion.SubmitLogsBatchRequest
+	0, // 8: logingestion.LogIngestion.SubmitLogStream:input_type -> logingestion.SubmitLogRequest
+	1, // 9: logingestion.LogIngestion.SubmitLog:output_type -> logingestion.SubmitLogResponse
+	4, // 10: logingestion.LogIngestion.SubmitLogsBatch:output_type -> logingestion.SubmitLogsBatchResponse
+	5, // 11: logingestion.LogIngestion.SubmitLogStream:output_type -> logingestion.SubmitLogStreamAck
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_proto_logingestion_proto_init() }
@@ -230,7 +744,7 @@ func file_proto_logingestion_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_logingestion_proto_rawDesc), len(file_proto_logingestion_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
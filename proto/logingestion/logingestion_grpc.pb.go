@@ -19,7 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	LogIngestion_SubmitLog_FullMethodName = "/logingestion.LogIngestion/SubmitLog"
+	LogIngestion_SubmitLog_FullMethodName       = "/logingestion.LogIngestion/SubmitLog"
+	LogIngestion_SubmitLogsBatch_FullMethodName = "/logingestion.LogIngestion/SubmitLogsBatch"
+	LogIngestion_SubmitLogStream_FullMethodName = "/logingestion.LogIngestion/SubmitLogStream"
 )
 
 // LogIngestionClient is the client API for LogIngestion service.
@@ -30,6 +32,15 @@ const (
 type LogIngestionClient interface {
 	// SubmitLog method for submitting a single log entry, supports HTTP POST
 	SubmitLog(ctx context.Context, in *SubmitLogRequest, opts ...grpc.CallOption) (*SubmitLogResponse, error)
+	// SubmitLogsBatch submits several log entries in one call; each entry is
+	// validated independently and gets its own result
+	SubmitLogsBatch(ctx context.Context, in *SubmitLogsBatchRequest, opts ...grpc.CallOption) (*SubmitLogsBatchResponse, error)
+	// SubmitLogStream accepts log entries over one long-lived stream and sends
+	// an acknowledgement per entry once it is durable (or reached its
+	// ack_level). The server stops reading while too many entries are
+	// unacknowledged or the ingestion queue is full, so HTTP/2 flow control
+	// slows down the client instead of entries being rejected.
+	SubmitLogStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SubmitLogRequest, SubmitLogStreamAck], error)
 }
 
 type logIngestionClient struct {
@@ -50,6 +61,29 @@ func (c *logIngestionClient) SubmitLog(ctx context.Context, in *SubmitLogRequest
 	return out, nil
 }
 
+func (c *logIngestionClient) SubmitLogsBatch(ctx context.Context, in *SubmitLogsBatchRequest, opts ...grpc.CallOption) (*SubmitLogsBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitLogsBatchResponse)
+	err := c.cc.Invoke(ctx, LogIngestion_SubmitLogsBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logIngestionClient) SubmitLogStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SubmitLogRequest, SubmitLogStreamAck], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LogIngestion_ServiceDesc.Streams[0], LogIngestion_SubmitLogStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubmitLogRequest, SubmitLogStreamAck]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LogIngestion_SubmitLogStreamClient = grpc.BidiStreamingClient[SubmitLogRequest, SubmitLogStreamAck]
+
 // LogIngestionServer is the server API for LogIngestion service.
 // All implementations must embed UnimplementedLogIngestionServer
 // for forward compatibility.
@@ -58,6 +92,15 @@ func (c *logIngestionClient) SubmitLog(ctx context.Context, in *SubmitLogRequest
 type LogIngestionServer interface {
 	// SubmitLog method for submitting a single log entry, supports HTTP POST
 	SubmitLog(context.Context, *SubmitLogRequest) (*SubmitLogResponse, error)
+	// SubmitLogsBatch submits several log entries in one call; each entry is
+	// validated independently and gets its own result
+	SubmitLogsBatch(context.Context, *SubmitLogsBatchRequest) (*SubmitLogsBatchResponse, error)
+	// SubmitLogStream accepts log entries over one long-lived stream and sends
+	// an acknowledgement per entry once it is durable (or reached its
+	// ack_level). The server stops reading while too many entries are
+	// unacknowledged or the ingestion queue is full, so HTTP/2 flow control
+	// slows down the client instead of entries being rejected.
+	SubmitLogStream(grpc.BidiStreamingServer[SubmitLogRequest, SubmitLogStreamAck]) error
 	mustEmbedUnimplementedLogIngestionServer()
 }
 
@@ -71,6 +114,12 @@ type UnimplementedLogIngestionServer struct{}
 func (UnimplementedLogIngestionServer) SubmitLog(context.Context, *SubmitLogRequest) (*SubmitLogResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SubmitLog not implemented")
 }
+func (UnimplementedLogIngestionServer) SubmitLogsBatch(context.Context, *SubmitLogsBatchRequest) (*SubmitLogsBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitLogsBatch not implemented")
+}
+func (UnimplementedLogIngestionServer) SubmitLogStream(grpc.BidiStreamingServer[SubmitLogRequest, SubmitLogStreamAck]) error {
+	return status.Errorf(codes.Unimplemented, "method SubmitLogStream not implemented")
+}
 func (UnimplementedLogIngestionServer) mustEmbedUnimplementedLogIngestionServer() {}
 func (UnimplementedLogIngestionServer) testEmbeddedByValue()                      {}
 
@@ -110,6 +159,31 @@ func _LogIngestion_SubmitLog_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LogIngestion_SubmitLogsBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitLogsBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogIngestionServer).SubmitLogsBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogIngestion_SubmitLogsBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogIngestionServer).SubmitLogsBatch(ctx, req.(*SubmitLogsBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogIngestion_SubmitLogStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LogIngestionServer).SubmitLogStream(&grpc.GenericServerStream[SubmitLogRequest, SubmitLogStreamAck]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LogIngestion_SubmitLogStreamServer = grpc.BidiStreamingServer[SubmitLogRequest, SubmitLogStreamAck]
+
 // LogIngestion_ServiceDesc is the grpc.ServiceDesc for LogIngestion service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -121,7 +195,18 @@ var LogIngestion_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SubmitLog",
 			Handler:    _LogIngestion_SubmitLog_Handler,
 		},
+		{
+			MethodName: "SubmitLogsBatch",
+			Handler:    _LogIngestion_SubmitLogsBatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubmitLogStream",
+			Handler:       _LogIngestion_SubmitLogStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/logingestion.proto",
 }
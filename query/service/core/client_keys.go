@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"tlng/internal/signing"
+	"tlng/storage/store"
+)
+
+// ListClientKeys returns all public keys of an org, including revoked ones
+func (s *Service) ListClientKeys(ctx context.Context, orgID string) ([]*ClientKeyResponse, error) {
+	if orgID == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	keys, err := s.store.ListClientKeys(ctx, orgID)
+	if err != nil {
+		s.logger.Printf("Failed to list client keys for org=%s: %v", orgID, err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	resp := make([]*ClientKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, convertClientKey(key))
+	}
+	return resp, nil
+}
+
+// RegisterClientKey registers a public key clients of an org sign log hashes with.
+// Key IDs are never reused; ingestion accepts signatures with the key once its key cache expires.
+func (s *Service) RegisterClientKey(ctx context.Context, orgID, keyID, algorithm string, publicKeyPEM []byte, createdBy string) (*ClientKeyResponse, error) {
+	if orgID == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	// 1. Reject keys ingestion could not verify with
+	if err := signing.ValidateKeyID(keyID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	alg, err := signing.ParseAlgorithm(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	publicKey, err := signing.ParsePublicKey(alg, publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	// 2. Store it
+	key := &store.ClientKey{
+		SourceOrgID: orgID,
+		KeyID:       keyID,
+		Algorithm:   string(alg),
+		PublicKey:   string(publicKeyPEM),
+		Fingerprint: publicKey.Fingerprint,
+		CreatedBy:   createdBy,
+	}
+	if err := s.store.CreateClientKey(ctx, key); err != nil {
+		if errors.Is(err, store.ErrClientKeyExists) {
+			return nil, ErrClientKeyExists
+		}
+		s.logger.Printf("Failed to register client key for org=%s key_id=%s: %v", orgID, keyID, err)
+		return nil, fmt.Errorf("failed to update database: %w", err)
+	}
+
+	s.logger.Printf("Registered %s client key %s for org=%s (fingerprint=%s, created_by=%s)", alg, keyID, orgID, key.Fingerprint, createdBy)
+	return convertClientKey(key), nil
+}
+
+// GetClientKey returns a public key of an org
+func (s *Service) GetClientKey(ctx context.Context, orgID, keyID string) (*ClientKeyResponse, error) {
+	if orgID == "" {
+		return nil, ErrInvalidRequest
+	}
+	if err := signing.ValidateKeyID(keyID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	key, err := s.store.GetClientKey(ctx, orgID, keyID)
+	if err != nil {
+		if errors.Is(err, store.ErrClientKeyNotFound) {
+			return nil, ErrClientKeyNotFound
+		}
+		s.logger.Printf("Failed to query client key for org=%s key_id=%s: %v", orgID, keyID, err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	return convertClientKey(key), nil
+}
+
+// RevokeClientKey revokes a public key. Ingestion rejects new signatures with it once its key cache
+// expires; the key stays registered so logs it signed remain attributable.
+func (s *Service) RevokeClientKey(ctx context.Context, orgID, keyID string) (*ClientKeyResponse, error) {
+	if orgID == "" {
+		return nil, ErrInvalidRequest
+	}
+	if err := signing.ValidateKeyID(keyID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	key, err := s.store.RevokeClientKey(ctx, orgID, keyID)
+	if err != nil {
+		if errors.Is(err, store.ErrClientKeyNotFound) {
+			return nil, ErrClientKeyNotFound
+		}
+		s.logger.Printf("Failed to revoke client key for org=%s key_id=%s: %v", orgID, keyID, err)
+		return nil, fmt.Errorf("failed to update database: %w", err)
+	}
+
+	s.logger.Printf("Revoked client key %s of org=%s", keyID, orgID)
+	return convertClientKey(key), nil
+}
+
+// signatureInfo describes the on-chain signature of a log. The registered public key is added,
+// and the signature checked against it, if the key is still registered with the anchored fingerprint.
+func (s *Service) signatureInfo(ctx context.Context, logData *OnChainLogData, logHash string) *SignatureInfo {
+	info := &SignatureInfo{
+		SignerOrgID:    logData.OrgID,
+		KeyID:          logData.SignatureKeyID,
+		Algorithm:      logData.SignatureAlg,
+		KeyFingerprint: logData.SignatureKeyFP,
+		Signature:      logData.Signature,
+		SignedMessage:  logHash,
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(logData.Signature)
+	if err != nil {
+		s.logger.Printf("Malformed on-chain signature for log_hash=%s: %v", logHash, err)
+		return info
+	}
+	info.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	key, err := s.store.GetClientKey(ctx, logData.OrgID, logData.SignatureKeyID)
+	if err != nil {
+		if !errors.Is(err, store.ErrClientKeyNotFound) {
+			s.logger.Printf("Failed to query client key for org=%s key_id=%s: %v", logData.OrgID, logData.SignatureKeyID, err)
+		}
+		return info
+	}
+	if key.Fingerprint != logData.SignatureKeyFP {
+		return info
+	}
+	info.PublicKey = key.PublicKey
+	info.KeyRevokedAt = key.RevokedAt
+
+	publicKey, err := signing.ParsePublicKey(signing.Algorithm(key.Algorithm), []byte(key.PublicKey))
+	if err != nil {
+		s.logger.Printf("Failed to parse client key for org=%s key_id=%s: %v", key.SourceOrgID, key.KeyID, err)
+		return info
+	}
+	verified := publicKey.Verify([]byte(logHash), signature) == nil
+	info.Verified = &verified
+	return info
+}
+
+// convertClientKey converts store.ClientKey to ClientKeyResponse
+func convertClientKey(key *store.ClientKey) *ClientKeyResponse {
+	return &ClientKeyResponse{
+		SourceOrgID: key.SourceOrgID,
+		KeyID:       key.KeyID,
+		Algorithm:   key.Algorithm,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+		CreatedBy:   key.CreatedBy,
+		CreatedAt:   key.CreatedAt,
+		RevokedAt:   key.RevokedAt,
+	}
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tlng/internal/hashing"
+	"tlng/storage/store"
+)
+
+// errAmbiguousOrg is returned for content several orgs submitted when the caller did not name one
+var errAmbiguousOrg = fmt.Errorf("%w: log_hash belongs to several orgs, source_org_id is required", ErrInvalidRequest)
+
+// EraseLog crypto-shreds the content of an org's log on behalf of an admin member.
+// The record key is destroyed right away; the engine anchors the tombstone afterwards.
+// sourceOrgID may be empty if only one org submitted the content.
+// Erasing an erased log returns the existing erasure.
+func (s *Service) EraseLog(ctx context.Context, sourceOrgID, logHash, reason, requestedBy string) (*ErasureResponse, error) {
+	if logHash == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	logHash, err := hashing.Normalize(logHash, hashing.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	erasure, err := s.store.EraseContent(ctx, &store.Erasure{
+		LogHash:     logHash,
+		SourceOrgID: sourceOrgID,
+		Trigger:     store.ErasureTriggerRequest,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+		ErasedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrLogNotFound):
+			return nil, ErrLogNotFound
+		case errors.Is(err, store.ErrContentNotErasable):
+			return nil, ErrNotErasable
+		case errors.Is(err, store.ErrLogAmbiguous):
+			return nil, errAmbiguousOrg
+		}
+		s.logger.Printf("Failed to erase log_hash=%s: %v", logHash, err)
+		return nil, fmt.Errorf("failed to erase log: %w", err)
+	}
+
+	// Delete offloaded content: sealed blobs are unreadable without their record key already, but
+	// plaintext blobs of logs erased before they were anchored are not. Erasing again retries the delete.
+	if s.blobs != nil {
+		if err := s.blobs.Delete(ctx, erasure.SourceOrgID, logHash); err != nil {
+			s.logger.Printf("Failed to delete off-chain content of log_hash=%s (org=%s): %v", logHash, erasure.SourceOrgID, err)
+		}
+	}
+
+	s.logger.Printf("Erased content of log_hash=%s (org=%s, requested_by=%s)", logHash, erasure.SourceOrgID, requestedBy)
+	return convertErasure(erasure), nil
+}
+
+// GetErasure returns the erasure of an org's log and the state of its tombstone.
+// sourceOrgID may be empty if only one org's log with the hash was erased.
+func (s *Service) GetErasure(ctx context.Context, sourceOrgID, logHash string) (*ErasureResponse, error) {
+	if logHash == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	logHash, err := hashing.Normalize(logHash, hashing.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	var erasure *store.Erasure
+	if sourceOrgID != "" {
+		erasure, err = s.findErasure(ctx, sourceOrgID, logHash)
+	} else {
+		var erasures []*store.Erasure
+		erasures, err = s.listErasures(ctx, logHash)
+		if len(erasures) > 1 {
+			return nil, errAmbiguousOrg
+		}
+		if len(erasures) == 1 {
+			erasure = erasures[0]
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if erasure == nil {
+		return nil, ErrErasureNotFound
+	}
+	return convertErasure(erasure), nil
+}
+
+// ListRetentionPolicies returns the retention policies of all orgs
+func (s *Service) ListRetentionPolicies(ctx context.Context) ([]*RetentionPolicyResponse, error) {
+	policies, err := s.store.ListRetentionPolicies(ctx)
+	if err != nil {
+		s.logger.Printf("Failed to list retention policies: %v", err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	resp := make([]*RetentionPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		resp = append(resp, convertRetentionPolicy(policy))
+	}
+	return resp, nil
+}
+
+// SetRetentionPolicy creates or replaces an org's retention policy.
+// retention is a Go duration such as "720h"; the engine erases content older than it.
+func (s *Service) SetRetentionPolicy(ctx context.Context, orgID, retention, updatedBy string) (*RetentionPolicyResponse, error) {
+	if orgID == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	duration, err := time.ParseDuration(retention)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid retention: %v", ErrInvalidRequest, err)
+	}
+	if duration < time.Second {
+		return nil, fmt.Errorf("%w: retention must be at least 1s", ErrInvalidRequest)
+	}
+
+	policy := &store.RetentionPolicy{
+		SourceOrgID: orgID,
+		Retention:   duration.Truncate(time.Second),
+		UpdatedBy:   updatedBy,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if err := s.store.UpsertRetentionPolicy(ctx, policy); err != nil {
+		s.logger.Printf("Failed to set retention policy for org=%s: %v", orgID, err)
+		return nil, fmt.Errorf("failed to update database: %w", err)
+	}
+
+	s.logger.Printf("Set retention policy for org=%s to %s (updated_by=%s)", orgID, policy.Retention, updatedBy)
+	return convertRetentionPolicy(policy), nil
+}
+
+// DeleteRetentionPolicy removes an org's retention policy; content erased so far stays erased
+func (s *Service) DeleteRetentionPolicy(ctx context.Context, orgID string) error {
+	if orgID == "" {
+		return ErrInvalidRequest
+	}
+
+	if err := s.store.DeleteRetentionPolicy(ctx, orgID); err != nil {
+		if errors.Is(err, store.ErrRetentionPolicyNotFound) {
+			return ErrRetentionPolicyNotFound
+		}
+		s.logger.Printf("Failed to delete retention policy for org=%s: %v", orgID, err)
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+
+	s.logger.Printf("Deleted retention policy for org=%s", orgID)
+	return nil
+}
+
+// findErasure returns the erasure of an org's normalized log_hash, or nil if the log was not erased.
+// Without an org, the oldest erasure of any org is returned.
+func (s *Service) findErasure(ctx context.Context, sourceOrgID, logHash string) (*store.Erasure, error) {
+	if sourceOrgID == "" {
+		erasures, err := s.listErasures(ctx, logHash)
+		if err != nil || len(erasures) == 0 {
+			return nil, err
+		}
+		return erasures[0], nil
+	}
+
+	erasure, err := s.store.GetErasure(ctx, sourceOrgID, logHash)
+	if errors.Is(err, store.ErrErasureNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		s.logger.Printf("Failed to query erasure for log_hash=%s (org=%s): %v", logHash, sourceOrgID, err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	return erasure, nil
+}
+
+// listErasures returns the erasures of a normalized log_hash for every org, oldest first
+func (s *Service) listErasures(ctx context.Context, logHash string) ([]*store.Erasure, error) {
+	erasures, err := s.store.ListErasures(ctx, logHash)
+	if err != nil {
+		s.logger.Printf("Failed to query erasures for log_hash=%s: %v", logHash, err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	return erasures, nil
+}
+
+// convertErasure converts store.Erasure to ErasureResponse
+func convertErasure(erasure *store.Erasure) *ErasureResponse {
+	resp := &ErasureResponse{
+		LogHash:     erasure.LogHash,
+		SourceOrgID: erasure.SourceOrgID,
+		Trigger:     erasure.Trigger,
+		Reason:      erasure.Reason,
+		RequestedBy: erasure.RequestedBy,
+		ErasedAt:    erasure.ErasedAt,
+		Status:      string(erasure.Status),
+		TxHash:      derefString(erasure.TxHash),
+		AnchoredAt:  erasure.AnchoredAt,
+		LastError:   derefString(erasure.LastError),
+	}
+	if erasure.BlockHeight != nil {
+		resp.BlockHeight = *erasure.BlockHeight
+	}
+	return resp
+}
+
+// convertRetentionPolicy converts store.RetentionPolicy to RetentionPolicyResponse
+func convertRetentionPolicy(policy *store.RetentionPolicy) *RetentionPolicyResponse {
+	return &RetentionPolicyResponse{
+		SourceOrgID:      policy.SourceOrgID,
+		Retention:        policy.Retention.String(),
+		RetentionSeconds: int64(policy.Retention / time.Second),
+		UpdatedBy:        policy.UpdatedBy,
+		UpdatedAt:        policy.UpdatedAt,
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -8,4 +8,15 @@ var (
 	ErrPermissionDenied = errors.New("permission denied")
 	ErrInvalidRequest   = errors.New("invalid request")
 	ErrBlockchainError  = errors.New("blockchain query failed")
+	ErrDecryptionFailed = errors.New("failed to decrypt on-chain content")
+	ErrBlobUnavailable  = errors.New("off-chain content could not be fetched")
+	ErrBlobIntegrity    = errors.New("off-chain content does not match the on-chain log hash")
+
+	ErrErasureNotFound         = errors.New("erasure not found")
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrNotErasable             = errors.New("log content is anchored without a record key and cannot be erased")
+
+	ErrLogSchemaNotFound = errors.New("log schema not found")
+	ErrClientKeyNotFound = errors.New("client key not found")
+	ErrClientKeyExists   = errors.New("client key already exists")
 )
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"tlng/internal/schema"
+	"tlng/storage/store"
+)
+
+// ListLogSchemas returns the latest version of each category schema of an org
+func (s *Service) ListLogSchemas(ctx context.Context, orgID string) ([]*LogSchemaResponse, error) {
+	if orgID == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	schemas, err := s.store.ListLogSchemas(ctx, orgID)
+	if err != nil {
+		s.logger.Printf("Failed to list log schemas for org=%s: %v", orgID, err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+
+	resp := make([]*LogSchemaResponse, 0, len(schemas))
+	for _, sch := range schemas {
+		resp = append(resp, convertLogSchema(sch))
+	}
+	return resp, nil
+}
+
+// RegisterLogSchema stores document as the next version of an org's category schema.
+// The document must compile as a self-contained JSON Schema; ingestion validates new
+// submissions of the category against it once its schema cache expires.
+func (s *Service) RegisterLogSchema(ctx context.Context, orgID, category string, document []byte, createdBy string) (*LogSchemaResponse, error) {
+	if orgID == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	// 1. Reject documents ingestion could not compile
+	if _, err := schema.Compile(orgID, category, 0, document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	// 2. Store as the next version
+	sch := &store.LogSchema{
+		SourceOrgID: orgID,
+		Category:    category,
+		Schema:      compact.String(),
+		CreatedBy:   createdBy,
+	}
+	if err := s.store.CreateLogSchema(ctx, sch); err != nil {
+		s.logger.Printf("Failed to register log schema for org=%s category=%s: %v", orgID, category, err)
+		return nil, fmt.Errorf("failed to update database: %w", err)
+	}
+
+	s.logger.Printf("Registered log schema version %d for org=%s category=%s (created_by=%s)", sch.Version, orgID, category, createdBy)
+	return convertLogSchema(sch), nil
+}
+
+// GetLogSchema returns a version of an org's category schema, or the latest one if version is 0
+func (s *Service) GetLogSchema(ctx context.Context, orgID, category string, version int) (*LogSchemaResponse, error) {
+	if orgID == "" || version < 0 {
+		return nil, ErrInvalidRequest
+	}
+	if err := schema.ValidateCategory(category); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	sch, err := s.store.GetLogSchema(ctx, orgID, category, version)
+	if err != nil {
+		if errors.Is(err, store.ErrLogSchemaNotFound) {
+			return nil, ErrLogSchemaNotFound
+		}
+		s.logger.Printf("Failed to query log schema for org=%s category=%s: %v", orgID, category, err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	return convertLogSchema(sch), nil
+}
+
+// DeleteLogSchema removes all versions of an org's category schema. Submissions declaring the
+// category are rejected once ingestion's schema cache expires; logs already accepted keep their recorded version.
+func (s *Service) DeleteLogSchema(ctx context.Context, orgID, category string) error {
+	if orgID == "" {
+		return ErrInvalidRequest
+	}
+	if err := schema.ValidateCategory(category); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	if err := s.store.DeleteLogSchema(ctx, orgID, category); err != nil {
+		if errors.Is(err, store.ErrLogSchemaNotFound) {
+			return ErrLogSchemaNotFound
+		}
+		s.logger.Printf("Failed to delete log schema for org=%s category=%s: %v", orgID, category, err)
+		return fmt.Errorf("failed to update database: %w", err)
+	}
+
+	s.logger.Printf("Deleted log schema for org=%s category=%s", orgID, category)
+	return nil
+}
+
+// convertLogSchema converts store.LogSchema to LogSchemaResponse
+func convertLogSchema(sch *store.LogSchema) *LogSchemaResponse {
+	return &LogSchemaResponse{
+		SourceOrgID: sch.SourceOrgID,
+		Category:    sch.Category,
+		Version:     sch.Version,
+		Schema:      json.RawMessage(sch.Schema),
+		CreatedBy:   sch.CreatedBy,
+		CreatedAt:   sch.CreatedAt,
+	}
+}
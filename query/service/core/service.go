@@ -2,30 +2,47 @@ package core
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
 	"net/url"
+	"strconv"
+	"strings"
 
 	blockchain "tlng/blockchain/client"
+	"tlng/config"
+	"tlng/internal/blobstore"
+	"tlng/internal/encryption"
+	"tlng/internal/hashing"
+	"tlng/internal/jcs"
+	"tlng/internal/models"
 	"tlng/storage/store"
 )
 
 // Service provides core query business logic
 type Service struct {
-	store      store.Store
-	blockchain blockchain.BlockchainClient
-	logger     *log.Logger
+	store         store.Store
+	blockchain    blockchain.BlockchainClient
+	hashingCfg    config.HashingConfig
+	encryptionCfg config.QueryEncryptionConfig
+	keys          encryption.KeyProvider // nil when encryption is disabled
+	blobs         blobstore.Store        // nil when the blob store is disabled
+	logger        *log.Logger
 }
 
-// NewService creates a new query service instance
-func NewService(storeDB store.Store, bc blockchain.BlockchainClient, logger *log.Logger) *Service {
+// NewService creates a new query service instance.
+// hashingCfg must match the ingestion service so that content hashes are computed the same way.
+// keys opens encrypted on-chain content for callers granted in encryptionCfg; it may be nil.
+// blobs fetches content the ingestion service stored off chain; it may be nil.
+func NewService(storeDB store.Store, bc blockchain.BlockchainClient, hashingCfg config.HashingConfig, encryptionCfg config.QueryEncryptionConfig, keys encryption.KeyProvider, blobs blobstore.Store, logger *log.Logger) *Service {
 	return &Service{
-		store:      storeDB,
-		blockchain: bc,
-		logger:     logger,
+		store:         storeDB,
+		blockchain:    bc,
+		hashingCfg:    hashingCfg,
+		encryptionCfg: encryptionCfg,
+		keys:          keys,
+		blobs:         blobs,
+		logger:        logger,
 	}
 }
 
@@ -57,23 +74,50 @@ func (s *Service) GetStatusByRequestID(ctx context.Context, requestID, callerOrg
 }
 
 // QueryByContent queries log status by calculating hash from content
-// Only allows querying logs from the caller's organization
-func (s *Service) QueryByContent(ctx context.Context, logContent, callerOrgID string) (*LogStatusResponse, error) {
+// Only allows querying logs from the caller's organization.
+// hashAlgorithm is optional; without it the caller org's algorithm is tried first, then the others.
+func (s *Service) QueryByContent(ctx context.Context, logContent, hashAlgorithm, callerOrgID string) (*LogStatusResponse, error) {
 	if logContent == "" {
 		return nil, ErrInvalidRequest
 	}
 
-	// Calculate log_hash from content
-	logHash := calculateSHA256(logContent)
+	// Pick the algorithms the log may have been hashed with
+	var algorithms []hashing.Algorithm
+	if hashAlgorithm != "" {
+		algorithm, err := hashing.ParseAlgorithm(hashAlgorithm)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		algorithms = []hashing.Algorithm{algorithm}
+	} else {
+		orgAlgorithm := s.hashingCfg.AlgorithmFor(callerOrgID)
+		algorithms = []hashing.Algorithm{orgAlgorithm}
+		for _, algorithm := range hashing.Algorithms() {
+			if algorithm != orgAlgorithm {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+	}
 
-	// Query from State DB
-	status, err := s.store.GetLogStatusByHash(ctx, logHash)
-	if err != nil {
-		if errors.Is(err, store.ErrLogNotFound) {
-			return nil, ErrLogNotFound
+	// Calculate log_hash from content and query from State DB
+	var status *store.LogStatus
+	for _, algorithm := range algorithms {
+		logHash, err := hashing.Sum(algorithm, []byte(logContent))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash log content: %w", err)
+		}
+
+		status, err = s.store.GetLogStatusByHash(ctx, logHash)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, store.ErrLogNotFound) {
+			s.logger.Printf("Failed to query log status by log_hash=%s: %v", logHash, err)
+			return nil, fmt.Errorf("failed to query database: %w", err)
 		}
-		s.logger.Printf("Failed to query log status by log_hash=%s: %v", logHash, err)
-		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	if status == nil {
+		return nil, ErrLogNotFound
 	}
 
 	// Permission check: only allow querying own organization's logs
@@ -86,9 +130,20 @@ func (s *Service) QueryByContent(ctx context.Context, logContent, callerOrgID st
 	return convertToResponse(status), nil
 }
 
+// CanonicalizeLogJSON returns the RFC 8785 canonical form of a structured log, which is the
+// content the ingestion service hashed, so re-serialized events are found and verified as well
+func CanonicalizeLogJSON(logJSON []byte) (string, error) {
+	canonical, err := jcs.Canonicalize(logJSON)
+	if err != nil {
+		return "", fmt.Errorf("%w: log_json: %v", ErrInvalidRequest, err)
+	}
+	return string(canonical), nil
+}
+
 // AuditLogByHash performs on-chain audit query by log_hash
-// No permission restrictions - consortium members can audit all logs
-func (s *Service) AuditLogByHash(ctx context.Context, logHash string) (*OnChainLogResponse, error) {
+// No permission restrictions - consortium members can audit all logs.
+// Encrypted content is only decrypted for members the owning org has granted access.
+func (s *Service) AuditLogByHash(ctx context.Context, logHash, callerMemberID string) (*OnChainLogResponse, error) {
 	if logHash == "" {
 		return nil, ErrInvalidRequest
 	}
@@ -97,6 +152,307 @@ func (s *Service) AuditLogByHash(ctx context.Context, logHash string) (*OnChainL
 		return nil, fmt.Errorf("blockchain client not available")
 	}
 
+	// Untagged hashes are SHA-256; "sha256:<hex>" is stored untagged
+	logHash, err := hashing.Normalize(logHash, hashing.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	// 1. Look up the on-chain record and the erasure of its org: erased content is never returned,
+	// even if it could still be decrypted. Other orgs' erasures of the same content do not apply.
+	logData, err := s.findOnChain(ctx, logHash)
+	if errors.Is(err, ErrLogNotFound) {
+		// Erased before it was anchored; the engine anchors it hash-only
+		erasure, findErr := s.findErasure(ctx, "", logHash)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if erasure == nil {
+			return nil, err
+		}
+		return &OnChainLogResponse{
+			Source:          "state_db",
+			LogHash:         logHash,
+			SenderOrgID:     erasure.SourceOrgID,
+			AttestationMode: models.AttestationModeHashOnly,
+			Erased:          true,
+			ErasedAt:        &erasure.ErasedAt,
+			ErasureTxHash:   derefString(erasure.TxHash),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	erasure, err := s.findErasure(ctx, logData.OrgID, logHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Return structured response; hash-only records have no content to return
+	resp := &OnChainLogResponse{
+		Source:          "blockchain",
+		LogHash:         logHash,
+		LogContent:      logData.Content,
+		SenderOrgID:     logData.OrgID,
+		Timestamp:       logData.Timestamp,
+		ClientTimestamp: logData.ClientTimestamp,
+		AttestationMode: logData.AttestationMode,
+		ContentFormat:   logData.ContentFormat,
+		Category:        logData.Category,
+		SchemaVersion:   logData.SchemaVersion,
+		ContentLocator:  logData.ContentLocator,
+	}
+
+	if encryption.IsEnvelope(logData.Content) {
+		resp.LogContent = ""
+		resp.ContentEncrypted = true
+	}
+	if logData.Signature != "" {
+		resp.Signature = s.signatureInfo(ctx, logData, logHash)
+	}
+
+	if erasure != nil {
+		resp.LogContent = ""
+		resp.Erased = true
+		resp.ErasedAt = &erasure.ErasedAt
+		resp.ErasureTxHash = derefString(erasure.TxHash)
+		return resp, nil
+	}
+
+	// 3. Decrypt for members the owning org has granted access
+	if resp.ContentEncrypted && s.canDecrypt(logData.OrgID, callerMemberID) {
+		plaintext, err := s.decryptContent(ctx, logData.OrgID, logHash, logData.Content)
+		if err != nil {
+			return nil, err
+		}
+		resp.LogContent = plaintext
+	}
+
+	// 4. Fetch content stored off chain; without a blob store the caller gets the locator only.
+	// Sealed blobs are decrypted under the same grants as on-chain content.
+	if logData.ContentLocator != "" && s.blobs != nil {
+		content, sealed, err := s.fetchBlob(ctx, logData, logHash, callerMemberID)
+		if err != nil {
+			return nil, err
+		}
+		resp.LogContent = content
+		resp.ContentEncrypted = sealed
+	}
+
+	return resp, nil
+}
+
+// AuditLogsByTx performs the on-chain audit of every log anchored in a transaction.
+// The logs of the transaction are found in the State DB; each record is then read from the chain
+// as by AuditLogByHash, with the same permissions.
+func (s *Service) AuditLogsByTx(ctx context.Context, txHash, callerMemberID string) (*TxAuditResponse, error) {
+	if txHash == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	// 1. Logs of the transaction
+	statuses, err := s.store.ListLogStatusesByTxHash(ctx, txHash)
+	if err != nil {
+		s.logger.Printf("Failed to query log status by tx_hash=%s: %v", txHash, err)
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	if len(statuses) == 0 {
+		return nil, ErrLogNotFound
+	}
+
+	resp := &TxAuditResponse{
+		TxHash:         txHash,
+		BlockTimestamp: statuses[0].BlockTimestamp,
+		Logs:           make([]*OnChainLogResponse, 0, len(statuses)),
+	}
+	if statuses[0].BlockHeight != nil {
+		resp.BlockHeight = *statuses[0].BlockHeight
+	}
+
+	// 2. On-chain record of each log
+	for _, status := range statuses {
+		record, err := s.AuditLogByHash(ctx, status.LogHash, callerMemberID)
+		if err != nil {
+			return nil, err
+		}
+		resp.Logs = append(resp.Logs, record)
+	}
+
+	return resp, nil
+}
+
+// canDecrypt reports whether a member may read an org's encrypted content
+func (s *Service) canDecrypt(orgID, callerMemberID string) bool {
+	return s.keys != nil && s.encryptionCfg.CanDecrypt(orgID, callerMemberID)
+}
+
+// fetchBlob reads off-chain content and checks it against the on-chain log hash. A sealed blob is
+// decrypted only for members the owning org has granted access; others get no content.
+func (s *Service) fetchBlob(ctx context.Context, logData *OnChainLogData, logHash, callerMemberID string) (content string, sealed bool, err error) {
+	locator := logData.ContentLocator
+	blob, err := s.blobs.Get(ctx, locator)
+	if err != nil {
+		s.logger.Printf("Failed to fetch off-chain content for log_hash=%s from %s: %v", logHash, locator, err)
+		return "", false, fmt.Errorf("%w: %v", ErrBlobUnavailable, err)
+	}
+	if encryption.IsRecordEnvelope(string(blob)) {
+		if !s.canDecrypt(logData.OrgID, callerMemberID) {
+			return "", true, nil
+		}
+		plaintext, err := s.decryptContent(ctx, logData.OrgID, logHash, string(blob))
+		return plaintext, true, err
+	}
+
+	algorithm, _, err := hashing.Parse(logHash, hashing.SHA256)
+	if err != nil {
+		return "", false, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	computed, err := hashing.Sum(algorithm, blob)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to hash log content: %w", err)
+	}
+	if computed != logHash {
+		s.logger.Printf("Off-chain content at %s does not match log_hash=%s (computed %s)", locator, logHash, computed)
+		return "", false, ErrBlobIntegrity
+	}
+	return string(blob), false, nil
+}
+
+// decryptContent opens an org's encrypted content, on chain or off chain, and checks it against the log hash
+func (s *Service) decryptContent(ctx context.Context, orgID, logHash, envelope string) (string, error) {
+	var plaintext string
+	var err error
+	if encryption.IsRecordEnvelope(envelope) {
+		plaintext, err = s.decryptRecord(ctx, orgID, logHash, envelope)
+	} else {
+		plaintext, err = encryption.Decrypt(ctx, s.keys, orgID, logHash, envelope)
+	}
+	if err != nil {
+		s.logger.Printf("Failed to decrypt content for log_hash=%s: %v", logHash, err)
+		return "", ErrDecryptionFailed
+	}
+
+	// The hash commits to the plaintext, so the decrypted content must hash back to it
+	algorithm, _, err := hashing.Parse(logHash, hashing.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	computed, err := hashing.Sum(algorithm, []byte(plaintext))
+	if err != nil {
+		return "", fmt.Errorf("failed to hash log content: %w", err)
+	}
+	if computed != logHash {
+		s.logger.Printf("Decrypted content does not match log_hash=%s", logHash)
+		return "", ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// decryptRecord opens content sealed with a per-record key from the state DB
+func (s *Service) decryptRecord(ctx context.Context, orgID, logHash, envelope string) (string, error) {
+	id := store.ContentKeyID{SourceOrgID: orgID, LogHash: logHash}
+	contentKeys, err := s.store.GetContentKeys(ctx, []store.ContentKeyID{id})
+	if err != nil {
+		return "", err
+	}
+	contentKey := contentKeys[id]
+	if contentKey == nil || contentKey.WrappedKey == nil {
+		return "", fmt.Errorf("no record key for log_hash %s", logHash)
+	}
+	recordKey := &encryption.RecordKey{OrgKeyID: contentKey.OrgKeyID, WrappedKey: *contentKey.WrappedKey}
+	return encryption.DecryptRecord(ctx, s.keys, recordKey, orgID, logHash, envelope)
+}
+
+// VerifyLogContent checks caller-supplied content against the chain without returning any content.
+// If logHash is given, only that record is checked and a mismatch yields Verified=false;
+// otherwise the content is hashed with hashAlgorithm, or with every algorithm if none is given,
+// and ErrLogNotFound is returned when no record matches.
+// No permission restrictions - consortium members can verify all logs
+func (s *Service) VerifyLogContent(ctx context.Context, logContent, logHash, hashAlgorithm string) (*ContentVerificationResponse, error) {
+	if logContent == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	if s.blockchain == nil {
+		return nil, fmt.Errorf("blockchain client not available")
+	}
+
+	// 1. Pick the candidate hashes
+	var candidates []string
+	if logHash != "" {
+		algorithm, digest, err := hashing.Parse(logHash, hashing.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+		}
+		candidates = []string{hashing.Format(algorithm, digest)}
+	} else {
+		algorithms := hashing.Algorithms()
+		if hashAlgorithm != "" {
+			algorithm, err := hashing.ParseAlgorithm(hashAlgorithm)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+			}
+			algorithms = []hashing.Algorithm{algorithm}
+		}
+		for _, algorithm := range algorithms {
+			candidate, err := hashing.Sum(algorithm, []byte(logContent))
+			if err != nil {
+				return nil, fmt.Errorf("failed to hash log content: %w", err)
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// 2. Find the first candidate that is anchored on chain
+	for _, candidate := range candidates {
+		logData, err := s.findOnChain(ctx, candidate)
+		if errors.Is(err, ErrLogNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// 3. Recompute the hash with the record's algorithm. The on-chain key commits to the content,
+		// so the stored content is not compared.
+		algorithm, _, _ := hashing.Parse(candidate, hashing.SHA256)
+		computed, err := hashing.Sum(algorithm, []byte(logContent))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash log content: %w", err)
+		}
+		verified := computed == candidate
+
+		resp := &ContentVerificationResponse{
+			Source:          "blockchain",
+			Verified:        verified,
+			LogHash:         candidate,
+			HashAlgorithm:   string(algorithm),
+			SenderOrgID:     logData.OrgID,
+			Timestamp:       logData.Timestamp,
+			ClientTimestamp: logData.ClientTimestamp,
+			AttestationMode: logData.AttestationMode,
+			ContentFormat:   logData.ContentFormat,
+			Category:        logData.Category,
+			SchemaVersion:   logData.SchemaVersion,
+		}
+
+		// 4. The hash of erased content stays verifiable; report the erasure alongside
+		erasure, err := s.findErasure(ctx, logData.OrgID, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if erasure != nil {
+			resp.Erased = true
+			resp.ErasedAt = &erasure.ErasedAt
+		}
+		return resp, nil
+	}
+
+	return nil, ErrLogNotFound
+}
+
+// findOnChain reads and parses the on-chain record of a normalized log_hash
+func (s *Service) findOnChain(ctx context.Context, logHash string) (*OnChainLogData, error) {
 	// Query blockchain
 	rawData, err := s.blockchain.FindLogByHash(ctx, logHash)
 	if err != nil {
@@ -114,41 +470,62 @@ func (s *Service) AuditLogByHash(ctx context.Context, logHash string) (*OnChainL
 		s.logger.Printf("Failed to parse on-chain data for log_hash=%s: %v", logHash, err)
 		return nil, fmt.Errorf("failed to parse on-chain data: %w", err)
 	}
-
-	// Return structured response
-	return &OnChainLogResponse{
-		Source:      "blockchain",
-		LogHash:     logHash,
-		LogContent:  logData.Content,
-		SenderOrgID: logData.OrgID,
-		Timestamp:   logData.Timestamp,
-	}, nil
+	return logData, nil
 }
 
 // OnChainLogData represents parsed on-chain log data
 type OnChainLogData struct {
-	OrgID     string
-	Timestamp string
-	Content   string
+	OrgID           string
+	Timestamp       string
+	ClientTimestamp string // Absent in records written before client timestamps were stored
+	AttestationMode string // "full" or "hash_only"; absent in records written before attestation modes
+	ContentFormat   string // "text" or "json"; absent in records written before structured logs
+	Category        string // Schema category of a structured log; empty if none
+	SchemaVersion   int    // Schema version the log was validated against; 0 if none
+	ContentLocator  string // Blob store locator of content kept off chain; empty if the content is on chain
+	Signature       string // Client signature over the log hash (unpadded base64url); empty if unsigned
+	SignatureKeyID  string
+	SignatureAlg    string
+	SignatureKeyFP  string // Hex SHA-256 of the signer's public key
+	Content         string // Empty for hash-only and offloaded records
 }
 
+// onChainRecordVersion marks records whose values the contract query-escapes
+const onChainRecordVersion = "2"
+
 // parseOnChainData parses blockchain response data in key=value&key=value format
 // ChainMaker SDK GetContractInfo returns the result field as a plain string
 func parseOnChainData(raw string) (*OnChainLogData, error) {
-	// Parse key=value pairs (reusing url.ParseQuery for parsing convenience)
-	values, err := url.ParseQuery(raw)
+	values, err := parseOnChainValues(raw)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse query string from '%s': %w", raw, err)
+		return nil, fmt.Errorf("failed to parse on-chain record: %w", err)
 	}
 
 	data := &OnChainLogData{
-		OrgID:     values.Get("org_id"),
-		Timestamp: values.Get("ts"),
-		Content:   values.Get("content"),
+		OrgID:           values.Get("org_id"),
+		Timestamp:       values.Get("ts"),
+		ClientTimestamp: values.Get("client_ts"),
+		AttestationMode: values.Get("mode"),
+		ContentFormat:   values.Get("format"),
+		ContentLocator:  values.Get("locator"),
+		Signature:       values.Get("sig"),
+		SignatureKeyID:  values.Get("sig_key"),
+		SignatureAlg:    values.Get("sig_alg"),
+		SignatureKeyFP:  values.Get("sig_fp"),
+		Content:         values.Get("content"),
+	}
+	if data.AttestationMode == "" {
+		data.AttestationMode = models.AttestationModeFull
+	}
+	if data.ContentFormat == "" {
+		data.ContentFormat = models.ContentFormatText
+	}
+	if data.Category = values.Get("category"); data.Category != "" {
+		data.SchemaVersion, _ = strconv.Atoi(values.Get("schema_version"))
 	}
 
 	// Validate required fields
-	if data.OrgID == "" || data.Timestamp == "" || data.Content == "" {
+	if data.OrgID == "" || data.Timestamp == "" || (data.Content == "" && data.AttestationMode != models.AttestationModeHashOnly && data.ContentLocator == "") {
 		return nil, fmt.Errorf("incomplete on-chain data: org_id=%s, ts=%s, content_len=%d",
 			data.OrgID, data.Timestamp, len(data.Content))
 	}
@@ -156,20 +533,39 @@ func parseOnChainData(raw string) (*OnChainLogData, error) {
 	return data, nil
 }
 
-// calculateSHA256 computes the SHA256 hash of the input string
-func calculateSHA256(content string) string {
-	hash := sha256.Sum256([]byte(content))
-	return hex.EncodeToString(hash[:])
+// parseOnChainValues splits a record into its values. Version 2 records are query-escaped; older
+// records store values raw, with content last, so '&', '+' and '%' in it are kept as they are.
+func parseOnChainValues(raw string) (url.Values, error) {
+	head, content, hasContent := strings.Cut(raw, "&content=")
+	values := url.Values{}
+	for _, field := range strings.Split(head, "&") {
+		key, value, _ := strings.Cut(field, "=")
+		values.Set(key, value)
+	}
+	if values.Get("v") == onChainRecordVersion {
+		return url.ParseQuery(raw)
+	}
+	if hasContent {
+		values.Set("content", content)
+	}
+	return values, nil
 }
 
 // convertToResponse converts store.LogStatus to LogStatusResponse
 func convertToResponse(status *store.LogStatus) *LogStatusResponse {
 	resp := &LogStatusResponse{
-		RequestID:         status.RequestID,
-		LogHash:           status.LogHash,
-		SourceOrgID:       status.SourceOrgID,
-		Status:            string(status.Status),
-		ReceivedTimestamp: status.ReceivedTimestamp,
+		RequestID:              status.RequestID,
+		LogHash:                status.LogHash,
+		SourceOrgID:            status.SourceOrgID,
+		Status:                 string(status.Status),
+		ReceivedTimestamp:      status.ReceivedTimestamp,
+		ClientTimestamp:        status.ClientTimestamp,
+		ClientTimestampFlagged: status.ClientTimestampFlagged,
+		BlockTimestamp:         status.BlockTimestamp,
+		AttestationMode:        status.AttestationMode,
+		ContentFormat:          status.ContentFormat,
+		Erased:                 status.ErasedAt != nil,
+		ErasedAt:               status.ErasedAt,
 	}
 
 	// Add optional fields if present
@@ -188,6 +584,12 @@ func convertToResponse(status *store.LogStatus) *LogStatusResponse {
 	if status.ErrorMessage != nil {
 		resp.ErrorMessage = *status.ErrorMessage
 	}
+	if status.Category != nil {
+		resp.Category = *status.Category
+	}
+	if status.SchemaVersion != nil {
+		resp.SchemaVersion = *status.SchemaVersion
+	}
 
 	return resp
 }
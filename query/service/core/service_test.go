@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"tlng/internal/hashing"
+	"tlng/internal/jcs"
+)
+
+// contractRecord builds a log record the way the contract's submit_logs_batch writes it
+func contractRecord(orgID, ts, format, content string) string {
+	return fmt.Sprintf("org_id=%s&v=%s&ts=%s&client_ts=%s&mode=full&format=%s&category=&schema_version=0&locator=&sig_key=&sig_alg=&sig_fp=&sig=&content=%s",
+		url.QueryEscape(orgID), onChainRecordVersion, url.QueryEscape(ts), url.QueryEscape(ts), format, url.QueryEscape(content))
+}
+
+// TestParseOnChainDataRoundTrip checks that content read back from a record still hashes to its log_hash
+func TestParseOnChainDataRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{"json offset", "json", `{"ts":"2024-01-01T00:00:00+08:00","msg":"login"}`},
+		{"json query", "json", `{"q":"a=1&b=2","rate":"95%"}`},
+		{"json escapes", "json", `{"path":"/a%2Fb?x=%zz","note":"a + b = c"}`},
+		{"text percent", "text", "cpu 95%"},
+		{"text spaces", "text", "user alice  logged in from 10.0.0.1 "},
+		{"text unicode", "text", "€ & ü"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := tt.input
+			if tt.format == "json" {
+				canonical, err := jcs.Canonicalize([]byte(tt.input))
+				if err != nil {
+					t.Fatalf("Canonicalize: %v", err)
+				}
+				content = string(canonical)
+			}
+			logHash, err := hashing.Sum(hashing.SHA256, []byte(content))
+			if err != nil {
+				t.Fatalf("Sum: %v", err)
+			}
+
+			data, err := parseOnChainData(contractRecord("org&1", "2024-01-01T00:00:00+08:00", tt.format, content))
+			if err != nil {
+				t.Fatalf("parseOnChainData: %v", err)
+			}
+			if data.Content != content {
+				t.Errorf("Content = %q, want %q", data.Content, content)
+			}
+			if got, _ := hashing.Sum(hashing.SHA256, []byte(data.Content)); got != logHash {
+				t.Errorf("content hashes to %s, want %s", got, logHash)
+			}
+			if data.OrgID != "org&1" || data.Timestamp != "2024-01-01T00:00:00+08:00" || data.ContentFormat != tt.format {
+				t.Errorf("got org_id=%q ts=%q format=%q", data.OrgID, data.Timestamp, data.ContentFormat)
+			}
+		})
+	}
+}
+
+// TestParseOnChainDataLegacy checks records written before values were escaped
+func TestParseOnChainDataLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		ts      string
+		mode    string
+		content string
+	}{
+		{"original", "org_id=org1&ts=2024-01-01T00:00:00Z&content=cpu 95%", "2024-01-01T00:00:00Z", "full", "cpu 95%"},
+		{"offset", "org_id=org1&ts=2024-01-01T00:00:00+08:00&client_ts=&mode=full&format=json&category=&schema_version=0&locator=&sig_key=&sig_alg=&sig_fp=&sig=&content={\"q\":\"a=1&b=2\"}",
+			"2024-01-01T00:00:00+08:00", "full", `{"q":"a=1&b=2"}`},
+		{"content with marker", "org_id=org1&ts=2024-01-01T00:00:00Z&content=x&v=2&content=y", "2024-01-01T00:00:00Z", "full", "x&v=2&content=y"},
+		{"hash only", "org_id=org1&ts=2024-01-01T00:00:00Z&client_ts=&mode=hash_only&format=text&category=&schema_version=0&locator=&sig_key=&sig_alg=&sig_fp=&sig=&content=",
+			"2024-01-01T00:00:00Z", "hash_only", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := parseOnChainData(tt.raw)
+			if err != nil {
+				t.Fatalf("parseOnChainData: %v", err)
+			}
+			if data.OrgID != "org1" || data.Timestamp != tt.ts || data.AttestationMode != tt.mode || data.Content != tt.content {
+				t.Errorf("got org_id=%q ts=%q mode=%q content=%q", data.OrgID, data.Timestamp, data.AttestationMode, data.Content)
+			}
+		})
+	}
+}
+
+func TestParseOnChainDataIncomplete(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"org_id=org1&v=2&ts=2024-01-01T00%3A00%3A00Z&mode=full&content=",
+		"org_id=&v=2&ts=2024-01-01T00%3A00%3A00Z&content=x",
+		"org_id=org1&v=2&ts=%zz&content=x",
+	} {
+		if _, err := parseOnChainData(raw); err == nil {
+			t.Errorf("parseOnChainData(%q) succeeded, want an error", raw)
+		}
+	}
+}
@@ -1,26 +1,142 @@
 package core
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // LogStatusResponse represents the response for log status queries
 type LogStatusResponse struct {
-	RequestID            string     `json:"request_id"`
-	LogHash              string     `json:"log_hash"`
-	SourceOrgID          string     `json:"source_org_id"`
-	Status               string     `json:"status"`
-	ReceivedTimestamp    time.Time  `json:"received_timestamp"`
-	ProcessingStartedAt  *time.Time `json:"processing_started_at,omitempty"`
-	ProcessingFinishedAt *time.Time `json:"processing_finished_at,omitempty"`
-	TxHash               string     `json:"tx_hash,omitempty"`
-	BlockHeight          int64      `json:"block_height,omitempty"`
-	ErrorMessage         string     `json:"error_message,omitempty"`
+	RequestID              string     `json:"request_id"`
+	LogHash                string     `json:"log_hash"`
+	SourceOrgID            string     `json:"source_org_id"`
+	Status                 string     `json:"status"`
+	ReceivedTimestamp      time.Time  `json:"received_timestamp"`
+	ClientTimestamp        *time.Time `json:"client_timestamp,omitempty"`
+	ClientTimestampFlagged bool       `json:"client_timestamp_flagged,omitempty"`
+	ProcessingStartedAt    *time.Time `json:"processing_started_at,omitempty"`
+	ProcessingFinishedAt   *time.Time `json:"processing_finished_at,omitempty"`
+	TxHash                 string     `json:"tx_hash,omitempty"`
+	BlockHeight            int64      `json:"block_height,omitempty"`
+	BlockTimestamp         *time.Time `json:"block_timestamp,omitempty"`
+	ErrorMessage           string     `json:"error_message,omitempty"`
+	AttestationMode        string     `json:"attestation_mode,omitempty"`
+	ContentFormat          string     `json:"content_format,omitempty"`
+	Category               string     `json:"category,omitempty"`
+	SchemaVersion          int        `json:"schema_version,omitempty"`
+	Erased                 bool       `json:"erased,omitempty"`
+	ErasedAt               *time.Time `json:"erased_at,omitempty"`
 }
 
 // OnChainLogResponse represents the response for blockchain audit queries
 type OnChainLogResponse struct {
-	Source      string `json:"source"`
-	LogHash     string `json:"log_hash"`
-	LogContent  string `json:"log_content"`
-	SenderOrgID string `json:"sender_org_id"`
-	Timestamp   string `json:"timestamp"`
+	Source           string `json:"source"`
+	LogHash          string `json:"log_hash"`
+	LogContent       string `json:"log_content,omitempty"` // Omitted for hash-only records and for encrypted content the caller may not read
+	ContentEncrypted bool   `json:"content_encrypted,omitempty"`
+	SenderOrgID      string `json:"sender_org_id"`
+	Timestamp        string `json:"timestamp"`
+	ClientTimestamp  string `json:"client_timestamp,omitempty"`
+	AttestationMode  string `json:"attestation_mode"`
+	ContentFormat    string `json:"content_format,omitempty"`  // "json" content is in RFC 8785 canonical form
+	Category         string `json:"category,omitempty"`        // Schema category of a structured log
+	SchemaVersion    int    `json:"schema_version,omitempty"`  // Schema version the log was validated against
+	ContentLocator   string `json:"content_locator,omitempty"` // Set when the content is stored off chain by its hash
+
+	// Client signature over log_hash, omitted for unsigned logs
+	Signature *SignatureInfo `json:"signature,omitempty"`
+
+	// Erased content is never returned; hash, timestamps and transaction stay verifiable
+	Erased        bool       `json:"erased,omitempty"`
+	ErasedAt      *time.Time `json:"erased_at,omitempty"`
+	ErasureTxHash string     `json:"erasure_tx_hash,omitempty"` // Tombstone transaction, once anchored
+}
+
+// TxAuditResponse represents the on-chain records of the logs anchored in a transaction
+type TxAuditResponse struct {
+	TxHash         string                `json:"tx_hash"`
+	BlockHeight    int64                 `json:"block_height,omitempty"`
+	BlockTimestamp *time.Time            `json:"block_timestamp,omitempty"`
+	Logs           []*OnChainLogResponse `json:"logs"` // In the order the logs were received
+}
+
+// ContentVerificationResponse represents the result of checking caller-supplied content against the chain.
+// It never contains log content.
+type ContentVerificationResponse struct {
+	Source          string     `json:"source"`
+	Verified        bool       `json:"verified"`
+	LogHash         string     `json:"log_hash"`
+	HashAlgorithm   string     `json:"hash_algorithm"`
+	SenderOrgID     string     `json:"sender_org_id"`
+	Timestamp       string     `json:"timestamp"`
+	ClientTimestamp string     `json:"client_timestamp,omitempty"`
+	AttestationMode string     `json:"attestation_mode"`
+	ContentFormat   string     `json:"content_format"`
+	Category        string     `json:"category,omitempty"`
+	SchemaVersion   int        `json:"schema_version,omitempty"`
+	Erased          bool       `json:"erased,omitempty"`
+	ErasedAt        *time.Time `json:"erased_at,omitempty"`
+}
+
+// ErasureResponse represents an erasure and the state of its on-chain tombstone
+type ErasureResponse struct {
+	LogHash     string     `json:"log_hash"`
+	SourceOrgID string     `json:"source_org_id"`
+	Trigger     string     `json:"trigger"`
+	Reason      string     `json:"reason,omitempty"`
+	RequestedBy string     `json:"requested_by,omitempty"`
+	ErasedAt    time.Time  `json:"erased_at"`
+	Status      string     `json:"status"`
+	TxHash      string     `json:"tx_hash,omitempty"`
+	BlockHeight int64      `json:"block_height,omitempty"`
+	AnchoredAt  *time.Time `json:"anchored_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// RetentionPolicyResponse represents an org's retention policy
+type RetentionPolicyResponse struct {
+	SourceOrgID      string    `json:"source_org_id"`
+	Retention        string    `json:"retention"`
+	RetentionSeconds int64     `json:"retention_seconds"`
+	UpdatedBy        string    `json:"updated_by,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// SignatureInfo identifies who signed a log and carries what a third party needs to check it:
+// Signature is over the UTF-8 bytes of SignedMessage, verifiable with the public key whose
+// SubjectPublicKeyInfo hashes to KeyFingerprint
+type SignatureInfo struct {
+	SignerOrgID    string `json:"signer_org_id"`
+	KeyID          string `json:"key_id"`
+	Algorithm      string `json:"algorithm"` // ed25519, ecdsa-p256 or sm2
+	KeyFingerprint string `json:"key_fingerprint"`
+	Signature      string `json:"signature"` // Base64
+	SignedMessage  string `json:"signed_message"`
+
+	// From the org's key registry; omitted if the key is no longer registered with the anchored fingerprint
+	PublicKey    string     `json:"public_key,omitempty"` // PEM
+	KeyRevokedAt *time.Time `json:"key_revoked_at,omitempty"`
+	Verified     *bool      `json:"verified,omitempty"` // Checked against PublicKey by the query service
+}
+
+// ClientKeyResponse represents a public key clients of an org sign log hashes with
+type ClientKeyResponse struct {
+	SourceOrgID string     `json:"source_org_id"`
+	KeyID       string     `json:"key_id"`
+	Algorithm   string     `json:"algorithm"`
+	PublicKey   string     `json:"public_key"` // PEM
+	Fingerprint string     `json:"fingerprint"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// LogSchemaResponse represents one version of an org's category schema
+type LogSchemaResponse struct {
+	SourceOrgID string          `json:"source_org_id"`
+	Category    string          `json:"category"`
+	Version     int             `json:"version"`
+	Schema      json.RawMessage `json:"schema"`
+	CreatedBy   string          `json:"created_by,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
 }
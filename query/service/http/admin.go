@@ -0,0 +1,350 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tlng/internal/auth"
+)
+
+// EraseLogRequest represents the request body for an erasure
+type EraseLogRequest struct {
+	LogHash     string `json:"log_hash"`
+	SourceOrgID string `json:"source_org_id"` // Required only if several orgs submitted the same content
+	Reason      string `json:"reason"`        // Kept in the state DB only, never on chain
+}
+
+// EraseLog handles POST /v1/admin/erasures
+func (h *Handler) EraseLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Ensure the request body is closed when we're done
+	defer r.Body.Close()
+
+	// Parse request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	var req EraseLogRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	if strings.TrimSpace(req.LogHash) == "" {
+		h.writeError(w, http.StatusBadRequest, "log_hash is required")
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		h.writeError(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	authCtx := auth.GetAuthContext(r.Context())
+
+	// The key is destroyed now; the tombstone is anchored by the engine, hence 202
+	result, err := h.service.EraseLog(r.Context(), strings.TrimSpace(req.SourceOrgID), strings.TrimSpace(req.LogHash), req.Reason, authCtx.MemberID)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, result)
+}
+
+// GetErasure handles GET /v1/admin/erasures/{log_hash}[?source_org_id=...]
+func (h *Handler) GetErasure(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	logHash, ok := h.pathParam(w, r, "/v1/admin/erasures/", "log_hash")
+	if !ok {
+		return
+	}
+
+	result, err := h.service.GetErasure(r.Context(), r.URL.Query().Get("source_org_id"), logHash)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// ListRetentionPolicies handles GET /v1/admin/retention-policies
+func (h *Handler) ListRetentionPolicies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.service.ListRetentionPolicies(r.Context())
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// SetRetentionPolicyRequest represents the request body for a retention policy
+type SetRetentionPolicyRequest struct {
+	Retention string `json:"retention"` // Go duration, e.g. "720h"
+}
+
+// RetentionPolicy handles PUT and DELETE /v1/admin/retention-policies/{org_id}
+func (h *Handler) RetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	orgID, ok := h.pathParam(w, r, "/v1/admin/retention-policies/", "org_id")
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := h.service.DeleteRetentionPolicy(r.Context(), orgID); err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Ensure the request body is closed when we're done
+	defer r.Body.Close()
+
+	// Parse request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	var req SetRetentionPolicyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	if strings.TrimSpace(req.Retention) == "" {
+		h.writeError(w, http.StatusBadRequest, "retention is required")
+		return
+	}
+
+	authCtx := auth.GetAuthContext(r.Context())
+
+	result, err := h.service.SetRetentionPolicy(r.Context(), orgID, strings.TrimSpace(req.Retention), authCtx.MemberID)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// RegisterLogSchemaRequest represents the request body for a log schema registration
+type RegisterLogSchemaRequest struct {
+	Schema json.RawMessage `json:"schema"` // JSON Schema document, draft 2020-12 unless it declares $schema
+}
+
+// LogSchemas handles the log schema registry:
+// GET /v1/admin/schemas/{org_id} lists the latest version of each category,
+// POST /v1/admin/schemas/{org_id}/{category} registers a new version,
+// GET /v1/admin/schemas/{org_id}/{category}[?version=N] returns the latest or the given version and
+// DELETE /v1/admin/schemas/{org_id}/{category} removes all versions
+func (h *Handler) LogSchemas(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/admin/schemas/"), "/"), "/")
+	for _, segment := range segments {
+		if strings.TrimSpace(segment) == "" || strings.Contains(segment, "..") {
+			h.writeError(w, http.StatusBadRequest, "invalid path: expected /v1/admin/schemas/{org_id}[/{category}]")
+			return
+		}
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		result, err := h.service.ListLogSchemas(r.Context(), segments[0])
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, result)
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		version := 0
+		if v := r.URL.Query().Get("version"); v != "" {
+			var err error
+			if version, err = strconv.Atoi(v); err != nil || version <= 0 {
+				h.writeError(w, http.StatusBadRequest, "version must be a positive integer")
+				return
+			}
+		}
+		result, err := h.service.GetLogSchema(r.Context(), segments[0], segments[1], version)
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, result)
+	case len(segments) == 2 && r.Method == http.MethodPost:
+		h.registerLogSchema(w, r, segments[0], segments[1])
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		if err := h.service.DeleteLogSchema(r.Context(), segments[0], segments[1]); err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) > 2:
+		h.writeError(w, http.StatusBadRequest, "invalid path: expected /v1/admin/schemas/{org_id}[/{category}]")
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// registerLogSchema handles POST /v1/admin/schemas/{org_id}/{category}
+func (h *Handler) registerLogSchema(w http.ResponseWriter, r *http.Request, orgID, category string) {
+	// Ensure the request body is closed when we're done
+	defer r.Body.Close()
+
+	// Parse request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	var req RegisterLogSchemaRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	if len(req.Schema) == 0 || string(req.Schema) == "null" {
+		h.writeError(w, http.StatusBadRequest, "schema is required")
+		return
+	}
+
+	authCtx := auth.GetAuthContext(r.Context())
+
+	result, err := h.service.RegisterLogSchema(r.Context(), orgID, category, req.Schema, authCtx.MemberID)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, result)
+}
+
+// RegisterClientKeyRequest represents the request body for a client key registration
+type RegisterClientKeyRequest struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`  // ed25519, ecdsa-p256 or sm2
+	PublicKey string `json:"public_key"` // PEM SubjectPublicKeyInfo
+}
+
+// ClientKeys handles the client key registry:
+// GET /v1/admin/client-keys/{org_id} lists an org's keys,
+// POST /v1/admin/client-keys/{org_id} registers a key,
+// GET /v1/admin/client-keys/{org_id}/{key_id} returns a key and
+// DELETE /v1/admin/client-keys/{org_id}/{key_id} revokes it
+func (h *Handler) ClientKeys(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/admin/client-keys/"), "/"), "/")
+	for _, segment := range segments {
+		if strings.TrimSpace(segment) == "" || strings.Contains(segment, "..") {
+			h.writeError(w, http.StatusBadRequest, "invalid path: expected /v1/admin/client-keys/{org_id}[/{key_id}]")
+			return
+		}
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		result, err := h.service.ListClientKeys(r.Context(), segments[0])
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, result)
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		h.registerClientKey(w, r, segments[0])
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		result, err := h.service.GetClientKey(r.Context(), segments[0], segments[1])
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, result)
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		result, err := h.service.RevokeClientKey(r.Context(), segments[0], segments[1])
+		if err != nil {
+			h.handleServiceError(w, err)
+			return
+		}
+		h.writeJSON(w, http.StatusOK, result)
+	case len(segments) > 2:
+		h.writeError(w, http.StatusBadRequest, "invalid path: expected /v1/admin/client-keys/{org_id}[/{key_id}]")
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// registerClientKey handles POST /v1/admin/client-keys/{org_id}
+func (h *Handler) registerClientKey(w http.ResponseWriter, r *http.Request, orgID string) {
+	// Ensure the request body is closed when we're done
+	defer r.Body.Close()
+
+	// Parse request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	var req RegisterClientKeyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	if req.KeyID == "" || req.Algorithm == "" || req.PublicKey == "" {
+		h.writeError(w, http.StatusBadRequest, "key_id, algorithm and public_key are required")
+		return
+	}
+
+	authCtx := auth.GetAuthContext(r.Context())
+
+	result, err := h.service.RegisterClientKey(r.Context(), orgID, req.KeyID, req.Algorithm, []byte(req.PublicKey), authCtx.MemberID)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, result)
+}
+
+// pathParam extracts the last path segment after prefix, writing a 400 if it is missing or unsafe
+func (h *Handler) pathParam(w http.ResponseWriter, r *http.Request, prefix, name string) (string, bool) {
+	value := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, prefix))
+	if value == "" {
+		h.writeError(w, http.StatusBadRequest, "missing "+name)
+		return "", false
+	}
+
+	// Validate to prevent path traversal
+	if strings.Contains(value, "..") || strings.Contains(value, "/") {
+		h.writeError(w, http.StatusBadRequest, "invalid "+name+": path traversal characters not allowed")
+		return "", false
+	}
+	return value, true
+}
@@ -8,34 +8,60 @@ import (
 	"net/http"
 	"strings"
 
-	"tlng/query/auth"
+	"tlng/internal/auth"
 	"tlng/query/service/core"
 )
 
 // Handler wraps the query service with HTTP handlers
 type Handler struct {
-	service *core.Service
-	logger  *log.Logger
+	service        *core.Service
+	adminMemberIDs []string
+	gateway        *auth.GatewayVerifier
+	logger         *log.Logger
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(service *core.Service, logger *log.Logger) *Handler {
+// adminMemberIDs are the mTLS members allowed to use the admin API;
+// gateway checks the ingress signature of the identity headers, nil trusts them
+func NewHandler(service *core.Service, adminMemberIDs []string, gateway *auth.GatewayVerifier, logger *log.Logger) *Handler {
 	return &Handler{
-		service: service,
-		logger:  logger,
+		service:        service,
+		adminMemberIDs: adminMemberIDs,
+		gateway:        gateway,
+		logger:         logger,
 	}
 }
 
 // RegisterRoutes registers all query API routes
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	// Every route trusts identity headers only with the ingress signature
+	requireGateway := auth.RequireGateway(h.gateway)
+
 	// API 1: Query by request_id (API Key auth)
-	mux.Handle("/v1/query/status/", auth.RequireAPIKey(http.HandlerFunc(h.GetStatusByRequestID)))
+	mux.Handle("/v1/query/status/", requireGateway(auth.RequireAPIKey(http.HandlerFunc(h.GetStatusByRequestID))))
 
 	// API 2: Query by log content (API Key auth)
-	mux.Handle("/v1/query_by_content", auth.RequireAPIKey(http.HandlerFunc(h.QueryByContent)))
+	mux.Handle("/v1/query_by_content", requireGateway(auth.RequireAPIKey(http.HandlerFunc(h.QueryByContent))))
 
 	// API 3: Audit log by hash (mTLS auth)
-	mux.Handle("/v1/audit/log/", auth.RequireMTLS(http.HandlerFunc(h.AuditLogByHash)))
+	mux.Handle("/v1/audit/log/", requireGateway(auth.RequireMTLS(http.HandlerFunc(h.AuditLogByHash))))
+
+	// API 4: Verify caller-supplied content against the chain (mTLS auth)
+	mux.Handle("/v1/audit/verify", requireGateway(auth.RequireMTLS(http.HandlerFunc(h.VerifyLogContent))))
+
+	// API 5: Audit the logs of a transaction (mTLS auth)
+	mux.Handle("/log/by_tx/", requireGateway(auth.RequireMTLS(http.HandlerFunc(h.AuditLogsByTx))))
+
+	// Admin API: erasures, retention policies, log schemas and client keys (mTLS auth, admin members only)
+	requireAdmin := func(next http.Handler) http.Handler {
+		return requireGateway(auth.RequireAdmin(h.adminMemberIDs)(next))
+	}
+	mux.Handle("/v1/admin/erasures", requireAdmin(http.HandlerFunc(h.EraseLog)))
+	mux.Handle("/v1/admin/erasures/", requireAdmin(http.HandlerFunc(h.GetErasure)))
+	mux.Handle("/v1/admin/retention-policies", requireAdmin(http.HandlerFunc(h.ListRetentionPolicies)))
+	mux.Handle("/v1/admin/retention-policies/", requireAdmin(http.HandlerFunc(h.RetentionPolicy)))
+	mux.Handle("/v1/admin/schemas/", requireAdmin(http.HandlerFunc(h.LogSchemas)))
+	mux.Handle("/v1/admin/client-keys/", requireAdmin(http.HandlerFunc(h.ClientKeys)))
 }
 
 // GetStatusByRequestID handles GET /v1/query/status/{request_id}
@@ -78,7 +104,9 @@ func (h *Handler) GetStatusByRequestID(w http.ResponseWriter, r *http.Request) {
 
 // QueryByContentRequest represents the request body for content query
 type QueryByContentRequest struct {
-	LogContent string `json:"log_content"`
+	LogContent    string          `json:"log_content"`
+	LogJSON       json.RawMessage `json:"log_json,omitempty"`       // Structured log instead of log_content, in any serialization
+	HashAlgorithm string          `json:"hash_algorithm,omitempty"` // Optional, algorithm used at submission
 }
 
 // QueryByContent handles POST /v1/query_by_content
@@ -104,7 +132,11 @@ func (h *Handler) QueryByContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.TrimSpace(req.LogContent) == "" {
+	logContent, ok := h.requestContent(w, req.LogContent, req.LogJSON)
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(logContent) == "" {
 		h.writeError(w, http.StatusBadRequest, "log_content is required")
 		return
 	}
@@ -117,7 +149,7 @@ func (h *Handler) QueryByContent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service
-	result, err := h.service.QueryByContent(r.Context(), req.LogContent, authCtx.OrgID)
+	result, err := h.service.QueryByContent(r.Context(), logContent, req.HashAlgorithm, authCtx.OrgID)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -160,7 +192,50 @@ func (h *Handler) AuditLogByHash(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service (no org restriction for consortium members)
-	result, err := h.service.AuditLogByHash(r.Context(), logHash)
+	result, err := h.service.AuditLogByHash(r.Context(), logHash, authCtx.MemberID)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// AuditLogsByTx handles GET /log/by_tx/{tx_hash}
+func (h *Handler) AuditLogsByTx(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract tx_hash from path
+	path := strings.TrimPrefix(r.URL.Path, "/log/by_tx/")
+	txHash := strings.TrimSpace(path)
+	if txHash == "" {
+		h.writeError(w, http.StatusBadRequest, "missing tx_hash")
+		return
+	}
+
+	// Validate tx_hash to prevent path traversal
+	if strings.Contains(txHash, "..") || strings.Contains(txHash, "/") {
+		h.writeError(w, http.StatusBadRequest, "invalid tx_hash: path traversal characters not allowed")
+		return
+	}
+
+	// Extract auth context (mTLS, member_id required)
+	authCtx := auth.ExtractAuthContext(r)
+	if authCtx == nil {
+		h.writeError(w, http.StatusUnauthorized, "missing authentication context")
+		return
+	}
+
+	if authCtx.MemberID == "" {
+		h.writeError(w, http.StatusForbidden, "member_id required for audit API")
+		return
+	}
+
+	// Call service (no org restriction for consortium members)
+	result, err := h.service.AuditLogsByTx(r.Context(), txHash, authCtx.MemberID)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -169,6 +244,87 @@ func (h *Handler) AuditLogByHash(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, result)
 }
 
+// VerifyLogContentRequest represents the request body for content verification
+type VerifyLogContentRequest struct {
+	LogContent    string          `json:"log_content"`
+	LogJSON       json.RawMessage `json:"log_json,omitempty"`       // Structured log instead of log_content, in any serialization
+	LogHash       string          `json:"log_hash,omitempty"`       // Optional, check only this record
+	HashAlgorithm string          `json:"hash_algorithm,omitempty"` // Optional, algorithm used at submission
+}
+
+// VerifyLogContent handles POST /v1/audit/verify
+func (h *Handler) VerifyLogContent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Ensure the request body is closed when we're done
+	defer r.Body.Close()
+
+	// Parse request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+
+	var req VerifyLogContentRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	logContent, ok := h.requestContent(w, req.LogContent, req.LogJSON)
+	if !ok {
+		return
+	}
+	if logContent == "" {
+		h.writeError(w, http.StatusBadRequest, "log_content is required")
+		return
+	}
+
+	// Extract auth context (mTLS, member_id required)
+	authCtx := auth.ExtractAuthContext(r)
+	if authCtx == nil {
+		h.writeError(w, http.StatusUnauthorized, "missing authentication context")
+		return
+	}
+
+	if authCtx.MemberID == "" {
+		h.writeError(w, http.StatusForbidden, "member_id required for audit API")
+		return
+	}
+
+	// Call service (no org restriction for consortium members)
+	result, err := h.service.VerifyLogContent(r.Context(), logContent, req.LogHash, req.HashAlgorithm)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// requestContent returns the content to hash: logContent as is, or logJSON in the canonical
+// form it was hashed in at submission. It writes a 400 if both are set or logJSON is invalid.
+func (h *Handler) requestContent(w http.ResponseWriter, logContent string, logJSON json.RawMessage) (string, bool) {
+	if len(logJSON) == 0 {
+		return logContent, true
+	}
+	if logContent != "" {
+		h.writeError(w, http.StatusBadRequest, "log_content and log_json are mutually exclusive")
+		return "", false
+	}
+
+	canonical, err := core.CanonicalizeLogJSON(logJSON)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+	return canonical, true
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -200,6 +356,17 @@ func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 		h.writeError(w, http.StatusBadRequest, err.Error())
 	case errors.Is(err, core.ErrBlockchainError):
 		h.writeError(w, http.StatusInternalServerError, err.Error())
+	case errors.Is(err, core.ErrDecryptionFailed):
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+	case errors.Is(err, core.ErrBlobUnavailable):
+		h.writeError(w, http.StatusBadGateway, err.Error())
+	case errors.Is(err, core.ErrBlobIntegrity):
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+	case errors.Is(err, core.ErrErasureNotFound), errors.Is(err, core.ErrRetentionPolicyNotFound), errors.Is(err, core.ErrLogSchemaNotFound),
+		errors.Is(err, core.ErrClientKeyNotFound):
+		h.writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, core.ErrNotErasable), errors.Is(err, core.ErrClientKeyExists):
+		h.writeError(w, http.StatusConflict, err.Error())
 	default:
 		h.writeError(w, http.StatusInternalServerError, "internal server error")
 	}
@@ -7,19 +7,185 @@ CREATE TABLE IF NOT EXISTS tbl_log_status (
     log_hash TEXT NOT NULL,
     source_org_id TEXT,
     received_timestamp TIMESTAMPTZ,
+    client_timestamp TIMESTAMPTZ,
+    client_timestamp_flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    attestation_mode VARCHAR(20) NOT NULL DEFAULT 'full',
+    content_format VARCHAR(20) NOT NULL DEFAULT 'text',
+    category TEXT,
+    schema_version INTEGER,
     status VARCHAR(20) NOT NULL DEFAULT 'RECEIVED',
     received_at_db TIMESTAMPTZ NOT NULL DEFAULT NOW(),
     processing_started_at TIMESTAMPTZ,
     processing_finished_at TIMESTAMPTZ,
     tx_hash TEXT,
     block_height BIGINT,
+    block_timestamp TIMESTAMPTZ,
     log_hash_on_chain TEXT,
     error_message TEXT,
     retry_count INTEGER NOT NULL DEFAULT 0
 );
 
+-- Event time columns for databases created before they were added to the table definition
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS client_timestamp TIMESTAMPTZ;
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS client_timestamp_flagged BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS block_timestamp TIMESTAMPTZ;
+
+-- Attestation mode for databases created before it was added to the table definition
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS attestation_mode VARCHAR(20) NOT NULL DEFAULT 'full';
+
+-- Content format for databases created before structured logs; 'json' marks RFC 8785 canonicalized content
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS content_format VARCHAR(20) NOT NULL DEFAULT 'text';
+
+-- Schema category and version of structured logs validated against the org's schema registry
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS category TEXT;
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS schema_version INTEGER;
+
+-- Erasure time; set when the log's content was crypto-shredded
+ALTER TABLE tbl_log_status ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
+
 -- Indexes for query APIs
 -- API 1: GET /v1/query/status/{request_id} - uses request_id (already PRIMARY KEY, no extra index needed)
 -- API 2: POST /v1/query_by_content - uses log_hash for content-based lookup
 CREATE INDEX IF NOT EXISTS idx_log_status_log_hash ON tbl_log_status (log_hash);
--- API 3: GET /v1/audit/log/{log_hash} - uses log_hash (covered by above index)
\ No newline at end of file
+-- API 3: GET /v1/audit/log/{log_hash} - uses log_hash (covered by above index)
+-- GET /log/by_tx/{tx_hash} - uses tx_hash to list the logs of a transaction
+CREATE INDEX IF NOT EXISTS idx_log_status_tx_hash ON tbl_log_status (tx_hash) WHERE tx_hash IS NOT NULL;
+
+-- Transactional outbox for Kafka messages
+-- Rows are inserted in the same statement as tbl_log_status rows and published by the ingestion outbox relay
+CREATE TABLE IF NOT EXISTS tbl_log_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    request_id TEXT NOT NULL,
+    source_org_id TEXT,
+    payload BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+-- Rows that can never be published (undecodable payload) are set aside instead of blocking the relay
+ALTER TABLE tbl_log_outbox ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ;
+ALTER TABLE tbl_log_outbox ADD COLUMN IF NOT EXISTS error_message TEXT;
+
+-- Relay scans unsent rows in id order
+CREATE INDEX IF NOT EXISTS idx_log_outbox_pending ON tbl_log_outbox (id) WHERE sent_at IS NULL;
+-- Purge of sent rows past the retention
+CREATE INDEX IF NOT EXISTS idx_log_outbox_sent_at ON tbl_log_outbox (sent_at) WHERE sent_at IS NOT NULL;
+
+-- Idempotency keys for log submission, scoped per org and kept until expires_at
+CREATE TABLE IF NOT EXISTS tbl_idempotency_key (
+    source_org_id TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+    log_hash TEXT NOT NULL,
+    received_timestamp TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (source_org_id, idempotency_key)
+);
+
+-- Purge of expired keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_key_expires_at ON tbl_idempotency_key (expires_at);
+
+-- Per-record content keys, wrapped with the org data key
+-- wrapped_key is set to NULL to crypto-shred the record's on-chain content
+-- Keyed per org, so orgs submitting the same content never share or erase each other's keys
+CREATE TABLE IF NOT EXISTS tbl_content_key (
+    log_hash TEXT NOT NULL,
+    source_org_id TEXT NOT NULL,
+    org_key_id TEXT NOT NULL,
+    wrapped_key TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    erased_at TIMESTAMPTZ,
+    PRIMARY KEY (source_org_id, log_hash)
+);
+
+-- Retention expiry scans live keys per org by age
+CREATE INDEX IF NOT EXISTS idx_content_key_retention ON tbl_content_key (source_org_id, created_at) WHERE erased_at IS NULL;
+
+-- Erasures and their on-chain tombstones, per org like the content keys
+CREATE TABLE IF NOT EXISTS tbl_erasure (
+    log_hash TEXT NOT NULL,
+    source_org_id TEXT NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL,
+    reason TEXT,
+    requested_by TEXT,
+    erased_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    tx_hash TEXT,
+    block_height BIGINT,
+    anchored_at TIMESTAMPTZ,
+    last_error TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (source_org_id, log_hash)
+);
+
+-- Databases created when content keys and erasures were keyed by log_hash alone
+DO $$
+BEGIN
+    IF (SELECT array_length(conkey, 1) FROM pg_constraint WHERE conname = 'tbl_content_key_pkey') = 1 THEN
+        ALTER TABLE tbl_content_key DROP CONSTRAINT tbl_content_key_pkey;
+        ALTER TABLE tbl_content_key ADD PRIMARY KEY (source_org_id, log_hash);
+    END IF;
+    IF (SELECT array_length(conkey, 1) FROM pg_constraint WHERE conname = 'tbl_erasure_pkey') = 1 THEN
+        ALTER TABLE tbl_erasure DROP CONSTRAINT tbl_erasure_pkey;
+        ALTER TABLE tbl_erasure ADD PRIMARY KEY (source_org_id, log_hash);
+    END IF;
+END $$;
+
+-- Audits look up the erasures of a log_hash across orgs
+CREATE INDEX IF NOT EXISTS idx_erasure_log_hash ON tbl_erasure (log_hash);
+
+-- Engine anchors pending tombstones oldest first
+CREATE INDEX IF NOT EXISTS idx_erasure_pending ON tbl_erasure (erased_at) WHERE status = 'PENDING';
+
+-- Per-org retention of readable on-chain content
+CREATE TABLE IF NOT EXISTS tbl_retention_policy (
+    source_org_id TEXT PRIMARY KEY,
+    retention_seconds BIGINT NOT NULL CHECK (retention_seconds > 0),
+    updated_by TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Per-org JSON Schemas of structured log categories; every registration adds a version
+CREATE TABLE IF NOT EXISTS tbl_log_schema (
+    source_org_id TEXT NOT NULL,
+    category TEXT NOT NULL,
+    version INTEGER NOT NULL CHECK (version > 0),
+    schema_json TEXT NOT NULL,
+    created_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (source_org_id, category, version)
+);
+
+-- Public keys clients sign log hashes with; revoked keys are kept so old signatures stay attributable
+CREATE TABLE IF NOT EXISTS tbl_client_key (
+    source_org_id TEXT NOT NULL,
+    key_id TEXT NOT NULL,
+    algorithm VARCHAR(16) NOT NULL CHECK (algorithm IN ('ed25519', 'ecdsa-p256', 'sm2')),
+    public_key_pem TEXT NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    created_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    PRIMARY KEY (source_org_id, key_id)
+);
+
+-- Ingestion progress of S3 objects polled by the ingestion service; one row per object version
+CREATE TABLE IF NOT EXISTS tbl_s3_checkpoint (
+    source_name TEXT NOT NULL,
+    bucket TEXT NOT NULL,
+    object_key TEXT NOT NULL,
+    etag TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'IN_PROGRESS' CHECK (status IN ('IN_PROGRESS', 'COMPLETED', 'FAILED')),
+    next_line BIGINT NOT NULL DEFAULT 1,
+    next_offset BIGINT NOT NULL DEFAULT 0,
+    records BIGINT NOT NULL DEFAULT 0,
+    rejected BIGINT NOT NULL DEFAULT 0,
+    lease_owner TEXT NOT NULL,
+    lease_expires_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    PRIMARY KEY (source_name, bucket, object_key, etag)
+);
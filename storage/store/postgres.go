@@ -107,7 +107,8 @@ func (s *PostgresStore) GetAndMarkBatchAsProcessing(ctx context.Context, request
             tbl_log_status.received_timestamp,
             tbl_log_status.status, -- Will be 'PROCESSING'
             tbl_log_status.retry_count,
-            tbl_log_status.processing_started_at;
+            tbl_log_status.processing_started_at,
+            tbl_log_status.erased_at;
     `
 
 	// We keep your original BeginFunc pattern for transactional safety
@@ -148,6 +149,7 @@ func (s *PostgresStore) GetAndMarkBatchAsProcessing(ctx context.Context, request
 				&task.Status,
 				&task.RetryCount,
 				&processingStartedAt, // Scan into the local variable
+				&task.ErasedAt,
 			); err != nil {
 				return fmt.Errorf("failed to scan processed task row: %w", err)
 			}
@@ -186,12 +188,14 @@ func (s *PostgresStore) MarkBatchAsCompleted(ctx context.Context, completions []
 		txHashes := make([]string, len(completions))
 		logHashes := make([]string, len(completions))
 		blockHeights := make([]int64, len(completions))
+		blockTimestamps := make([]*time.Time, len(completions))
 
 		for i, c := range completions {
 			requestIDs[i] = c.RequestID
 			txHashes[i] = c.TxHash
 			logHashes[i] = c.LogHashOnChain
 			blockHeights[i] = int64(c.BlockHeight)
+			blockTimestamps[i] = c.BlockTimestamp
 		}
 
 		updateQuery := `
@@ -200,6 +204,7 @@ func (s *PostgresStore) MarkBatchAsCompleted(ctx context.Context, completions []
                 tx_hash = data.tx_hash,
                 log_hash_on_chain = data.log_hash,
                 block_height = data.block_height,
+                block_timestamp = data.block_timestamp,
                 processing_finished_at = $1,
                 error_message = NULL
             FROM (
@@ -207,7 +212,8 @@ func (s *PostgresStore) MarkBatchAsCompleted(ctx context.Context, completions []
                     request_id,
                     ($3::text[])[idx] AS tx_hash,
                     ($4::text[])[idx] AS log_hash,
-                    ($5::bigint[])[idx] AS block_height
+                    ($5::bigint[])[idx] AS block_height,
+                    ($6::timestamptz[])[idx] AS block_timestamp
                 FROM
                     UNNEST($2::text[]) WITH ORDINALITY AS t(request_id, idx)
             ) AS data
@@ -221,6 +227,7 @@ func (s *PostgresStore) MarkBatchAsCompleted(ctx context.Context, completions []
 			txHashes,
 			logHashes,
 			blockHeights,
+			blockTimestamps,
 		)
 		if err != nil {
 			return fmt.Errorf("batch update failed: %w", err)
@@ -356,6 +363,12 @@ func (s *PostgresStore) InsertLogStatusBatch(ctx context.Context, statuses []*Lo
 	sourceOrgIDs := make([]string, len(statuses))
 	receivedTimestamps := make([]time.Time, len(statuses))
 	statusStrings := make([]string, len(statuses))
+	clientTimestamps := make([]*time.Time, len(statuses)) // NULL when the client sent none
+	clientTimestampFlags := make([]bool, len(statuses))
+	attestationModes := make([]string, len(statuses))
+	contentFormats := make([]string, len(statuses))
+	categories := make([]*string, len(statuses))  // NULL for logs without a category
+	schemaVersions := make([]*int, len(statuses)) // NULL for logs without a category
 	// retry_count is static (0), so we don't need a slice for it
 
 	for i, status := range statuses {
@@ -364,6 +377,12 @@ func (s *PostgresStore) InsertLogStatusBatch(ctx context.Context, statuses []*Lo
 		sourceOrgIDs[i] = status.SourceOrgID
 		receivedTimestamps[i] = status.ReceivedTimestamp
 		statusStrings[i] = string(status.Status)
+		clientTimestamps[i] = status.ClientTimestamp
+		clientTimestampFlags[i] = status.ClientTimestampFlagged
+		attestationModes[i] = status.AttestationMode
+		contentFormats[i] = status.ContentFormat
+		categories[i] = status.Category
+		schemaVersions[i] = status.SchemaVersion
 	}
 
 	// 2. Construct a single query using UNNEST WITH ORDINALITY
@@ -374,7 +393,13 @@ func (s *PostgresStore) InsertLogStatusBatch(ctx context.Context, statuses []*Lo
             source_org_id, 
             received_timestamp, 
             status, 
-            retry_count
+            retry_count,
+            client_timestamp,
+            client_timestamp_flagged,
+            attestation_mode,
+            content_format,
+            category,
+            schema_version
         )
         SELECT
             request_id,                             -- From the UNNEST
@@ -382,7 +407,13 @@ func (s *PostgresStore) InsertLogStatusBatch(ctx context.Context, statuses []*Lo
             ($3::text[])[idx] AS source_org_id,     -- Indexed from param $3
             ($4::timestamptz[])[idx] AS received_timestamp, -- Indexed from param $4
             ($5::text[])[idx] AS status,            -- Indexed from param $5
-            0 AS retry_count                        -- Static value
+            0 AS retry_count,                       -- Static value
+            ($6::timestamptz[])[idx] AS client_timestamp,   -- Indexed from param $6
+            ($7::boolean[])[idx] AS client_timestamp_flagged, -- Indexed from param $7
+            ($8::text[])[idx] AS attestation_mode,  -- Indexed from param $8
+            ($9::text[])[idx] AS content_format,    -- Indexed from param $9
+            ($10::text[])[idx] AS category,         -- Indexed from param $10
+            ($11::integer[])[idx] AS schema_version -- Indexed from param $11
         FROM
             -- Unnest the primary key array to drive the loop
             UNNEST($1::text[]) WITH ORDINALITY AS t(request_id, idx)
@@ -391,11 +422,17 @@ func (s *PostgresStore) InsertLogStatusBatch(ctx context.Context, statuses []*Lo
 
 	// 3. Execute the single query
 	_, err := s.db.Exec(queryCtx, query,
-		requestIDs,         // $1
-		logHashes,          // $2
-		sourceOrgIDs,       // $3
-		receivedTimestamps, // $4
-		statusStrings,      // $5
+		requestIDs,           // $1
+		logHashes,            // $2
+		sourceOrgIDs,         // $3
+		receivedTimestamps,   // $4
+		statusStrings,        // $5
+		clientTimestamps,     // $6
+		clientTimestampFlags, // $7
+		attestationModes,     // $8
+		contentFormats,       // $9
+		categories,           // $10
+		schemaVersions,       // $11
 	)
 
 	if err != nil {
@@ -409,8 +446,9 @@ func (s *PostgresStore) InsertLogStatusBatch(ctx context.Context, statuses []*Lo
 func (s *PostgresStore) GetLogStatusByRequestID(ctx context.Context, requestID string) (*LogStatus, error) {
 	query := `
 		SELECT request_id, log_hash, source_org_id, received_timestamp,
+		       client_timestamp, client_timestamp_flagged, attestation_mode, content_format, category, schema_version,
 		       status, received_at_db, processing_started_at, processing_finished_at,
-		       tx_hash, block_height, log_hash_on_chain, error_message, retry_count
+		       tx_hash, block_height, block_timestamp, log_hash_on_chain, error_message, retry_count, erased_at
 		FROM tbl_log_status
 		WHERE request_id = $1
 	`
@@ -421,15 +459,23 @@ func (s *PostgresStore) GetLogStatusByRequestID(ctx context.Context, requestID s
 		&status.LogHash,
 		&status.SourceOrgID,
 		&status.ReceivedTimestamp,
+		&status.ClientTimestamp,
+		&status.ClientTimestampFlagged,
+		&status.AttestationMode,
+		&status.ContentFormat,
+		&status.Category,
+		&status.SchemaVersion,
 		&status.Status,
 		&status.ReceivedAtDB,
 		&status.ProcessingStartedAt,
 		&status.ProcessingFinishedAt,
 		&status.TxHash,
 		&status.BlockHeight,
+		&status.BlockTimestamp,
 		&status.LogHashOnChain,
 		&status.ErrorMessage,
 		&status.RetryCount,
+		&status.ErasedAt,
 	)
 
 	if err != nil {
@@ -446,8 +492,9 @@ func (s *PostgresStore) GetLogStatusByRequestID(ctx context.Context, requestID s
 func (s *PostgresStore) GetLogStatusByHash(ctx context.Context, logHash string) (*LogStatus, error) {
 	query := `
 		SELECT request_id, log_hash, source_org_id, received_timestamp,
+		       client_timestamp, client_timestamp_flagged, attestation_mode, content_format, category, schema_version,
 		       status, received_at_db, processing_started_at, processing_finished_at,
-		       tx_hash, block_height, log_hash_on_chain, error_message, retry_count
+		       tx_hash, block_height, block_timestamp, log_hash_on_chain, error_message, retry_count, erased_at
 		FROM tbl_log_status
 		WHERE log_hash = $1
 	`
@@ -458,15 +505,23 @@ func (s *PostgresStore) GetLogStatusByHash(ctx context.Context, logHash string)
 		&status.LogHash,
 		&status.SourceOrgID,
 		&status.ReceivedTimestamp,
+		&status.ClientTimestamp,
+		&status.ClientTimestampFlagged,
+		&status.AttestationMode,
+		&status.ContentFormat,
+		&status.Category,
+		&status.SchemaVersion,
 		&status.Status,
 		&status.ReceivedAtDB,
 		&status.ProcessingStartedAt,
 		&status.ProcessingFinishedAt,
 		&status.TxHash,
 		&status.BlockHeight,
+		&status.BlockTimestamp,
 		&status.LogHashOnChain,
 		&status.ErrorMessage,
 		&status.RetryCount,
+		&status.ErasedAt,
 	)
 
 	if err != nil {
@@ -478,3 +533,57 @@ func (s *PostgresStore) GetLogStatusByHash(ctx context.Context, logHash string)
 
 	return &status, nil
 }
+
+// ListLogStatusesByTxHash returns the logs anchored in a transaction, in the order they were received
+func (s *PostgresStore) ListLogStatusesByTxHash(ctx context.Context, txHash string) ([]*LogStatus, error) {
+	query := `
+		SELECT request_id, log_hash, source_org_id, received_timestamp,
+		       client_timestamp, client_timestamp_flagged, attestation_mode, content_format, category, schema_version,
+		       status, received_at_db, processing_started_at, processing_finished_at,
+		       tx_hash, block_height, block_timestamp, log_hash_on_chain, error_message, retry_count, erased_at
+		FROM tbl_log_status
+		WHERE tx_hash = $1
+		ORDER BY received_timestamp, request_id
+	`
+
+	rows, err := s.db.Query(ctx, query, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log status by tx_hash: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []*LogStatus
+	for rows.Next() {
+		var status LogStatus
+		if err := rows.Scan(
+			&status.RequestID,
+			&status.LogHash,
+			&status.SourceOrgID,
+			&status.ReceivedTimestamp,
+			&status.ClientTimestamp,
+			&status.ClientTimestampFlagged,
+			&status.AttestationMode,
+			&status.ContentFormat,
+			&status.Category,
+			&status.SchemaVersion,
+			&status.Status,
+			&status.ReceivedAtDB,
+			&status.ProcessingStartedAt,
+			&status.ProcessingFinishedAt,
+			&status.TxHash,
+			&status.BlockHeight,
+			&status.BlockTimestamp,
+			&status.LogHashOnChain,
+			&status.ErrorMessage,
+			&status.RetryCount,
+			&status.ErasedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan log status row: %w", err)
+		}
+		statuses = append(statuses, &status)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating log status rows: %w", rows.Err())
+	}
+	return statuses, nil
+}
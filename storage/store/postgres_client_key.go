@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const clientKeyColumns = `source_org_id, key_id, algorithm, public_key_pem, fingerprint, created_by, created_at, revoked_at`
+
+// CreateClientKey registers a public key; key IDs are never reused, so an existing one is a conflict
+func (s *PostgresStore) CreateClientKey(ctx context.Context, key *ClientKey) error {
+	err := s.db.QueryRow(ctx, `
+        INSERT INTO tbl_client_key (source_org_id, key_id, algorithm, public_key_pem, fingerprint, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        ON CONFLICT (source_org_id, key_id) DO NOTHING
+        RETURNING created_at
+    `, key.SourceOrgID, key.KeyID, key.Algorithm, key.PublicKey, key.Fingerprint, key.CreatedBy).Scan(&key.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrClientKeyExists
+		}
+		return fmt.Errorf("failed to insert client key: %w", err)
+	}
+	return nil
+}
+
+// GetClientKey returns a public key of an org, including revoked ones
+func (s *PostgresStore) GetClientKey(ctx context.Context, sourceOrgID, keyID string) (*ClientKey, error) {
+	query := `
+        SELECT ` + clientKeyColumns + `
+        FROM tbl_client_key
+        WHERE source_org_id = $1 AND key_id = $2
+    `
+	return scanClientKey(s.db.QueryRow(ctx, query, sourceOrgID, keyID))
+}
+
+// ListClientKeys returns all public keys of an org ordered by key ID
+func (s *PostgresStore) ListClientKeys(ctx context.Context, sourceOrgID string) ([]*ClientKey, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT `+clientKeyColumns+`
+        FROM tbl_client_key
+        WHERE source_org_id = $1
+        ORDER BY key_id
+    `, sourceOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*ClientKey
+	for rows.Next() {
+		key, err := scanClientKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating client key rows: %w", rows.Err())
+	}
+	return keys, nil
+}
+
+// RevokeClientKey sets revoked_at once and returns the key
+func (s *PostgresStore) RevokeClientKey(ctx context.Context, sourceOrgID, keyID string) (*ClientKey, error) {
+	query := `
+        UPDATE tbl_client_key
+        SET revoked_at = COALESCE(revoked_at, NOW())
+        WHERE source_org_id = $1 AND key_id = $2
+        RETURNING ` + clientKeyColumns
+	return scanClientKey(s.db.QueryRow(ctx, query, sourceOrgID, keyID))
+}
+
+// scanClientKey scans one row selected with clientKeyColumns
+func scanClientKey(row pgx.Row) (*ClientKey, error) {
+	var key ClientKey
+	var createdBy *string
+	err := row.Scan(
+		&key.SourceOrgID,
+		&key.KeyID,
+		&key.Algorithm,
+		&key.PublicKey,
+		&key.Fingerprint,
+		&createdBy,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrClientKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to scan client key: %w", err)
+	}
+	if createdBy != nil {
+		key.CreatedBy = *createdBy
+	}
+	return &key, nil
+}
@@ -0,0 +1,483 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+
+	"tlng/internal/models"
+)
+
+// erasureAnchorLockKey is the advisory lock that lets only one engine submit tombstones at a time
+const erasureAnchorLockKey int64 = 0x746c6e67657261 // "tlngera"
+
+// retentionEraseReason is recorded for erasures triggered by a retention policy
+const retentionEraseReason = "retention policy expired"
+
+const erasureColumns = `log_hash, source_org_id, trigger_type, reason, requested_by, erased_at,
+            status, tx_hash, block_height, anchored_at, last_error, attempts`
+
+// InsertContentKeys stores record keys in a single statement; existing keys win, so a retried
+// batch keeps the key its content may already be encrypted with on chain
+func (s *PostgresStore) InsertContentKeys(ctx context.Context, keys []*ContentKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	logHashes := make([]string, len(keys))
+	sourceOrgIDs := make([]string, len(keys))
+	orgKeyIDs := make([]string, len(keys))
+	wrappedKeys := make([]*string, len(keys))
+	for i, key := range keys {
+		logHashes[i] = key.LogHash
+		sourceOrgIDs[i] = key.SourceOrgID
+		orgKeyIDs[i] = key.OrgKeyID
+		wrappedKeys[i] = key.WrappedKey
+	}
+
+	query := `
+        INSERT INTO tbl_content_key (log_hash, source_org_id, org_key_id, wrapped_key)
+        SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[])
+        ON CONFLICT (source_org_id, log_hash) DO NOTHING
+    `
+	if _, err := s.db.Exec(queryCtx, query, logHashes, sourceOrgIDs, orgKeyIDs, wrappedKeys); err != nil {
+		return fmt.Errorf("failed to insert content keys: %w", err)
+	}
+	return nil
+}
+
+// GetContentKeys returns the stored record keys of the given logs
+func (s *PostgresStore) GetContentKeys(ctx context.Context, ids []ContentKeyID) (map[ContentKeyID]*ContentKey, error) {
+	keys := make(map[ContentKeyID]*ContentKey, len(ids))
+	if len(ids) == 0 {
+		return keys, nil
+	}
+
+	sourceOrgIDs := make([]string, len(ids))
+	logHashes := make([]string, len(ids))
+	for i, id := range ids {
+		sourceOrgIDs[i] = id.SourceOrgID
+		logHashes[i] = id.LogHash
+	}
+
+	rows, err := s.db.Query(ctx, `
+        SELECT k.log_hash, k.source_org_id, k.org_key_id, k.wrapped_key, k.created_at, k.erased_at
+        FROM tbl_content_key k
+        JOIN unnest($1::text[], $2::text[]) AS t(source_org_id, log_hash)
+          ON k.source_org_id = t.source_org_id AND k.log_hash = t.log_hash
+    `, sourceOrgIDs, logHashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query content keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key ContentKey
+		if err := rows.Scan(&key.LogHash, &key.SourceOrgID, &key.OrgKeyID, &key.WrappedKey, &key.CreatedAt, &key.ErasedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan content key row: %w", err)
+		}
+		keys[key.ID()] = &key
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating content key rows: %w", rows.Err())
+	}
+	return keys, nil
+}
+
+// EraseContent shreds the record key and records the erasure in one transaction.
+// Logs waiting to be anchored get an erased placeholder key, so the engine anchors them hash-only.
+// Logs anchored (or being anchored) in full mode without a record key carry plaintext content on chain
+// and return ErrContentNotErasable. Other orgs' logs with the same content are not affected.
+func (s *PostgresStore) EraseContent(ctx context.Context, erasure *Erasure) (*Erasure, error) {
+	var result *Erasure
+
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// 1. Resolve the org when the caller did not name it
+		sourceOrgID := erasure.SourceOrgID
+		if sourceOrgID == "" {
+			rows, err := tx.Query(ctx, `
+                SELECT DISTINCT source_org_id FROM tbl_log_status WHERE log_hash = $1 LIMIT 2
+            `, erasure.LogHash)
+			if err != nil {
+				return fmt.Errorf("failed to query log orgs for erasure: %w", err)
+			}
+			var orgs []string
+			for rows.Next() {
+				var org string
+				if err := rows.Scan(&org); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to scan log org: %w", err)
+				}
+				orgs = append(orgs, org)
+			}
+			rows.Close()
+			if rows.Err() != nil {
+				return fmt.Errorf("error iterating log org rows: %w", rows.Err())
+			}
+			switch len(orgs) {
+			case 0:
+				return ErrLogNotFound
+			case 1:
+				sourceOrgID = orgs[0]
+			default:
+				return ErrLogAmbiguous
+			}
+		}
+
+		// 2. Erasing twice returns the first erasure
+		existing, err := scanErasure(tx.QueryRow(ctx, `
+            SELECT `+erasureColumns+` FROM tbl_erasure WHERE source_org_id = $1 AND log_hash = $2
+        `, sourceOrgID, erasure.LogHash))
+		if err == nil {
+			result = existing
+			return nil
+		}
+		if !errors.Is(err, ErrErasureNotFound) {
+			return err
+		}
+
+		// 3. Lock the org's log rows and find out what is on chain
+		var attestationMode string
+		var status Status
+		err = tx.QueryRow(ctx, `
+            SELECT attestation_mode, status
+            FROM tbl_log_status
+            WHERE source_org_id = $1 AND log_hash = $2
+            ORDER BY (status = $3) DESC, received_at_db
+            LIMIT 1
+            FOR UPDATE
+        `, sourceOrgID, erasure.LogHash, StatusCompleted).Scan(&attestationMode, &status)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrLogNotFound
+			}
+			return fmt.Errorf("failed to load log status for erasure: %w", err)
+		}
+
+		// 4. Destroy the record key, or leave an erased placeholder for logs the engine has not anchored
+		tag, err := tx.Exec(ctx, `
+            UPDATE tbl_content_key SET wrapped_key = NULL, erased_at = $3
+            WHERE source_org_id = $1 AND log_hash = $2
+        `, sourceOrgID, erasure.LogHash, erasure.ErasedAt)
+		if err != nil {
+			return fmt.Errorf("failed to shred content key: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			switch {
+			case attestationMode == models.AttestationModeHashOnly:
+				// No content goes on chain
+			case status == StatusReceived || status == StatusFailed:
+				if _, err := tx.Exec(ctx, `
+                    INSERT INTO tbl_content_key (log_hash, source_org_id, org_key_id, wrapped_key, erased_at)
+                    VALUES ($1, $2, '', NULL, $3)
+                    ON CONFLICT (source_org_id, log_hash) DO UPDATE SET wrapped_key = NULL, erased_at = EXCLUDED.erased_at
+                `, erasure.LogHash, sourceOrgID, erasure.ErasedAt); err != nil {
+					return fmt.Errorf("failed to insert erased content key: %w", err)
+				}
+			default:
+				// Plaintext content is on chain or being anchored
+				return ErrContentNotErasable
+			}
+		}
+
+		// 5. Mark the org's logs and record the erasure; the engine anchors the tombstone
+		if _, err := tx.Exec(ctx, `
+            UPDATE tbl_log_status SET erased_at = $3 WHERE source_org_id = $1 AND log_hash = $2
+        `, sourceOrgID, erasure.LogHash, erasure.ErasedAt); err != nil {
+			return fmt.Errorf("failed to mark log erased: %w", err)
+		}
+		if err := scrubOutboxContent(ctx, tx, []ContentKeyID{{SourceOrgID: sourceOrgID, LogHash: erasure.LogHash}}); err != nil {
+			return err
+		}
+
+		record := *erasure
+		record.SourceOrgID = sourceOrgID
+		record.Status = ErasureStatusPending
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO tbl_erasure (log_hash, source_org_id, trigger_type, reason, requested_by, erased_at, status)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+        `, record.LogHash, record.SourceOrgID, record.Trigger, record.Reason, record.RequestedBy, record.ErasedAt, record.Status); err != nil {
+			return fmt.Errorf("failed to insert erasure: %w", err)
+		}
+
+		result = &record
+		return nil // Commit transaction
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// EraseExpiredContent shreds record keys created before their org's retention window in a single statement
+func (s *PostgresStore) EraseExpiredContent(ctx context.Context, now time.Time, limit int) ([]ContentKeyID, error) {
+	query := `
+        WITH expired AS (
+            SELECT k.log_hash, k.source_org_id
+            FROM tbl_content_key k
+            JOIN tbl_retention_policy p ON p.source_org_id = k.source_org_id
+            WHERE k.erased_at IS NULL
+              AND k.created_at < $1::timestamptz - make_interval(secs => p.retention_seconds)
+            ORDER BY k.created_at
+            LIMIT $2
+            FOR UPDATE OF k SKIP LOCKED
+        ),
+        shredded AS (
+            UPDATE tbl_content_key k
+            SET wrapped_key = NULL, erased_at = $1
+            FROM expired
+            WHERE k.source_org_id = expired.source_org_id AND k.log_hash = expired.log_hash
+            RETURNING k.log_hash, k.source_org_id
+        ),
+        tombstones AS (
+            INSERT INTO tbl_erasure (log_hash, source_org_id, trigger_type, reason, requested_by, erased_at, status)
+            SELECT log_hash, source_org_id, $3, $4, 'system', $1, $5
+            FROM shredded
+            ON CONFLICT (source_org_id, log_hash) DO NOTHING
+        ),
+        marked AS (
+            UPDATE tbl_log_status
+            SET erased_at = $1
+            FROM shredded
+            WHERE tbl_log_status.source_org_id = shredded.source_org_id
+              AND tbl_log_status.log_hash = shredded.log_hash
+        )
+        SELECT source_org_id, log_hash FROM shredded
+    `
+
+	var erased []ContentKeyID
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, now, limit, ErasureTriggerRetention, retentionEraseReason, ErasureStatusPending)
+		if err != nil {
+			return fmt.Errorf("failed to erase expired content: %w", err)
+		}
+		for rows.Next() {
+			var id ContentKeyID
+			if err := rows.Scan(&id.SourceOrgID, &id.LogHash); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan erased content key: %w", err)
+			}
+			erased = append(erased, id)
+		}
+		rows.Close()
+		if rows.Err() != nil {
+			return fmt.Errorf("failed to erase expired content: %w", rows.Err())
+		}
+
+		// Outbox payloads of the erased logs go in the same transaction
+		return scrubOutboxContent(ctx, tx, erased)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return erased, nil
+}
+
+// GetErasure queries the erasure of an org's log_hash
+func (s *PostgresStore) GetErasure(ctx context.Context, sourceOrgID, logHash string) (*Erasure, error) {
+	erasure, err := scanErasure(s.db.QueryRow(ctx, `
+        SELECT `+erasureColumns+` FROM tbl_erasure WHERE source_org_id = $1 AND log_hash = $2
+    `, sourceOrgID, logHash))
+	if err != nil {
+		return nil, err
+	}
+	return erasure, nil
+}
+
+// ListErasures returns the erasures of a log_hash for every org, oldest first
+func (s *PostgresStore) ListErasures(ctx context.Context, logHash string) ([]*Erasure, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT `+erasureColumns+` FROM tbl_erasure WHERE log_hash = $1 ORDER BY erased_at
+    `, logHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query erasures: %w", err)
+	}
+	defer rows.Close()
+
+	var erasures []*Erasure
+	for rows.Next() {
+		erasure, err := scanErasure(rows)
+		if err != nil {
+			return nil, err
+		}
+		erasures = append(erasures, erasure)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating erasure rows: %w", rows.Err())
+	}
+	return erasures, nil
+}
+
+// AnchorPendingErasures anchors the oldest pending erasures inside a transaction holding the anchor
+// advisory lock. A failed anchor is recorded on the erasure and does not stop the others.
+func (s *PostgresStore) AnchorPendingErasures(ctx context.Context, limit int, anchor func(erasure *Erasure) (string, uint64, error)) (int, error) {
+	anchored := 0
+
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// 1. Make sure no other engine is anchoring
+		var locked bool
+		if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, erasureAnchorLockKey).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to acquire erasure anchor lock: %w", err)
+		}
+		if !locked {
+			return nil
+		}
+
+		// 2. Load pending erasures, oldest first
+		rows, err := tx.Query(ctx, `
+            SELECT `+erasureColumns+`
+            FROM tbl_erasure
+            WHERE status = $1
+            ORDER BY erased_at
+            LIMIT $2
+        `, ErasureStatusPending, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query pending erasures: %w", err)
+		}
+
+		var pending []*Erasure
+		for rows.Next() {
+			erasure, err := scanErasure(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			pending = append(pending, erasure)
+		}
+		rows.Close()
+		if rows.Err() != nil {
+			return fmt.Errorf("error iterating erasure rows: %w", rows.Err())
+		}
+
+		// 3. Anchor each tombstone and record the outcome
+		for _, erasure := range pending {
+			txHash, blockHeight, anchorErr := anchor(erasure)
+			if anchorErr != nil {
+				if _, err := tx.Exec(ctx, `
+                    UPDATE tbl_erasure SET attempts = attempts + 1, last_error = $3
+                    WHERE source_org_id = $1 AND log_hash = $2
+                `, erasure.SourceOrgID, erasure.LogHash, anchorErr.Error()); err != nil {
+					return fmt.Errorf("failed to record erasure anchor error: %w", err)
+				}
+				continue
+			}
+
+			if _, err := tx.Exec(ctx, `
+                UPDATE tbl_erasure
+                SET status = $3, tx_hash = $4, block_height = $5, anchored_at = NOW(),
+                    attempts = attempts + 1, last_error = NULL
+                WHERE source_org_id = $1 AND log_hash = $2
+            `, erasure.SourceOrgID, erasure.LogHash, ErasureStatusAnchored, txHash, int64(blockHeight)); err != nil {
+				return fmt.Errorf("failed to mark erasure anchored: %w", err)
+			}
+			anchored++
+		}
+
+		return nil // Commit transaction
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return anchored, nil
+}
+
+// UpsertRetentionPolicy creates or replaces an org's retention policy
+func (s *PostgresStore) UpsertRetentionPolicy(ctx context.Context, policy *RetentionPolicy) error {
+	query := `
+        INSERT INTO tbl_retention_policy (source_org_id, retention_seconds, updated_by, updated_at)
+        VALUES ($1, $2, $3, NOW())
+        ON CONFLICT (source_org_id) DO UPDATE
+        SET retention_seconds = EXCLUDED.retention_seconds,
+            updated_by = EXCLUDED.updated_by,
+            updated_at = EXCLUDED.updated_at
+    `
+	if _, err := s.db.Exec(ctx, query, policy.SourceOrgID, int64(policy.Retention/time.Second), policy.UpdatedBy); err != nil {
+		return fmt.Errorf("failed to upsert retention policy: %w", err)
+	}
+	return nil
+}
+
+// ListRetentionPolicies returns all retention policies ordered by org
+func (s *PostgresStore) ListRetentionPolicies(ctx context.Context) ([]*RetentionPolicy, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT source_org_id, retention_seconds, updated_by, updated_at
+        FROM tbl_retention_policy
+        ORDER BY source_org_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*RetentionPolicy
+	for rows.Next() {
+		var policy RetentionPolicy
+		var retentionSeconds int64
+		var updatedBy *string
+		if err := rows.Scan(&policy.SourceOrgID, &retentionSeconds, &updatedBy, &policy.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan retention policy row: %w", err)
+		}
+		policy.Retention = time.Duration(retentionSeconds) * time.Second
+		if updatedBy != nil {
+			policy.UpdatedBy = *updatedBy
+		}
+		policies = append(policies, &policy)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating retention policy rows: %w", rows.Err())
+	}
+	return policies, nil
+}
+
+// DeleteRetentionPolicy removes an org's retention policy
+func (s *PostgresStore) DeleteRetentionPolicy(ctx context.Context, sourceOrgID string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM tbl_retention_policy WHERE source_org_id = $1`, sourceOrgID)
+	if err != nil {
+		return fmt.Errorf("failed to delete retention policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+// scanErasure scans one row selected with erasureColumns
+func scanErasure(row pgx.Row) (*Erasure, error) {
+	var erasure Erasure
+	var reason, requestedBy *string
+	err := row.Scan(
+		&erasure.LogHash,
+		&erasure.SourceOrgID,
+		&erasure.Trigger,
+		&reason,
+		&requestedBy,
+		&erasure.ErasedAt,
+		&erasure.Status,
+		&erasure.TxHash,
+		&erasure.BlockHeight,
+		&erasure.AnchoredAt,
+		&erasure.LastError,
+		&erasure.Attempts,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrErasureNotFound
+		}
+		return nil, fmt.Errorf("failed to scan erasure: %w", err)
+	}
+	if reason != nil {
+		erasure.Reason = *reason
+	}
+	if requestedBy != nil {
+		erasure.RequestedBy = *requestedBy
+	}
+	return &erasure, nil
+}
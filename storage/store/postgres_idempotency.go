@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ReserveIdempotencyKey inserts the record, replacing an expired one with the same org and key.
+// If an unexpired record exists it is returned unchanged.
+func (s *PostgresStore) ReserveIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	insertQuery := `
+        INSERT INTO tbl_idempotency_key (
+            source_org_id, idempotency_key, request_id, log_hash, received_timestamp, expires_at
+        )
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (source_org_id, idempotency_key) DO UPDATE
+        SET request_id = EXCLUDED.request_id,
+            log_hash = EXCLUDED.log_hash,
+            received_timestamp = EXCLUDED.received_timestamp,
+            expires_at = EXCLUDED.expires_at
+        WHERE tbl_idempotency_key.expires_at <= NOW() -- Only take over expired keys
+        RETURNING request_id
+    `
+	selectQuery := `
+        SELECT source_org_id, idempotency_key, request_id, log_hash, received_timestamp, expires_at
+        FROM tbl_idempotency_key
+        WHERE source_org_id = $1 AND idempotency_key = $2
+    `
+
+	// The existing record can be purged between the two queries, so try a few times
+	for attempt := 0; attempt < 3; attempt++ {
+		// 1. Try to reserve the key
+		var requestID string
+		err := s.db.QueryRow(queryCtx, insertQuery,
+			record.SourceOrgID,
+			record.IdempotencyKey,
+			record.RequestID,
+			record.LogHash,
+			record.ReceivedTimestamp,
+			record.ExpiresAt,
+		).Scan(&requestID)
+		if err == nil {
+			return nil, nil // Reserved
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+		}
+
+		// 2. The key is held by an earlier submission, load it
+		var existing IdempotencyRecord
+		err = s.db.QueryRow(queryCtx, selectQuery, record.SourceOrgID, record.IdempotencyKey).Scan(
+			&existing.SourceOrgID,
+			&existing.IdempotencyKey,
+			&existing.RequestID,
+			&existing.LogHash,
+			&existing.ReceivedTimestamp,
+			&existing.ExpiresAt,
+		)
+		if err == nil {
+			return &existing, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("failed to reserve idempotency key '%s': concurrent updates", record.IdempotencyKey)
+}
+
+// ReleaseIdempotencyKey deletes the key if it still belongs to requestID
+func (s *PostgresStore) ReleaseIdempotencyKey(ctx context.Context, sourceOrgID, idempotencyKey, requestID string) error {
+	query := `
+        DELETE FROM tbl_idempotency_key
+        WHERE source_org_id = $1 AND idempotency_key = $2 AND request_id = $3
+    `
+	if _, err := s.db.Exec(ctx, query, sourceOrgID, idempotencyKey, requestID); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes records whose TTL has passed
+func (s *PostgresStore) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM tbl_idempotency_key WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
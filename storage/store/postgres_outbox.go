@@ -0,0 +1,341 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// outboxRelayLockKey is the advisory lock that lets only one relay publish at a time,
+// so outbox records are sent in order even with several ingestion replicas
+const outboxRelayLockKey int64 = 0x746c6e676f7574 // "tlngout"
+
+// InsertLogStatusBatchWithOutbox inserts statuses and outbox records in a single statement,
+// so either both are committed or neither is
+func (s *PostgresStore) InsertLogStatusBatchWithOutbox(ctx context.Context, statuses []*LogStatus, outbox []*OutboxRecord) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	// 1. Prepare parallel slices for both tables
+	requestIDs := make([]string, len(statuses))
+	logHashes := make([]string, len(statuses))
+	sourceOrgIDs := make([]string, len(statuses))
+	receivedTimestamps := make([]time.Time, len(statuses))
+	statusStrings := make([]string, len(statuses))
+	clientTimestamps := make([]*time.Time, len(statuses)) // NULL when the client sent none
+	clientTimestampFlags := make([]bool, len(statuses))
+	attestationModes := make([]string, len(statuses))
+	contentFormats := make([]string, len(statuses))
+	categories := make([]*string, len(statuses))  // NULL for logs without a category
+	schemaVersions := make([]*int, len(statuses)) // NULL for logs without a category
+
+	for i, status := range statuses {
+		requestIDs[i] = status.RequestID
+		logHashes[i] = status.LogHash
+		sourceOrgIDs[i] = status.SourceOrgID
+		receivedTimestamps[i] = status.ReceivedTimestamp
+		statusStrings[i] = string(status.Status)
+		clientTimestamps[i] = status.ClientTimestamp
+		clientTimestampFlags[i] = status.ClientTimestampFlagged
+		attestationModes[i] = status.AttestationMode
+		contentFormats[i] = status.ContentFormat
+		categories[i] = status.Category
+		schemaVersions[i] = status.SchemaVersion
+	}
+
+	outboxRequestIDs := make([]string, len(outbox))
+	outboxOrgIDs := make([]string, len(outbox))
+	outboxPayloads := make([][]byte, len(outbox))
+
+	for i, record := range outbox {
+		outboxRequestIDs[i] = record.RequestID
+		outboxOrgIDs[i] = record.SourceOrgID
+		outboxPayloads[i] = record.Payload
+	}
+
+	// 2. Insert statuses, then outbox records only for the statuses that were new.
+	// A replayed batch therefore does not enqueue its messages twice.
+	query := `
+        WITH inserted AS (
+            INSERT INTO tbl_log_status (
+                request_id,
+                log_hash,
+                source_org_id,
+                received_timestamp,
+                status,
+                retry_count,
+                client_timestamp,
+                client_timestamp_flagged,
+                attestation_mode,
+                content_format,
+                category,
+                schema_version
+            )
+            SELECT
+                request_id,
+                ($2::text[])[idx] AS log_hash,
+                ($3::text[])[idx] AS source_org_id,
+                ($4::timestamptz[])[idx] AS received_timestamp,
+                ($5::text[])[idx] AS status,
+                0 AS retry_count,
+                ($9::timestamptz[])[idx] AS client_timestamp,
+                ($10::boolean[])[idx] AS client_timestamp_flagged,
+                ($11::text[])[idx] AS attestation_mode,
+                ($12::text[])[idx] AS content_format,
+                ($13::text[])[idx] AS category,
+                ($14::integer[])[idx] AS schema_version
+            FROM
+                UNNEST($1::text[]) WITH ORDINALITY AS t(request_id, idx)
+            ON CONFLICT (request_id) DO NOTHING
+            RETURNING request_id
+        )
+        INSERT INTO tbl_log_outbox (request_id, source_org_id, payload)
+        SELECT o.request_id, o.source_org_id, o.payload
+        FROM
+            UNNEST($6::text[], $7::text[], $8::bytea[]) WITH ORDINALITY AS o(request_id, source_org_id, payload, idx)
+            JOIN inserted ON inserted.request_id = o.request_id
+        ORDER BY o.idx -- Outbox IDs follow batch order
+    `
+
+	// 3. Execute the single query
+	_, err := s.db.Exec(queryCtx, query,
+		requestIDs,           // $1
+		logHashes,            // $2
+		sourceOrgIDs,         // $3
+		receivedTimestamps,   // $4
+		statusStrings,        // $5
+		outboxRequestIDs,     // $6
+		outboxOrgIDs,         // $7
+		outboxPayloads,       // $8
+		clientTimestamps,     // $9
+		clientTimestampFlags, // $10
+		attestationModes,     // $11
+		contentFormats,       // $12
+		categories,           // $13
+		schemaVersions,       // $14
+	)
+	if err != nil {
+		return fmt.Errorf("failed to batch insert log statuses with outbox: %w", err)
+	}
+
+	return nil
+}
+
+// RelayOutboxBatch publishes the oldest pending outbox records inside a transaction holding
+// the relay advisory lock. Records are marked sent only after publish returns nil; if the
+// commit fails afterwards they are published again (at-least-once).
+//
+// Records are read in id order, but ids are assigned at insert and become visible at commit,
+// so records of concurrent transactions may be published in commit order instead. Records of
+// one transaction, and of transactions that do not overlap, keep their order.
+func (s *PostgresStore) RelayOutboxBatch(ctx context.Context, limit int, publish func(records []*OutboxRecord) ([]OutboxFailure, error)) (int, error) {
+	relayed := 0
+
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// 1. Make sure no other relay is publishing
+		var locked bool
+		if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLockKey).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to acquire outbox relay lock: %w", err)
+		}
+		if !locked {
+			return nil
+		}
+
+		// 2. Load pending records in insertion order
+		rows, err := tx.Query(ctx, `
+            SELECT id, request_id, source_org_id, payload, created_at
+            FROM tbl_log_outbox
+            WHERE sent_at IS NULL AND failed_at IS NULL
+            ORDER BY id
+            LIMIT $1
+        `, limit)
+		if err != nil {
+			return fmt.Errorf("failed to query outbox: %w", err)
+		}
+
+		var records []*OutboxRecord
+		for rows.Next() {
+			var record OutboxRecord
+			if err := rows.Scan(&record.ID, &record.RequestID, &record.SourceOrgID, &record.Payload, &record.CreatedAt); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan outbox row: %w", err)
+			}
+			records = append(records, &record)
+		}
+		rows.Close()
+		if rows.Err() != nil {
+			return fmt.Errorf("error iterating outbox rows: %w", rows.Err())
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		// 3. Publish
+		failures, err := publish(records)
+		if err != nil {
+			return err
+		}
+
+		// 4. Set failed records aside and fail their logs, which can no longer reach the engine
+		failed := make(map[int64]bool, len(failures))
+		if len(failures) > 0 {
+			failedIDs := make([]int64, len(failures))
+			errorMessages := make([]string, len(failures))
+			for i, failure := range failures {
+				failed[failure.ID] = true
+				failedIDs[i] = failure.ID
+				errorMessages[i] = failure.ErrorMessage
+			}
+			_, err := tx.Exec(ctx, `
+                WITH data AS (
+                    SELECT * FROM UNNEST($1::bigint[], $2::text[]) AS t(id, error_msg)
+                ),
+                failed AS (
+                    UPDATE tbl_log_outbox
+                    SET failed_at = NOW(), error_message = data.error_msg
+                    FROM data
+                    WHERE tbl_log_outbox.id = data.id
+                    RETURNING tbl_log_outbox.request_id, data.error_msg
+                )
+                UPDATE tbl_log_status
+                SET status = 'FAILED', error_message = failed.error_msg
+                FROM failed
+                WHERE tbl_log_status.request_id = failed.request_id
+                  AND tbl_log_status.status = 'RECEIVED'
+            `, failedIDs, errorMessages)
+			if err != nil {
+				return fmt.Errorf("failed to mark outbox records failed: %w", err)
+			}
+		}
+
+		// 5. Mark the rest sent in the same transaction
+		ids := make([]int64, 0, len(records))
+		for _, record := range records {
+			if !failed[record.ID] {
+				ids = append(ids, record.ID)
+			}
+		}
+		if _, err := tx.Exec(ctx, `UPDATE tbl_log_outbox SET sent_at = NOW() WHERE id = ANY($1)`, ids); err != nil {
+			return fmt.Errorf("failed to mark outbox records sent: %w", err)
+		}
+
+		relayed = len(records)
+		return nil // Commit transaction
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return relayed, nil
+}
+
+// scrubOutboxContent removes the content of erased logs from their outbox records inside tx.
+// Sent and failed records are deleted. Pending records keep their message without LogContent,
+// so the relay still publishes it and the engine anchors the log hash-only.
+func scrubOutboxContent(ctx context.Context, tx pgx.Tx, ids []ContentKeyID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	sourceOrgIDs := make([]string, len(ids))
+	logHashes := make([]string, len(ids))
+	for i, id := range ids {
+		sourceOrgIDs[i] = id.SourceOrgID
+		logHashes[i] = id.LogHash
+	}
+
+	// 1. Records that are no longer relayed
+	if _, err := tx.Exec(ctx, `
+        DELETE FROM tbl_log_outbox o
+        USING tbl_log_status s, unnest($1::text[], $2::text[]) AS t(source_org_id, log_hash)
+        WHERE o.request_id = s.request_id
+          AND s.source_org_id = t.source_org_id AND s.log_hash = t.log_hash
+          AND (o.sent_at IS NOT NULL OR o.failed_at IS NOT NULL)
+    `, sourceOrgIDs, logHashes); err != nil {
+		return fmt.Errorf("failed to delete outbox records of erased logs: %w", err)
+	}
+
+	// 2. Pending records
+	rows, err := tx.Query(ctx, `
+        SELECT o.id, o.payload
+        FROM tbl_log_outbox o
+        JOIN tbl_log_status s ON s.request_id = o.request_id
+        JOIN unnest($1::text[], $2::text[]) AS t(source_org_id, log_hash)
+          ON s.source_org_id = t.source_org_id AND s.log_hash = t.log_hash
+        WHERE o.sent_at IS NULL AND o.failed_at IS NULL
+        FOR UPDATE OF o
+    `, sourceOrgIDs, logHashes)
+	if err != nil {
+		return fmt.Errorf("failed to query outbox records of erased logs: %w", err)
+	}
+	var scrubbedIDs, undecodableIDs []int64
+	var payloads [][]byte
+	for rows.Next() {
+		var id int64
+		var payload []byte
+		if err := rows.Scan(&id, &payload); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		// Fields are kept as they are; a payload the relay could not decode is set aside as it would be
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			undecodableIDs = append(undecodableIDs, id)
+			continue
+		}
+		fields["LogContent"] = json.RawMessage(`""`)
+		if payload, err = json.Marshal(fields); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to encode outbox payload: %w", err)
+		}
+		scrubbedIDs = append(scrubbedIDs, id)
+		payloads = append(payloads, payload)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return fmt.Errorf("error iterating outbox rows: %w", rows.Err())
+	}
+
+	if len(scrubbedIDs) > 0 {
+		if _, err := tx.Exec(ctx, `
+            UPDATE tbl_log_outbox o SET payload = t.payload
+            FROM unnest($1::bigint[], $2::bytea[]) AS t(id, payload)
+            WHERE o.id = t.id
+        `, scrubbedIDs, payloads); err != nil {
+			return fmt.Errorf("failed to scrub outbox payloads: %w", err)
+		}
+	}
+	if len(undecodableIDs) > 0 {
+		if _, err := tx.Exec(ctx, `
+            WITH failed AS (
+                UPDATE tbl_log_outbox
+                SET payload = ''::bytea, failed_at = NOW(), error_message = 'undecodable payload, scrubbed on erasure'
+                WHERE id = ANY($1)
+                RETURNING request_id
+            )
+            UPDATE tbl_log_status
+            SET status = 'FAILED', error_message = 'undecodable outbox payload'
+            FROM failed
+            WHERE tbl_log_status.request_id = failed.request_id
+              AND tbl_log_status.status = 'RECEIVED'
+        `, undecodableIDs); err != nil {
+			return fmt.Errorf("failed to set aside undecodable outbox records: %w", err)
+		}
+	}
+	return nil
+}
+
+// PurgeSentOutbox deletes outbox records sent before the given time
+func (s *PostgresStore) PurgeSentOutbox(ctx context.Context, sentBefore time.Time) (int64, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM tbl_log_outbox WHERE sent_at IS NOT NULL AND sent_at < $1`, sentBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge sent outbox records: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
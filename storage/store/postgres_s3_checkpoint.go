@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const s3CheckpointColumns = `source_name, bucket, object_key, etag, size, status, next_line, next_offset, records, rejected,
+            lease_owner, lease_expires_at, last_error, started_at, updated_at, completed_at`
+
+// ListS3Checkpoints returns the checkpoints of the given objects of a source
+func (s *PostgresStore) ListS3Checkpoints(ctx context.Context, sourceName, bucket string, objectKeys []string) ([]*S3Checkpoint, error) {
+	if len(objectKeys) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.Query(ctx, `
+        SELECT `+s3CheckpointColumns+`
+        FROM tbl_s3_checkpoint
+        WHERE source_name = $1 AND bucket = $2 AND object_key = ANY($3)
+    `, sourceName, bucket, objectKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query S3 checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []*S3Checkpoint
+	for rows.Next() {
+		checkpoint, err := scanS3Checkpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating S3 checkpoint rows: %w", rows.Err())
+	}
+	return checkpoints, nil
+}
+
+// ClaimS3Checkpoint inserts an in-progress checkpoint, or takes over one whose lease is held by
+// the same owner or has expired. Lease times are taken from the database clock, so pollers on
+// different hosts agree on them.
+func (s *PostgresStore) ClaimS3Checkpoint(ctx context.Context, checkpoint *S3Checkpoint, lease time.Duration) (*S3Checkpoint, error) {
+	query := `
+        INSERT INTO tbl_s3_checkpoint (
+            source_name, bucket, object_key, etag, size, status, next_line, next_offset, records, rejected,
+            lease_owner, lease_expires_at, started_at, updated_at
+        )
+        VALUES ($1, $2, $3, $4, $5, 'IN_PROGRESS', 1, 0, 0, 0, $6, NOW() + make_interval(secs => $7), NOW(), NOW())
+        ON CONFLICT (source_name, bucket, object_key, etag) DO UPDATE
+        SET lease_owner = EXCLUDED.lease_owner,
+            lease_expires_at = EXCLUDED.lease_expires_at,
+            updated_at = NOW()
+        WHERE tbl_s3_checkpoint.status = 'IN_PROGRESS'
+          AND (tbl_s3_checkpoint.lease_owner = EXCLUDED.lease_owner OR tbl_s3_checkpoint.lease_expires_at <= NOW())
+        RETURNING ` + s3CheckpointColumns
+	claimed, err := scanS3Checkpoint(s.db.QueryRow(ctx, query,
+		checkpoint.SourceName,
+		checkpoint.Bucket,
+		checkpoint.ObjectKey,
+		checkpoint.ETag,
+		checkpoint.Size,
+		checkpoint.LeaseOwner,
+		lease.Seconds(),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil // Completed, failed or leased elsewhere
+	}
+	return claimed, err
+}
+
+// UpdateS3Checkpoint stores the progress of a claimed object while its owner holds the lease
+func (s *PostgresStore) UpdateS3Checkpoint(ctx context.Context, checkpoint *S3Checkpoint, lease time.Duration) error {
+	query := `
+        UPDATE tbl_s3_checkpoint
+        SET status = $5,
+            next_line = $6,
+            next_offset = $7,
+            records = $8,
+            rejected = $9,
+            last_error = $10,
+            lease_expires_at = NOW() + make_interval(secs => $12),
+            updated_at = NOW(),
+            completed_at = CASE WHEN $5::VARCHAR = 'IN_PROGRESS' THEN NULL ELSE NOW() END
+        WHERE source_name = $1 AND bucket = $2 AND object_key = $3 AND etag = $4
+          AND lease_owner = $11 AND status = 'IN_PROGRESS'
+    `
+	tag, err := s.db.Exec(ctx, query,
+		checkpoint.SourceName,
+		checkpoint.Bucket,
+		checkpoint.ObjectKey,
+		checkpoint.ETag,
+		string(checkpoint.Status),
+		checkpoint.NextLine,
+		checkpoint.NextOffset,
+		checkpoint.Records,
+		checkpoint.Rejected,
+		checkpoint.LastError,
+		checkpoint.LeaseOwner,
+		lease.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update S3 checkpoint: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: '%s'", ErrS3LeaseLost, checkpoint.ObjectKey)
+	}
+	return nil
+}
+
+// scanS3Checkpoint scans one row selected with s3CheckpointColumns
+func scanS3Checkpoint(row pgx.Row) (*S3Checkpoint, error) {
+	var checkpoint S3Checkpoint
+	var status string
+	err := row.Scan(
+		&checkpoint.SourceName,
+		&checkpoint.Bucket,
+		&checkpoint.ObjectKey,
+		&checkpoint.ETag,
+		&checkpoint.Size,
+		&status,
+		&checkpoint.NextLine,
+		&checkpoint.NextOffset,
+		&checkpoint.Records,
+		&checkpoint.Rejected,
+		&checkpoint.LeaseOwner,
+		&checkpoint.LeaseExpiresAt,
+		&checkpoint.LastError,
+		&checkpoint.StartedAt,
+		&checkpoint.UpdatedAt,
+		&checkpoint.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan S3 checkpoint: %w", err)
+	}
+	checkpoint.Status = S3CheckpointStatus(status)
+	return &checkpoint, nil
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const logSchemaColumns = `source_org_id, category, version, schema_json, created_by, created_at`
+
+// CreateLogSchema inserts the next version of a category schema. The version is assigned under a
+// transaction-scoped advisory lock on the org and category, so concurrent registrations get distinct versions.
+func (s *PostgresStore) CreateLogSchema(ctx context.Context, schema *LogSchema) error {
+	err := s.db.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// 1. Serialize registrations of the same category
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1 || '/' || $2))`, schema.SourceOrgID, schema.Category); err != nil {
+			return fmt.Errorf("failed to acquire log schema lock: %w", err)
+		}
+
+		// 2. Insert as the next version
+		return tx.QueryRow(ctx, `
+            INSERT INTO tbl_log_schema (source_org_id, category, version, schema_json, created_by, created_at)
+            SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, NOW()
+            FROM tbl_log_schema
+            WHERE source_org_id = $1 AND category = $2
+            RETURNING version, created_at
+        `, schema.SourceOrgID, schema.Category, schema.Schema, schema.CreatedBy).Scan(&schema.Version, &schema.CreatedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert log schema: %w", err)
+	}
+	return nil
+}
+
+// GetLogSchema returns a version of a category schema, or the latest one if version is 0
+func (s *PostgresStore) GetLogSchema(ctx context.Context, sourceOrgID, category string, version int) (*LogSchema, error) {
+	query := `
+        SELECT ` + logSchemaColumns + `
+        FROM tbl_log_schema
+        WHERE source_org_id = $1 AND category = $2 AND ($3 = 0 OR version = $3)
+        ORDER BY version DESC
+        LIMIT 1
+    `
+	return scanLogSchema(s.db.QueryRow(ctx, query, sourceOrgID, category, version))
+}
+
+// ListLogSchemas returns the latest version of each category schema of an org
+func (s *PostgresStore) ListLogSchemas(ctx context.Context, sourceOrgID string) ([]*LogSchema, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT DISTINCT ON (category) `+logSchemaColumns+`
+        FROM tbl_log_schema
+        WHERE source_org_id = $1
+        ORDER BY category, version DESC
+    `, sourceOrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var schemas []*LogSchema
+	for rows.Next() {
+		schema, err := scanLogSchema(rows)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("error iterating log schema rows: %w", rows.Err())
+	}
+	return schemas, nil
+}
+
+// DeleteLogSchema removes all versions of a category schema; logs already validated keep their recorded version
+func (s *PostgresStore) DeleteLogSchema(ctx context.Context, sourceOrgID, category string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM tbl_log_schema WHERE source_org_id = $1 AND category = $2`, sourceOrgID, category)
+	if err != nil {
+		return fmt.Errorf("failed to delete log schema: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLogSchemaNotFound
+	}
+	return nil
+}
+
+// scanLogSchema scans one row selected with logSchemaColumns
+func scanLogSchema(row pgx.Row) (*LogSchema, error) {
+	var schema LogSchema
+	var createdBy *string
+	err := row.Scan(
+		&schema.SourceOrgID,
+		&schema.Category,
+		&schema.Version,
+		&schema.Schema,
+		&createdBy,
+		&schema.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrLogSchemaNotFound
+		}
+		return nil, fmt.Errorf("failed to scan log schema: %w", err)
+	}
+	if createdBy != nil {
+		schema.CreatedBy = *createdBy
+	}
+	return &schema, nil
+}
@@ -8,7 +8,15 @@ import (
 
 // Store-level errors
 var (
-	ErrLogNotFound = errors.New("log not found")
+	ErrLogNotFound             = errors.New("log not found")
+	ErrErasureNotFound         = errors.New("erasure not found")
+	ErrContentNotErasable      = errors.New("log content is anchored without a record key and cannot be erased")
+	ErrLogAmbiguous            = errors.New("log_hash belongs to several orgs")
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrLogSchemaNotFound       = errors.New("log schema not found")
+	ErrClientKeyNotFound       = errors.New("client key not found")
+	ErrClientKeyExists         = errors.New("client key already exists")
+	ErrS3LeaseLost             = errors.New("S3 object is leased by another poller")
 )
 
 // Status defines the task status enum type
@@ -27,6 +35,7 @@ type CompletionRecord struct {
 	TxHash         string
 	LogHashOnChain string
 	BlockHeight    uint64
+	BlockTimestamp *time.Time // Nil if the chain did not report the block time
 }
 
 // FailureRecord represents a failed log record for batch updates
@@ -35,21 +44,167 @@ type FailureRecord struct {
 	ErrorMessage string
 }
 
+// OutboxRecord is a Kafka message waiting in tbl_log_outbox to be relayed
+type OutboxRecord struct {
+	ID          int64      `db:"id"`
+	RequestID   string     `db:"request_id"`
+	SourceOrgID string     `db:"source_org_id"`
+	Payload     []byte     `db:"payload"` // Serialized Kafka message value
+	CreatedAt   time.Time  `db:"created_at"`
+	SentAt      *time.Time `db:"sent_at"`
+}
+
+// OutboxFailure is an outbox record that can never be published, such as one whose payload
+// does not decode. The relay sets it aside instead of retrying it.
+type OutboxFailure struct {
+	ID           int64
+	ErrorMessage string
+}
+
+// IdempotencyRecord maps a client idempotency key, scoped to an org, to the submission it created
+type IdempotencyRecord struct {
+	SourceOrgID       string    `db:"source_org_id"`
+	IdempotencyKey    string    `db:"idempotency_key"`
+	RequestID         string    `db:"request_id"`
+	LogHash           string    `db:"log_hash"`
+	ReceivedTimestamp time.Time `db:"received_timestamp"`
+	ExpiresAt         time.Time `db:"expires_at"`
+}
+
+// ErasureStatus tracks whether an erasure tombstone is on chain
+type ErasureStatus string
+
+const (
+	ErasureStatusPending  ErasureStatus = "PENDING"  // Key destroyed, tombstone not on chain yet
+	ErasureStatusAnchored ErasureStatus = "ANCHORED" // Tombstone on chain
+)
+
+// Erasure triggers
+const (
+	ErasureTriggerRequest   = "request"   // Admin erasure request
+	ErasureTriggerRetention = "retention" // Org retention policy expired
+)
+
+// ContentKey is the wrapped per-record key of encrypted on-chain content (Tbl_Content_Key)
+type ContentKey struct {
+	LogHash     string     `db:"log_hash"`
+	SourceOrgID string     `db:"source_org_id"`
+	OrgKeyID    string     `db:"org_key_id"`  // Org data key version that wraps the record key
+	WrappedKey  *string    `db:"wrapped_key"` // Nil once the record was erased
+	CreatedAt   time.Time  `db:"created_at"`
+	ErasedAt    *time.Time `db:"erased_at"`
+}
+
+// ContentKeyID identifies a record key: orgs submitting the same content each have their own
+type ContentKeyID struct {
+	SourceOrgID string
+	LogHash     string
+}
+
+// ID returns the identifier of the key
+func (k *ContentKey) ID() ContentKeyID {
+	return ContentKeyID{SourceOrgID: k.SourceOrgID, LogHash: k.LogHash}
+}
+
+// Erasure records the crypto-shredding of a log's content and its on-chain tombstone (Tbl_Erasure)
+type Erasure struct {
+	LogHash     string        `db:"log_hash"`
+	SourceOrgID string        `db:"source_org_id"`
+	Trigger     string        `db:"trigger_type"` // request or retention
+	Reason      string        `db:"reason"`
+	RequestedBy string        `db:"requested_by"`
+	ErasedAt    time.Time     `db:"erased_at"`
+	Status      ErasureStatus `db:"status"`
+	TxHash      *string       `db:"tx_hash"` // Tombstone transaction, set once anchored
+	BlockHeight *int64        `db:"block_height"`
+	AnchoredAt  *time.Time    `db:"anchored_at"`
+	LastError   *string       `db:"last_error"` // Last failed tombstone submission
+	Attempts    int           `db:"attempts"`
+}
+
+// RetentionPolicy is how long an org's encrypted on-chain content stays readable (Tbl_Retention_Policy)
+type RetentionPolicy struct {
+	SourceOrgID string        `db:"source_org_id"`
+	Retention   time.Duration `db:"retention_seconds"`
+	UpdatedBy   string        `db:"updated_by"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+}
+
+// LogSchema is one version of the JSON Schema an org registered for a log category (Tbl_Log_Schema)
+type LogSchema struct {
+	SourceOrgID string    `db:"source_org_id"`
+	Category    string    `db:"category"`
+	Version     int       `db:"version"` // Starts at 1, incremented per registration
+	Schema      string    `db:"schema_json"`
+	CreatedBy   string    `db:"created_by"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// ClientKey is a public key clients of an org sign log hashes with (Tbl_Client_Key)
+type ClientKey struct {
+	SourceOrgID string     `db:"source_org_id"`
+	KeyID       string     `db:"key_id"`
+	Algorithm   string     `db:"algorithm"` // ed25519, ecdsa-p256 or sm2
+	PublicKey   string     `db:"public_key_pem"`
+	Fingerprint string     `db:"fingerprint"` // Hex SHA-256 of the DER SubjectPublicKeyInfo
+	CreatedBy   string     `db:"created_by"`
+	CreatedAt   time.Time  `db:"created_at"`
+	RevokedAt   *time.Time `db:"revoked_at"`
+}
+
+// S3CheckpointStatus tracks the ingestion of an S3 object
+type S3CheckpointStatus string
+
+const (
+	S3CheckpointInProgress S3CheckpointStatus = "IN_PROGRESS" // Lines before next_line are durable
+	S3CheckpointCompleted  S3CheckpointStatus = "COMPLETED"   // Every line is durable or rejected
+	S3CheckpointFailed     S3CheckpointStatus = "FAILED"      // The object cannot be read; not retried
+)
+
+// S3Checkpoint is the ingestion progress of one version of an S3 object (Tbl_S3_Checkpoint).
+// A rewritten object has a new ETag and is ingested again as a new file.
+type S3Checkpoint struct {
+	SourceName     string             `db:"source_name"`
+	Bucket         string             `db:"bucket"`
+	ObjectKey      string             `db:"object_key"`
+	ETag           string             `db:"etag"`
+	Size           int64              `db:"size"`
+	Status         S3CheckpointStatus `db:"status"`
+	NextLine       int64              `db:"next_line"`   // 1-based number of the first line that is not durable yet
+	NextOffset     int64              `db:"next_offset"` // Byte offset of next_line in the decompressed object
+	Records        int64              `db:"records"`     // Lines submitted and durable
+	Rejected       int64              `db:"rejected"`    // Lines rejected by the core service
+	LeaseOwner     string             `db:"lease_owner"` // Poller processing the object
+	LeaseExpiresAt time.Time          `db:"lease_expires_at"`
+	LastError      *string            `db:"last_error"`
+	StartedAt      time.Time          `db:"started_at"`
+	UpdatedAt      time.Time          `db:"updated_at"`
+	CompletedAt    *time.Time         `db:"completed_at"`
+}
+
 // LogStatus is the Go struct corresponding to the database table Tbl_Log_Status
 type LogStatus struct {
-	RequestID            string     `db:"request_id"`
-	LogHash              string     `db:"log_hash"`
-	SourceOrgID          string     `db:"source_org_id"`
-	ReceivedTimestamp    time.Time  `db:"received_timestamp"`
-	Status               Status     `db:"status"`
-	ReceivedAtDB         time.Time  `db:"received_at_db"`
-	ProcessingStartedAt  *time.Time `db:"processing_started_at"`
-	ProcessingFinishedAt *time.Time `db:"processing_finished_at"`
-	TxHash               *string    `db:"tx_hash"`
-	BlockHeight          *int64     `db:"block_height"`
-	LogHashOnChain       *string    `db:"log_hash_on_chain"`
-	ErrorMessage         *string    `db:"error_message"`
-	RetryCount           int        `db:"retry_count"`
+	RequestID              string     `db:"request_id"`
+	LogHash                string     `db:"log_hash"`
+	SourceOrgID            string     `db:"source_org_id"`
+	ReceivedTimestamp      time.Time  `db:"received_timestamp"`
+	ClientTimestamp        *time.Time `db:"client_timestamp"`         // Event time reported by the client
+	ClientTimestampFlagged bool       `db:"client_timestamp_flagged"` // Client timestamp was outside the allowed clock skew
+	AttestationMode        string     `db:"attestation_mode"`         // full or hash_only (content kept off chain)
+	ContentFormat          string     `db:"content_format"`           // text, or json for canonicalized structured logs
+	Category               *string    `db:"category"`                 // Schema category declared by a structured log
+	SchemaVersion          *int       `db:"schema_version"`           // Version of the category schema the log was validated against
+	Status                 Status     `db:"status"`
+	ReceivedAtDB           time.Time  `db:"received_at_db"`
+	ProcessingStartedAt    *time.Time `db:"processing_started_at"`
+	ProcessingFinishedAt   *time.Time `db:"processing_finished_at"`
+	TxHash                 *string    `db:"tx_hash"`
+	BlockHeight            *int64     `db:"block_height"`
+	BlockTimestamp         *time.Time `db:"block_timestamp"`
+	LogHashOnChain         *string    `db:"log_hash_on_chain"`
+	ErrorMessage           *string    `db:"error_message"`
+	RetryCount             int        `db:"retry_count"`
+	ErasedAt               *time.Time `db:"erased_at"` // Content erased (crypto-shredded)
 }
 
 // Store is the data storage interface
@@ -70,12 +225,110 @@ type Store interface {
 	// InsertLogStatusBatch performs bulk insertion of log statuses
 	InsertLogStatusBatch(ctx context.Context, statuses []*LogStatus) error
 
+	// InsertLogStatusBatchWithOutbox inserts log statuses and their outbox records in one transaction.
+	// Outbox records are only written for statuses that did not exist yet.
+	InsertLogStatusBatchWithOutbox(ctx context.Context, statuses []*LogStatus, outbox []*OutboxRecord) error
+
+	// RelayOutboxBatch passes up to limit pending outbox records, oldest first, to publish. If it
+	// succeeds, the records it returns as failures are set aside with their logs marked FAILED,
+	// and the others are marked sent. Only one caller relays at a time; others return 0 without
+	// publishing.
+	RelayOutboxBatch(ctx context.Context, limit int, publish func(records []*OutboxRecord) ([]OutboxFailure, error)) (int, error)
+
+	// PurgeSentOutbox deletes outbox records sent before the given time
+	PurgeSentOutbox(ctx context.Context, sentBefore time.Time) (int64, error)
+
+	// ReserveIdempotencyKey stores record unless an unexpired record with the same org and key exists.
+	// It returns the existing record, or nil if record was stored.
+	ReserveIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error)
+
+	// ReleaseIdempotencyKey deletes the key reserved by requestID, so the submission can be retried
+	ReleaseIdempotencyKey(ctx context.Context, sourceOrgID, idempotencyKey, requestID string) error
+
+	// PurgeExpiredIdempotencyKeys deletes records whose TTL has passed
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+
 	// GetLogStatusByRequestID queries log status by request_id
 	GetLogStatusByRequestID(ctx context.Context, requestID string) (*LogStatus, error)
 
 	// GetLogStatusByHash queries log status by log_hash
 	GetLogStatusByHash(ctx context.Context, logHash string) (*LogStatus, error)
 
+	// ListLogStatusesByTxHash returns the logs anchored in a transaction; empty if there are none
+	ListLogStatusesByTxHash(ctx context.Context, txHash string) ([]*LogStatus, error)
+
+	// InsertContentKeys stores record keys; keys that already exist for an org's log_hash are kept
+	InsertContentKeys(ctx context.Context, keys []*ContentKey) error
+
+	// GetContentKeys returns the stored record keys of the given logs, including erased ones
+	GetContentKeys(ctx context.Context, ids []ContentKeyID) (map[ContentKeyID]*ContentKey, error)
+
+	// EraseContent destroys the record key of erasure.LogHash for erasure.SourceOrgID, marks that org's
+	// logs erased and records the erasure. Without SourceOrgID, the hash must belong to a single org
+	// (ErrLogAmbiguous otherwise). If the log was already erased, the existing erasure is returned.
+	EraseContent(ctx context.Context, erasure *Erasure) (*Erasure, error)
+
+	// EraseExpiredContent erases up to limit record keys older than their org's retention policy
+	// and returns the erased logs
+	EraseExpiredContent(ctx context.Context, now time.Time, limit int) ([]ContentKeyID, error)
+
+	// GetErasure queries the erasure of an org's log_hash
+	GetErasure(ctx context.Context, sourceOrgID, logHash string) (*Erasure, error)
+
+	// ListErasures returns the erasures of a log_hash for every org, oldest first
+	ListErasures(ctx context.Context, logHash string) ([]*Erasure, error)
+
+	// AnchorPendingErasures passes up to limit pending erasures, oldest first, to anchor and marks those
+	// it anchored. Only one caller anchors at a time; others return 0 without anchoring.
+	AnchorPendingErasures(ctx context.Context, limit int, anchor func(erasure *Erasure) (txHash string, blockHeight uint64, err error)) (int, error)
+
+	// UpsertRetentionPolicy creates or replaces an org's retention policy
+	UpsertRetentionPolicy(ctx context.Context, policy *RetentionPolicy) error
+
+	// ListRetentionPolicies returns all retention policies ordered by org
+	ListRetentionPolicies(ctx context.Context) ([]*RetentionPolicy, error)
+
+	// DeleteRetentionPolicy removes an org's retention policy
+	DeleteRetentionPolicy(ctx context.Context, sourceOrgID string) error
+
+	// CreateLogSchema stores schema as the next version of its org's category and sets
+	// schema.Version and schema.CreatedAt
+	CreateLogSchema(ctx context.Context, schema *LogSchema) error
+
+	// GetLogSchema returns a version of an org's category schema, or the latest one if version is 0
+	GetLogSchema(ctx context.Context, sourceOrgID, category string, version int) (*LogSchema, error)
+
+	// ListLogSchemas returns the latest version of each category schema of an org, ordered by category
+	ListLogSchemas(ctx context.Context, sourceOrgID string) ([]*LogSchema, error)
+
+	// DeleteLogSchema removes all versions of an org's category schema
+	DeleteLogSchema(ctx context.Context, sourceOrgID, category string) error
+
+	// CreateClientKey registers a public key and sets key.CreatedAt; an existing key ID, even revoked,
+	// returns ErrClientKeyExists
+	CreateClientKey(ctx context.Context, key *ClientKey) error
+
+	// GetClientKey returns a public key of an org, including revoked ones
+	GetClientKey(ctx context.Context, sourceOrgID, keyID string) (*ClientKey, error)
+
+	// ListClientKeys returns all public keys of an org ordered by key ID, including revoked ones
+	ListClientKeys(ctx context.Context, sourceOrgID string) ([]*ClientKey, error)
+
+	// RevokeClientKey marks a public key as revoked; revoking it again keeps the first revocation time
+	RevokeClientKey(ctx context.Context, sourceOrgID, keyID string) (*ClientKey, error)
+
+	// ListS3Checkpoints returns the checkpoints of the given objects of a source, all ETags included
+	ListS3Checkpoints(ctx context.Context, sourceName, bucket string, objectKeys []string) ([]*S3Checkpoint, error)
+
+	// ClaimS3Checkpoint leases an object to checkpoint.LeaseOwner for lease, creating its checkpoint
+	// if there is none. It returns the stored checkpoint to resume from, or nil if the object is
+	// completed, failed or leased by another owner.
+	ClaimS3Checkpoint(ctx context.Context, checkpoint *S3Checkpoint, lease time.Duration) (*S3Checkpoint, error)
+
+	// UpdateS3Checkpoint stores progress, status and error of a claimed object and renews its lease.
+	// It returns ErrS3LeaseLost if checkpoint.LeaseOwner no longer holds the lease.
+	UpdateS3Checkpoint(ctx context.Context, checkpoint *S3Checkpoint, lease time.Duration) error
+
 	// Close closes the database connection
 	Close()
 }